@@ -42,6 +42,9 @@ var _ AccessControl = ValidateFunc(func(_ *http.Request) error { return nil })
 func (i ListItem) Validate(req *http.Request) error {
 	if err := i.control.Validate(req); err != nil {
 		if e, ok := err.(*errors.Error); ok {
+			if _, isGroup := i.control.(*AllOf); isGroup { // keep the label of the failed member
+				return e
+			}
 			return e.Label(i.label)
 		}
 		return errors.AccessControl.Label(i.label).Kind(i.kind).With(err)
@@ -0,0 +1,89 @@
+package accesscontrol
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/eval"
+)
+
+var _ AccessControl = &Authorization{}
+
+// Authorization represents the evaluation of boolean authorization rules
+// which takes place after all access controls have been validated.
+type Authorization struct {
+	rules []hcl.Expression
+}
+
+// NewAuthorization creates a new Authorization object for the given rules expression.
+// A tuple expression results in one rule per element.
+func NewAuthorization(rules hcl.Expression) (*Authorization, error) {
+	if rules == nil {
+		return nil, fmt.Errorf("authorization requires rules")
+	}
+
+	if tuple, ok := rules.(*hclsyntax.TupleConsExpr); ok {
+		if len(tuple.Exprs) == 0 {
+			return nil, fmt.Errorf("authorization requires at least one rule")
+		}
+		a := &Authorization{}
+		for _, expr := range tuple.Exprs {
+			a.rules = append(a.rules, expr)
+		}
+		return a, nil
+	}
+	return &Authorization{rules: []hcl.Expression{rules}}, nil
+}
+
+// Validate implements the AccessControl interface. Every rule must evaluate to true,
+// evaluation errors and non-boolean results deny the request.
+func (a *Authorization) Validate(req *http.Request) error {
+	ctx := eval.ContextFromRequest(req).HCLContext()
+
+	for _, rule := range a.rules {
+		val, diags := rule.Value(ctx)
+		if diags.HasErrors() {
+			return errors.Authorization.Messagef("rule %s", rule.Range().String()).With(diags)
+		}
+
+		if err := isGranted(val); err != nil {
+			return errors.Authorization.Messagef("rule %s", rule.Range().String()).With(err)
+		}
+	}
+
+	return nil
+}
+
+func isGranted(val cty.Value) error {
+	if !val.IsWhollyKnown() || val.IsNull() {
+		return fmt.Errorf("evaluates to null")
+	}
+
+	valType := val.Type()
+	if valType == cty.Bool {
+		if val.True() {
+			return nil
+		}
+		return fmt.Errorf("not fulfilled")
+	}
+
+	// a single rule may result in a list of booleans
+	if valType.IsTupleType() || valType.IsListType() {
+		if val.LengthInt() == 0 {
+			return fmt.Errorf("not fulfilled")
+		}
+		for _, v := range val.AsValueSlice() {
+			if err := isGranted(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("must evaluate to bool, got: %s", valType.FriendlyName())
+}
@@ -0,0 +1,160 @@
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	"github.com/avenga/couper/eval"
+)
+
+const (
+	backChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+	maxLogoutTokenSize     = 64 << 10
+)
+
+// BackChannelLogoutOptions represents the options of a <BackChannelLogout> object.
+type BackChannelLogoutOptions struct {
+	Backend     http.RoundTripper
+	ClientID    string
+	ConfContext context.Context
+	Issuer      func() (string, error)
+	JwksURI     func() (string, error)
+}
+
+// BackChannelLogout validates the logout tokens of the OpenID Connect back-channel logout
+// (https://openid.net/specs/openid-connect-backchannel-1_0.html).
+type BackChannelLogout struct {
+	backend  http.RoundTripper
+	clientID string
+	confCtx  context.Context
+	issuer   func() (string, error)
+	jwks     *JWKS
+	jwksMu   sync.Mutex
+	jwksURI  func() (string, error)
+}
+
+// NewBackChannelLogout creates a new <*BackChannelLogout> object.
+func NewBackChannelLogout(opts *BackChannelLogoutOptions) (*BackChannelLogout, error) {
+	if opts.Issuer == nil || opts.JwksURI == nil {
+		return nil, fmt.Errorf("back-channel logout requires an issuer and a jwks_uri")
+	}
+
+	return &BackChannelLogout{
+		backend:  opts.Backend,
+		clientID: opts.ClientID,
+		confCtx:  opts.ConfContext,
+		issuer:   opts.Issuer,
+		jwksURI:  opts.JwksURI,
+	}, nil
+}
+
+// Validate reads the logout_token form parameter of the given request and returns its validated claims.
+func (b *BackChannelLogout) Validate(req *http.Request) (jwt.MapClaims, error) {
+	if req.Method != http.MethodPost {
+		return nil, fmt.Errorf("wrong method (%s)", req.Method)
+	}
+
+	if req.GetBody == nil {
+		if err := eval.SetGetBody(req, maxLogoutTokenSize); err != nil {
+			return nil, err
+		}
+	}
+
+	logoutToken := req.PostFormValue("logout_token")
+	if logoutToken == "" {
+		return nil, fmt.Errorf("missing logout_token")
+	}
+
+	issuer, err := b.issuer()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithLeeway(time.Second),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(b.clientID),
+	)
+
+	claims := jwt.MapClaims{}
+	if _, err = parser.ParseWithClaims(logoutToken, claims, b.getValidationKey); err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"iat", "jti"} {
+		if _, ok := claims[name]; !ok {
+			return nil, fmt.Errorf("missing %s claim", name)
+		}
+	}
+
+	events, ok := claims["events"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing events claim")
+	}
+	if _, ok = events[backChannelLogoutEvent].(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("missing %s event", backChannelLogoutEvent)
+	}
+
+	sid, _ := claims["sid"].(string)
+	sub, _ := claims["sub"].(string)
+	if sid == "" && sub == "" {
+		return nil, fmt.Errorf("missing sid or sub claim")
+	}
+
+	if _, ok = claims["nonce"]; ok {
+		return nil, fmt.Errorf("nonce claim not allowed")
+	}
+
+	return claims, nil
+}
+
+func (b *BackChannelLogout) getValidationKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	algorithm, _ := token.Header["alg"].(string)
+	if id == "" {
+		return nil, fmt.Errorf("missing \"kid\" in JOSE header")
+	}
+	if algorithm == "" {
+		return nil, fmt.Errorf("missing \"alg\" in JOSE header")
+	}
+
+	jwks, err := b.getJWKS()
+	if err != nil {
+		return nil, err
+	}
+
+	jwk, err := jwks.GetKey(id, algorithm, "sig")
+	if err != nil {
+		return nil, err
+	}
+	if jwk == nil {
+		return nil, fmt.Errorf("no matching %s JWK for kid %q", algorithm, id)
+	}
+	return jwk.Key, nil
+}
+
+// getJWKS returns the JWKS of the current jwks_uri which may change with the OpenID configuration.
+func (b *BackChannelLogout) getJWKS() (*JWKS, error) {
+	uri, err := b.jwksURI()
+	if err != nil {
+		return nil, err
+	}
+	if uri == "" {
+		return nil, fmt.Errorf("missing jwks_uri")
+	}
+
+	b.jwksMu.Lock()
+	defer b.jwksMu.Unlock()
+
+	if b.jwks == nil || b.jwks.uri != uri {
+		if b.jwks, err = NewJWKS(uri, "", b.backend, b.confCtx); err != nil {
+			return nil, err
+		}
+	}
+	return b.jwks, nil
+}
@@ -8,23 +8,34 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/avenga/couper/errors"
 )
 
 var _ AccessControl = &BasicAuth{}
 
+// htFileCheckInterval is the minimum interval between two checks for a modified htpasswd file.
+const htFileCheckInterval = time.Second
+
 // BasicAuth represents an AC-BasicAuth object
 type BasicAuth struct {
-	htFile htData
-	name   string
-	user   string
-	pass   string
+	file      string
+	htFile    htData
+	ldap      *LDAP
+	mu        sync.RWMutex
+	modTime   time.Time
+	name      string
+	nextCheck time.Time
+	user      string
+	pass      string
 }
 
 // NewBasicAuth creates a new AC-BasicAuth object
 func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 	ba := &BasicAuth{
+		file:   file,
 		htFile: make(htData),
 		name:   name,
 		user:   user,
@@ -35,12 +46,29 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 		return ba, nil
 	}
 
+	var err error
+	if ba.htFile, err = readHTFile(file); err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	ba.modTime = fileInfo.ModTime()
+	ba.nextCheck = time.Now().Add(htFileCheckInterval)
+
+	return ba, nil
+}
+
+func readHTFile(file string) (htData, error) {
 	fp, err := os.Open(file)
 	if err != nil {
 		return nil, err
 	}
 	defer fp.Close()
 
+	data := make(htData)
 	scanner := bufio.NewScanner(fp)
 	var lineNr int
 	for scanner.Scan() {
@@ -61,7 +89,7 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 
 		username, password := up[0], up[1]
 
-		if _, ok := ba.htFile[username]; ok {
+		if _, ok := data[username]; ok {
 			return nil, fmt.Errorf("multiple user: " + username)
 		}
 
@@ -79,24 +107,71 @@ func NewBasicAuth(name, user, pass, file string) (*BasicAuth, error) {
 				return nil, fmt.Errorf("parse error: malformed password for user: " + username)
 			}
 
-			ba.htFile[username] = pwd{
+			data[username] = pwd{
 				pwdOrig:   []byte(password),
 				pwdPrefix: prefix,
 				pwdSalt:   parts[0],
 				pwdType:   pwdType,
 			}
-		case pwdTypeBcrypt:
-			ba.htFile[username] = pwd{
+		case pwdTypeBcrypt, pwdTypeSHA1:
+			data[username] = pwd{
 				pwdOrig: []byte(password),
 				pwdType: pwdType,
 			}
+		case pwdTypeSHA256, pwdTypeSHA512:
+			prefix := pwdPrefixSHA256
+			if pwdType == pwdTypeSHA512 {
+				prefix = pwdPrefixSHA512
+			}
+
+			p, perr := parseShaCrypt(password, prefix)
+			if perr != nil {
+				return nil, fmt.Errorf("parse error: malformed password for user: " + username)
+			}
+			data[username] = p
+		case pwdTypeArgon2id:
+			p, perr := parseArgon2id(password)
+			if perr != nil {
+				return nil, fmt.Errorf("parse error: malformed password for user: " + username)
+			}
+			data[username] = p
 		default:
 			return nil, fmt.Errorf("parse error: algorithm not supported")
 		}
 	}
 
-	err = scanner.Err()
-	return ba, err
+	return data, scanner.Err()
+}
+
+// getHTData returns the htpasswd data and reloads the file if it has been modified.
+// A failed reload keeps the previously loaded data.
+func (ba *BasicAuth) getHTData() htData {
+	ba.mu.RLock()
+	data, check := ba.htFile, ba.file != "" && time.Now().After(ba.nextCheck)
+	ba.mu.RUnlock()
+
+	if !check {
+		return data
+	}
+
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+
+	if !time.Now().After(ba.nextCheck) { // concurrently checked
+		return ba.htFile
+	}
+	ba.nextCheck = time.Now().Add(htFileCheckInterval)
+
+	fileInfo, err := os.Stat(ba.file)
+	if err != nil || fileInfo.ModTime().Equal(ba.modTime) {
+		return ba.htFile
+	}
+
+	if reloaded, rerr := readHTFile(ba.file); rerr == nil {
+		ba.htFile = reloaded
+		ba.modTime = fileInfo.ModTime()
+	}
+	return ba.htFile
 }
 
 // Validate implements the AccessControl interface
@@ -114,6 +189,12 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 		return errors.BasicAuth.Message("reading authorization failed")
 	}
 
+	if ba.ldap != nil {
+		return ba.ldap.validate(req, user, pass)
+	}
+
+	htFile := ba.getHTData()
+
 	if ba.user == user {
 		if ba.pass != "" {
 			if subtle.ConstantTimeCompare([]byte(ba.pass), []byte(pass)) == 1 {
@@ -122,13 +203,13 @@ func (ba *BasicAuth) Validate(req *http.Request) error {
 			return errors.BasicAuth.Message("credential mismatch")
 		}
 
-		if len(ba.htFile) == 0 {
+		if len(htFile) == 0 {
 			return errors.BasicAuth.Message("no password configured")
 		}
 	}
 
-	if len(ba.htFile) > 0 {
-		if validateAccessData(user, pass, ba.htFile) {
+	if len(htFile) > 0 {
+		if validateAccessData(user, pass, htFile) {
 			return nil
 		}
 		return errors.BasicAuth.Message("file: credential mismatch")
@@ -3,9 +3,17 @@ package accesscontrol
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
 	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -16,6 +24,10 @@ const (
 	pwdPrefixBcrypt2x = "$2x$"
 	pwdPrefixBcrypt2y = "$2y$"
 	pwdPrefixMD5      = "$1$"
+	pwdPrefixSHA1     = "{SHA}"
+	pwdPrefixSHA256   = "$5$"
+	pwdPrefixSHA512   = "$6$"
+	pwdPrefixArgon2id = "$argon2id$"
 )
 
 const (
@@ -23,12 +35,35 @@ const (
 	pwdTypeApr1
 	pwdTypeBcrypt
 	pwdTypeMD5
+	pwdTypeSHA1
+	pwdTypeSHA256
+	pwdTypeSHA512
+	pwdTypeArgon2id
 )
 
 const (
 	aprCharacters    = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
 	aprMd5DigestSize = 16
 	aprMuddleRounds  = 1000
+
+	shaCryptRoundsDefault = 5000
+	shaCryptRoundsMin     = 1000
+	shaCryptRoundsMax     = 999999999
+	shaCryptSaltMaxLen    = 16
+)
+
+// shaCrypt256Order and shaCrypt512Order are the byte transpositions of the final
+// digests before the base64 encoding, see https://www.akkadia.org/drepper/SHA-crypt.txt
+var (
+	shaCrypt256Order = []byte{
+		0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14, 15, 25, 5, 6, 16, 26, 27, 7, 17, 18, 28, 8, 9, 19, 29,
+		31, 30,
+	}
+	shaCrypt512Order = []byte{
+		0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47, 5, 26, 6, 27, 48, 28, 49, 7, 50, 8, 29, 9, 30, 51,
+		31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35, 15, 36, 57, 37, 58, 16, 59, 17, 38, 18, 39, 60,
+		40, 61, 19, 62, 20, 41, 63,
+	}
 )
 
 var pwdPrefixes = map[string]int{
@@ -38,17 +73,31 @@ var pwdPrefixes = map[string]int{
 	pwdPrefixBcrypt2x: pwdTypeBcrypt,
 	pwdPrefixBcrypt2y: pwdTypeBcrypt,
 	pwdPrefixMD5:      pwdTypeMD5,
+	pwdPrefixSHA1:     pwdTypeSHA1,
+	pwdPrefixSHA256:   pwdTypeSHA256,
+	pwdPrefixSHA512:   pwdTypeSHA512,
+	pwdPrefixArgon2id: pwdTypeArgon2id,
 }
 
 type htData map[string]pwd
 
 type pwd struct {
+	argon2    *argon2Params
 	pwdOrig   []byte
 	pwdPrefix string
+	pwdRounds int
 	pwdSalt   string
 	pwdType   int
 }
 
+type argon2Params struct {
+	hash    []byte
+	memory  uint32
+	salt    []byte
+	threads uint8
+	time    uint32
+}
+
 func getPwdType(pass string) int {
 	for p, t := range pwdPrefixes {
 		if strings.HasPrefix(pass, p) {
@@ -73,6 +122,21 @@ func validateAccessData(plainUser, plainPass string, data htData) bool {
 				if err := bcrypt.CompareHashAndPassword(pass.pwdOrig, []byte(plainPass)); err == nil {
 					return true
 				}
+			case pwdTypeSHA1:
+				sum := sha1.Sum([]byte(plainPass))
+				if subtle.ConstantTimeCompare([]byte(pwdPrefixSHA1+base64.StdEncoding.EncodeToString(sum[:])), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeSHA256, pwdTypeSHA512:
+				if subtle.ConstantTimeCompare(shaCrypt(plainPass, pass), pass.pwdOrig) == 1 {
+					return true
+				}
+			case pwdTypeArgon2id:
+				p := pass.argon2
+				key := argon2.IDKey([]byte(plainPass), p.salt, p.time, p.memory, p.threads, uint32(len(p.hash)))
+				if subtle.ConstantTimeCompare(key, p.hash) == 1 {
+					return true
+				}
 			}
 		}
 	}
@@ -164,3 +228,181 @@ func apr1MD5(pass, salt, pref string) []byte {
 
 	return buf.Bytes()
 }
+
+// parseShaCrypt parses the optional rounds parameter and the salt of a SHA-256 ($5$)
+// or SHA-512 ($6$) crypt password.
+func parseShaCrypt(password, prefix string) (pwd, error) {
+	p := pwd{
+		pwdOrig:   []byte(password),
+		pwdPrefix: prefix,
+		pwdRounds: shaCryptRoundsDefault,
+		pwdType:   pwdPrefixes[prefix],
+	}
+
+	parts := strings.Split(strings.TrimPrefix(password, prefix), "$")
+	if len(parts) == 3 && strings.HasPrefix(parts[0], "rounds=") {
+		rounds, err := strconv.Atoi(strings.TrimPrefix(parts[0], "rounds="))
+		if err != nil {
+			return p, err
+		}
+		if rounds < shaCryptRoundsMin {
+			rounds = shaCryptRoundsMin
+		} else if rounds > shaCryptRoundsMax {
+			rounds = shaCryptRoundsMax
+		}
+		p.pwdPrefix += "rounds=" + strconv.Itoa(rounds) + "$"
+		p.pwdRounds = rounds
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return p, fmt.Errorf("invalid format")
+	}
+
+	p.pwdSalt = parts[0]
+	if len(p.pwdSalt) > shaCryptSaltMaxLen {
+		p.pwdSalt = p.pwdSalt[:shaCryptSaltMaxLen]
+	}
+	return p, nil
+}
+
+// parseArgon2id parses a password in the PHC string format, e.g.
+// $argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+func parseArgon2id(password string) (pwd, error) {
+	parts := strings.Split(password, "$")
+	if len(parts) != 6 || parts[2] != "v=19" {
+		return pwd{}, fmt.Errorf("invalid format")
+	}
+
+	params := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return pwd{}, err
+	}
+	if params.time == 0 || params.threads == 0 {
+		return pwd{}, fmt.Errorf("invalid parameters")
+	}
+
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return pwd{}, err
+	}
+	if params.hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(params.hash) == 0 {
+		return pwd{}, fmt.Errorf("invalid hash")
+	}
+
+	return pwd{
+		argon2:  params,
+		pwdOrig: []byte(password),
+		pwdType: pwdTypeArgon2id,
+	}, nil
+}
+
+// shaCrypt implements the SHA-256 and SHA-512 based crypt algorithms.
+func shaCrypt(pass string, p pwd) []byte {
+	newHash, order := sha256.New, shaCrypt256Order
+	if p.pwdType == pwdTypeSHA512 {
+		newHash, order = sha512.New, shaCrypt512Order
+	}
+
+	password, salt := []byte(pass), []byte(p.pwdSalt)
+
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	altSum := h.Sum(nil)
+
+	h.Reset()
+	h.Write(password)
+	h.Write(salt)
+	writeRepeated(h, altSum, len(password))
+	for i := len(password); i > 0; i >>= 1 {
+		if (i & 1) == 1 {
+			h.Write(altSum)
+		} else {
+			h.Write(password)
+		}
+	}
+	sum := h.Sum(nil)
+
+	h.Reset()
+	for i := 0; i < len(password); i++ {
+		h.Write(password)
+	}
+	pBytes := repeat(h.Sum(nil), len(password))
+
+	h.Reset()
+	for i := 0; i < 16+int(sum[0]); i++ {
+		h.Write(salt)
+	}
+	sBytes := repeat(h.Sum(nil), len(salt))
+
+	for i := 0; i < p.pwdRounds; i++ {
+		h.Reset()
+
+		if (i & 1) == 1 {
+			h.Write(pBytes)
+		} else {
+			h.Write(sum)
+		}
+
+		if (i % 3) != 0 {
+			h.Write(sBytes)
+		}
+
+		if (i % 7) != 0 {
+			h.Write(pBytes)
+		}
+
+		if (i & 1) == 1 {
+			h.Write(sum)
+		} else {
+			h.Write(pBytes)
+		}
+
+		sum = h.Sum(sum[:0])
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(p.pwdPrefix)
+	buf.WriteString(p.pwdSalt)
+	buf.WriteByte('$')
+
+	// groups of three bytes, the remaining one or two bytes are encoded with two or three characters
+	for i := 0; i < len(order); i += 3 {
+		var v uint
+		n := 4
+		switch len(order) - i {
+		case 1:
+			v, n = uint(sum[order[i]]), 2
+		case 2:
+			v, n = (uint(sum[order[i]])<<8)+uint(sum[order[i+1]]), 3
+		default:
+			v = (uint(sum[order[i]]) << 16) + (uint(sum[order[i+1]]) << 8) + uint(sum[order[i+2]])
+		}
+
+		for j := 0; j < n; j++ {
+			buf.WriteByte(aprCharacters[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	return buf.Bytes()
+}
+
+func writeRepeated(h hash.Hash, b []byte, n int) {
+	for ; n > len(b); n -= len(b) {
+		h.Write(b)
+	}
+	h.Write(b[:n])
+}
+
+func repeat(b []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		if n-len(out) < len(b) {
+			return append(out, b[:n-len(out)]...)
+		}
+		out = append(out, b...)
+	}
+	return out
+}
@@ -1,6 +1,9 @@
 package accesscontrol
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func Test_Apr1MD5(t *testing.T) {
 	var exp, res string
@@ -65,3 +68,89 @@ func Test_ValidateAccessData(t *testing.T) {
 		t.Error("Unexpected validation failure")
 	}
 }
+
+func Test_ShaCrypt(t *testing.T) {
+	for _, exp := range []string{
+		// https://www.akkadia.org/drepper/SHA-crypt.txt
+		"$5$rounds=10000$saltstringsaltst$3xv.VbSHBb41AL9AvLeujZkZRBAwqFMz2.opqey6IcA",
+		"$5$saltstring$5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5",
+		"$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.",
+	} {
+		prefix := exp[:3]
+		p, err := parseShaCrypt(exp, prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res := string(shaCrypt("Hello world!", p)); res != exp {
+			t.Errorf("Got unexpected password: '%s', want '%s'", res, exp)
+		}
+	}
+
+	// password longer than the digest size
+	exp := "$6$saltstring$xk3RYJPwdMM1jHTsO4sGQKAnWAepDHdFl5FzkfZXe86AJAXEJOC3zrxzGWfASl1Psi0EH.o0sgfsblAqTeFnR1"
+	p, err := parseShaCrypt(exp, pwdPrefixSHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := string(shaCrypt(strings.Repeat("a", 70), p)); res != exp {
+		t.Errorf("Got unexpected password: '%s', want '%s'", res, exp)
+	}
+
+	for _, malformed := range []string{"$5$", "$5$rounds=x$salt$hash", "$5$a$b$c$d"} {
+		if _, err = parseShaCrypt(malformed, pwdPrefixSHA256); err == nil {
+			t.Errorf("Expected error for malformed password: %q", malformed)
+		}
+	}
+}
+
+func Test_ValidateAccessData_AdditionalFormats(t *testing.T) {
+	var data htData = make(htData)
+	var pass string = "my-pass"
+
+	// python3 -c 'import crypt; print(crypt.crypt("my-pass", "$5$Xq2MyE1bTz0xJ6cD"))'
+	sha256Pwd, err := parseShaCrypt("$5$Xq2MyE1bTz0xJ6cD$PaPr5WuWunbbKOY053AiS96iiCiUkINS5XPi3.JYoJ4", pwdPrefixSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["sha256"] = sha256Pwd
+
+	// python3 -c 'import crypt; print(crypt.crypt("my-pass", "$6$rounds=2000$aD3mQ9sPzF1"))'
+	sha512Pwd, err := parseShaCrypt("$6$rounds=2000$aD3mQ9sPzF1$v37t2PgTeeIn/U5YSaTOujuUBz.ek/EOitw5YhNzC5pDcyGtfNFYm1zpKaXx3yYoiZJSUNKcBrhkyjy8q5/YW1", pwdPrefixSHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["sha512"] = sha512Pwd
+
+	// $ htpasswd -bs .htpasswd sha1 my-pass
+	data["sha1"] = pwd{
+		pwdOrig: []byte("{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc="),
+		pwdType: pwdTypeSHA1,
+	}
+
+	argon2Pwd, err := parseArgon2id("$argon2id$v=19$m=1024,t=2,p=1$Y291cGVyLXNhbHQtMTIzNA$EFNkJ9cQ2SUE99C5OF3BIsPNRJaYRXJkq5sLTrOAMIc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data["argon2id"] = argon2Pwd
+
+	for user := range data {
+		if !validateAccessData(user, pass, data) {
+			t.Errorf("Unexpected validation failure for %q", user)
+		}
+		if validateAccessData(user, "my-bass", data) {
+			t.Errorf("Unexpected validation success for %q", user)
+		}
+	}
+
+	for _, malformed := range []string{
+		"$argon2id$v=19$m=1024,t=2,p=1$Y291cGVy",
+		"$argon2id$v=16$m=1024,t=2,p=1$Y291cGVy$EFNkJ9cQ",
+		"$argon2id$v=19$m=1024,t=0,p=1$Y291cGVy$EFNkJ9cQ",
+		"$argon2id$v=19$m=1024,t=2,p=1$Y291cGVy$",
+		"$argon2id$v=19$m=1024,t=2,p=1$!$EFNkJ9cQ",
+	} {
+		if _, err = parseArgon2id(malformed); err == nil {
+			t.Errorf("Expected error for malformed password: %q", malformed)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package accesscontrol
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/errors"
+)
+
+const ldapUsernamePlaceholder = "{username}"
+
+// LDAPOptions represents the options of a <LDAP> object.
+type LDAPOptions struct {
+	BindDN         string
+	BindDNTemplate string
+	BindPassword   string
+	CacheTTL       time.Duration
+	GroupAttribute string
+	MemStore       *cache.MemoryStore
+	RequiredGroups []string
+	RoleMap        map[string][]string
+	SearchBaseDN   string
+	SearchFilter   string
+	StartTLS       bool
+	Timeout        time.Duration
+	TLSConfig      *tls.Config
+	URL            string
+}
+
+// LDAP authenticates basic auth credentials with a bind at a directory server. The
+// bind DN is either created from a template or searched with a service account.
+type LDAP struct {
+	name string
+	opts *LDAPOptions
+}
+
+type ldapUser struct {
+	DN     string
+	Groups []string
+}
+
+// NewLDAPBasicAuth creates a new AC-BasicAuth object which validates the credentials with LDAP.
+func NewLDAPBasicAuth(name string, opts *LDAPOptions) (*BasicAuth, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("ldap: url scheme must be ldap or ldaps")
+	}
+	if opts.StartTLS && u.Scheme == "ldaps" {
+		return nil, fmt.Errorf("ldap: start_tls not allowed with ldaps")
+	}
+
+	if (opts.BindDNTemplate == "") == (opts.SearchBaseDN == "") {
+		return nil, fmt.Errorf("ldap: either bind_dn_template or search_base_dn is required")
+	}
+	if opts.BindDNTemplate != "" && !strings.Contains(opts.BindDNTemplate, ldapUsernamePlaceholder) {
+		return nil, fmt.Errorf("ldap: bind_dn_template must contain %s", ldapUsernamePlaceholder)
+	}
+	if opts.SearchBaseDN != "" {
+		if !strings.Contains(opts.SearchFilter, ldapUsernamePlaceholder) {
+			return nil, fmt.Errorf("ldap: search_filter must contain %s", ldapUsernamePlaceholder)
+		}
+		if _, err = ldap.CompileFilter(strings.ReplaceAll(opts.SearchFilter, ldapUsernamePlaceholder, "user")); err != nil {
+			return nil, fmt.Errorf("ldap: invalid search_filter: %w", err)
+		}
+	}
+
+	if opts.CacheTTL > 0 && opts.MemStore == nil {
+		return nil, fmt.Errorf("ldap: cache requires a memory store")
+	}
+
+	return &BasicAuth{
+		htFile: make(htData),
+		ldap:   &LDAP{name: name, opts: opts},
+		name:   name,
+	}, nil
+}
+
+// validate authenticates the given credentials and stores the user's distinguished name
+// and groups in the request context.
+func (l *LDAP) validate(req *http.Request, user, pass string) error {
+	// an empty password results in an unauthenticated bind which would succeed
+	if user == "" || pass == "" {
+		return errors.BasicAuth.Message("ldap: credential mismatch")
+	}
+
+	lu, err := l.authenticate(user, pass)
+	if err != nil {
+		return err
+	}
+
+	if len(l.opts.RequiredGroups) > 0 && !hasAnyGroup(lu.Groups, l.opts.RequiredGroups) {
+		return errors.BasicAuth.Message("ldap: required group membership missing")
+	}
+
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+	acMap[l.name] = map[string]interface{}{
+		"user":   user,
+		"dn":     lu.DN,
+		"groups": lu.Groups,
+	}
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+
+	var scopeValues []string
+	for _, group := range lu.Groups {
+		for _, s := range l.opts.RoleMap[group] {
+			scopeValues = addScopeValue(scopeValues, s)
+		}
+	}
+	if len(scopeValues) > 0 {
+		scopes, ok := ctx.Value(request.Scopes).([]string)
+		if !ok {
+			scopes = []string{}
+		}
+		scopes = append(scopes, scopeValues...)
+		ctx = context.WithValue(ctx, request.Scopes, scopes)
+	}
+
+	*req = *req.WithContext(ctx)
+	return nil
+}
+
+// authenticate binds with the given credentials and reads the groups of the user.
+// Unavailable or misconfigured directory servers result in backend errors.
+func (l *LDAP) authenticate(user, pass string) (*ldapUser, error) {
+	var key string
+	if l.opts.CacheTTL > 0 {
+		sum := sha256.Sum256([]byte(user + "\x00" + pass))
+		key = "ldap|" + l.name + "|" + hex.EncodeToString(sum[:])
+		if lu, ok := l.opts.MemStore.Get(key).(*ldapUser); ok {
+			return lu, nil
+		}
+	}
+
+	conn, err := ldap.DialURL(l.opts.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: l.opts.Timeout}),
+		ldap.DialWithTLSConfig(l.opts.TLSConfig))
+	if err != nil {
+		return nil, l.newError(err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(l.opts.Timeout)
+
+	if l.opts.StartTLS {
+		u, _ := url.Parse(l.opts.URL)
+		if err = conn.StartTLS(withServerName(l.opts.TLSConfig, u.Hostname())); err != nil {
+			return nil, l.newError(err)
+		}
+	}
+
+	lu := &ldapUser{}
+	attributes := []string{l.opts.GroupAttribute}
+
+	if l.opts.BindDNTemplate != "" {
+		lu.DN = strings.ReplaceAll(l.opts.BindDNTemplate, ldapUsernamePlaceholder, escapeDN(user))
+		if err = conn.Bind(lu.DN, pass); err != nil {
+			return nil, l.newError(err)
+		}
+
+		result, serr := conn.Search(l.newSearchRequest(lu.DN, ldap.ScopeBaseObject, "(objectClass=*)", attributes))
+		if serr != nil {
+			return nil, l.newError(serr)
+		}
+		if len(result.Entries) == 1 {
+			lu.Groups = result.Entries[0].GetEqualFoldAttributeValues(l.opts.GroupAttribute)
+		}
+	} else {
+		if err = conn.Bind(l.opts.BindDN, l.opts.BindPassword); err != nil {
+			return nil, errors.Backend.Label(l.name).Message("ldap: service account bind").With(err)
+		}
+
+		filter := strings.ReplaceAll(l.opts.SearchFilter, ldapUsernamePlaceholder, ldap.EscapeFilter(user))
+		result, serr := conn.Search(l.newSearchRequest(l.opts.SearchBaseDN, ldap.ScopeWholeSubtree, filter, attributes))
+		if serr != nil {
+			return nil, l.newError(serr)
+		}
+		if len(result.Entries) != 1 {
+			return nil, errors.BasicAuth.Messagef("ldap: credential mismatch: %d entries found", len(result.Entries))
+		}
+
+		lu.DN = result.Entries[0].DN
+		lu.Groups = result.Entries[0].GetEqualFoldAttributeValues(l.opts.GroupAttribute)
+
+		if err = conn.Bind(lu.DN, pass); err != nil {
+			return nil, l.newError(err)
+		}
+	}
+
+	if key != "" {
+		l.opts.MemStore.Set(key, lu, int64(l.opts.CacheTTL.Seconds()))
+	}
+	return lu, nil
+}
+
+func (l *LDAP) newSearchRequest(baseDN string, scope int, filter string, attributes []string) *ldap.SearchRequest {
+	return ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 0, int(l.opts.Timeout.Seconds()), false,
+		filter, attributes, nil)
+}
+
+// newError maps connection and TLS failures to backend errors and all other
+// LDAP errors, e.g. wrong credentials, to basic_auth errors.
+func (l *LDAP) newError(err error) error {
+	switch {
+	case ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials):
+		return errors.BasicAuth.Message("ldap: credential mismatch")
+	case ldap.IsErrorWithCode(err, ldap.ErrorNetwork):
+		return errors.Backend.Label(l.name).Message("ldap").With(err)
+	default:
+		return errors.BasicAuth.Message("ldap").With(err)
+	}
+}
+
+// escapeDN escapes the special characters of an attribute value within a distinguished name (RFC 4514, section 2.4).
+func escapeDN(value string) string {
+	sb := &strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c == ',' || c == '+' || c == '"' || c == '\\' || c == '<' || c == '>' || c == ';' || c == '=':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == 0:
+			sb.WriteString("\\00")
+		case (c == ' ' || c == '#') && i == 0, c == ' ' && i == len(value)-1:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func withServerName(tlsConfig *tls.Config, host string) *tls.Config {
+	var conf *tls.Config
+	if tlsConfig != nil {
+		conf = tlsConfig.Clone()
+	} else {
+		conf = &tls.Config{}
+	}
+	if conf.ServerName == "" {
+		conf.ServerName = host
+	}
+	return conf
+}
+
+func hasAnyGroup(groups, required []string) bool {
+	for _, r := range required {
+		for _, g := range groups {
+			if strings.EqualFold(g, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package accesscontrol_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config/request"
+	couperErr "github.com/avenga/couper/errors"
+	"github.com/avenga/couper/internal/test"
+	"github.com/avenga/couper/internal/test/ldaptest"
+)
+
+func newLDAPEntries() []*ldaptest.Entry {
+	return []*ldaptest.Entry{
+		{DN: "cn=service,dc=example,dc=com", Attributes: map[string][]string{
+			ldaptest.PasswordAttribute: {"secret"},
+		}},
+		{DN: "uid=john,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"uid":                      {"john"},
+			"memberOf":                 {"cn=admins,ou=groups,dc=example,dc=com", "cn=users,ou=groups,dc=example,dc=com"},
+			ldaptest.PasswordAttribute: {"pass"},
+		}},
+		{DN: "uid=jane,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"uid":                      {"jane"},
+			"memberOf":                 {"cn=users,ou=groups,dc=example,dc=com"},
+			ldaptest.PasswordAttribute: {"pass"},
+		}},
+	}
+}
+
+func Test_NewLDAPBasicAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *ac.LDAPOptions
+		expErr string
+	}{
+		{"template", &ac.LDAPOptions{URL: "ldap://localhost", BindDNTemplate: "uid={username},dc=example"}, ""},
+		{"search", &ac.LDAPOptions{URL: "ldaps://localhost", SearchBaseDN: "dc=example", SearchFilter: "(uid={username})"}, ""},
+		{"scheme", &ac.LDAPOptions{URL: "http://localhost", BindDNTemplate: "uid={username}"}, "ldap: url scheme must be ldap or ldaps"},
+		{"start_tls with ldaps", &ac.LDAPOptions{URL: "ldaps://localhost", StartTLS: true, BindDNTemplate: "uid={username}"}, "ldap: start_tls not allowed with ldaps"},
+		{"missing mode", &ac.LDAPOptions{URL: "ldap://localhost"}, "ldap: either bind_dn_template or search_base_dn is required"},
+		{"both modes", &ac.LDAPOptions{URL: "ldap://localhost", BindDNTemplate: "uid={username}", SearchBaseDN: "dc=example"}, "ldap: either bind_dn_template or search_base_dn is required"},
+		{"template placeholder", &ac.LDAPOptions{URL: "ldap://localhost", BindDNTemplate: "uid=john"}, "ldap: bind_dn_template must contain {username}"},
+		{"filter placeholder", &ac.LDAPOptions{URL: "ldap://localhost", SearchBaseDN: "dc=example", SearchFilter: "(uid=john)"}, "ldap: search_filter must contain {username}"},
+		{"filter syntax", &ac.LDAPOptions{URL: "ldap://localhost", SearchBaseDN: "dc=example", SearchFilter: "uid={username}"}, `ldap: invalid search_filter: LDAP Result Code 201 "Filter Compile Error": ldap: filter does not start with an '('`},
+		{"cache without store", &ac.LDAPOptions{URL: "ldap://localhost", BindDNTemplate: "uid={username}", CacheTTL: time.Minute}, "ldap: cache requires a memory store"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := ac.NewLDAPBasicAuth("ba", tt.opts)
+			if tt.expErr == "" && err != nil {
+				subT.Fatal(err)
+			}
+			if tt.expErr != "" && (err == nil || err.Error() != tt.expErr) {
+				subT.Errorf("expected error %q, got: %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func Test_LDAPBasicAuth_Validate(t *testing.T) {
+	srv := ldaptest.NewServer(newLDAPEntries()...)
+	defer srv.Close()
+
+	tlsSrv := ldaptest.NewTLSServer(newLDAPEntries()...)
+	defer tlsSrv.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	tlsPool := x509.NewCertPool()
+	tlsPool.AddCert(tlsSrv.Certificate())
+
+	template := "uid={username},ou=people,dc=example,dc=com"
+
+	tests := []struct {
+		name       string
+		opts       *ac.LDAPOptions
+		user, pass string
+		expErr     string
+		expGroups  []string
+		expScopes  []string
+	}{
+		{"template bind", &ac.LDAPOptions{URL: srv.URL, BindDNTemplate: template, GroupAttribute: "memberOf"},
+			"jane", "pass", "", []string{"cn=users,ou=groups,dc=example,dc=com"}, nil},
+		{"template bind wrong password", &ac.LDAPOptions{URL: srv.URL, BindDNTemplate: template, GroupAttribute: "memberOf"},
+			"jane", "wrong", "ldap: credential mismatch", nil, nil},
+		{"template bind empty password", &ac.LDAPOptions{URL: srv.URL, BindDNTemplate: template, GroupAttribute: "memberOf"},
+			"jane", "", "ldap: credential mismatch", nil, nil},
+		{"search bind", &ac.LDAPOptions{URL: srv.URL, BindDN: "cn=service,dc=example,dc=com", BindPassword: "secret",
+			SearchBaseDN: "ou=people,dc=example,dc=com", SearchFilter: "(uid={username})", GroupAttribute: "memberOf"},
+			"john", "pass", "", []string{"cn=admins,ou=groups,dc=example,dc=com", "cn=users,ou=groups,dc=example,dc=com"}, nil},
+		{"search bind unknown user", &ac.LDAPOptions{URL: srv.URL, BindDN: "cn=service,dc=example,dc=com", BindPassword: "secret",
+			SearchBaseDN: "ou=people,dc=example,dc=com", SearchFilter: "(uid={username})", GroupAttribute: "memberOf"},
+			"*", "pass", "ldap: credential mismatch", nil, nil},
+		{"search bind wrong service password", &ac.LDAPOptions{URL: srv.URL, BindDN: "cn=service,dc=example,dc=com", BindPassword: "wrong",
+			SearchBaseDN: "ou=people,dc=example,dc=com", SearchFilter: "(uid={username})", GroupAttribute: "memberOf"},
+			"john", "pass", `ldap: service account bind: LDAP Result Code 49 "Invalid Credentials"`, nil, nil},
+		{"start_tls", &ac.LDAPOptions{URL: srv.URL, StartTLS: true, TLSConfig: &tls.Config{RootCAs: pool},
+			BindDNTemplate: template, GroupAttribute: "memberOf"},
+			"jane", "pass", "", []string{"cn=users,ou=groups,dc=example,dc=com"}, nil},
+		{"start_tls untrusted", &ac.LDAPOptions{URL: srv.URL, StartTLS: true, BindDNTemplate: template, GroupAttribute: "memberOf"},
+			"jane", "pass", "x509: certificate signed by unknown authority", nil, nil},
+		{"ldaps", &ac.LDAPOptions{URL: tlsSrv.URL, TLSConfig: &tls.Config{RootCAs: tlsPool},
+			BindDNTemplate: template, GroupAttribute: "memberOf"},
+			"jane", "pass", "", []string{"cn=users,ou=groups,dc=example,dc=com"}, nil},
+		{"required groups", &ac.LDAPOptions{URL: srv.URL, BindDNTemplate: template, GroupAttribute: "memberOf",
+			RequiredGroups: []string{"cn=admins,ou=groups,dc=example,dc=com"}},
+			"jane", "pass", "ldap: required group membership missing", nil, nil},
+		{"role map", &ac.LDAPOptions{URL: srv.URL, BindDNTemplate: template, GroupAttribute: "memberOf",
+			RequiredGroups: []string{"CN=admins,ou=groups,dc=example,dc=com"},
+			RoleMap: map[string][]string{
+				"cn=admins,ou=groups,dc=example,dc=com": {"read", "write"},
+				"cn=users,ou=groups,dc=example,dc=com":  {"read"},
+			}},
+			"john", "pass", "", []string{"cn=admins,ou=groups,dc=example,dc=com", "cn=users,ou=groups,dc=example,dc=com"}, []string{"read", "write"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			tt.opts.Timeout = time.Second
+			ba, err := ac.NewLDAPBasicAuth("ba", tt.opts)
+			h.Must(err)
+
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.SetBasicAuth(tt.user, tt.pass)
+
+			err = ba.Validate(req)
+			if tt.expErr != "" {
+				if err == nil {
+					subT.Fatalf("expected error %q", tt.expErr)
+				}
+				if msg := err.(couperErr.GoError).LogError(); !strings.Contains(msg, tt.expErr) {
+					subT.Errorf("expected error message to contain %q, got: %q", tt.expErr, msg)
+				}
+				return
+			}
+			h.Must(err)
+
+			acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+			data := acMap["ba"].(map[string]interface{})
+			if data["user"] != tt.user {
+				subT.Errorf("expected user %q, got: %v", tt.user, data["user"])
+			}
+			if !reflect.DeepEqual(data["groups"], tt.expGroups) {
+				subT.Errorf("expected groups %v, got: %v", tt.expGroups, data["groups"])
+			}
+
+			scopes, _ := req.Context().Value(request.Scopes).([]string)
+			if !reflect.DeepEqual(scopes, tt.expScopes) {
+				subT.Errorf("expected scopes %v, got: %v", tt.expScopes, scopes)
+			}
+		})
+	}
+}
+
+func Test_LDAPBasicAuth_Unavailable(t *testing.T) {
+	srv := ldaptest.NewServer(newLDAPEntries()...)
+	defer srv.Close()
+
+	unreachable := ldaptest.NewServer()
+	unreachable.Close()
+
+	template := "uid={username},ou=people,dc=example,dc=com"
+
+	tests := []struct {
+		name string
+		opts *ac.LDAPOptions
+	}{
+		{"unreachable", &ac.LDAPOptions{URL: unreachable.URL, BindDNTemplate: template}},
+		{"start_tls untrusted", &ac.LDAPOptions{URL: srv.URL, StartTLS: true, BindDNTemplate: template}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			tt.opts.Timeout = time.Second
+			ba, err := ac.NewLDAPBasicAuth("ba", tt.opts)
+			h.Must(err)
+
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.SetBasicAuth("jane", "pass")
+
+			err = ba.Validate(req)
+			if !couperErr.Equals(err, couperErr.Backend) {
+				subT.Fatalf("expected a backend error, got: %v", err)
+			}
+			if status := err.(couperErr.GoError).HTTPStatus(); status != http.StatusBadGateway {
+				subT.Errorf("expected status %d, got: %d", http.StatusBadGateway, status)
+			}
+		})
+	}
+}
+
+func Test_LDAPBasicAuth_Cache(t *testing.T) {
+	helper := test.New(t)
+
+	srv := ldaptest.NewServer(newLDAPEntries()...)
+	defer srv.Close()
+
+	memStore := cache.New(nil, nil)
+	ba, err := ac.NewLDAPBasicAuth("ba", &ac.LDAPOptions{
+		URL:            srv.URL,
+		BindDNTemplate: "uid={username},ou=people,dc=example,dc=com",
+		CacheTTL:       time.Minute,
+		GroupAttribute: "memberOf",
+		MemStore:       memStore,
+		Timeout:        time.Second,
+	})
+	helper.Must(err)
+
+	validate := func(pass string) error {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		req.SetBasicAuth("jane", pass)
+		return ba.Validate(req)
+	}
+
+	helper.Must(validate("pass"))
+	helper.Must(validate("pass"))
+	if n := srv.BindCount(); n != 1 {
+		t.Errorf("expected one bind, got: %d", n)
+	}
+
+	if err = validate("wrong"); err == nil {
+		t.Error("expected credential mismatch")
+	}
+	if n := srv.BindCount(); n != 2 {
+		t.Errorf("expected a bind for uncached credentials, got: %d", n)
+	}
+}
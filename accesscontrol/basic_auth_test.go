@@ -5,7 +5,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	ac "github.com/avenga/couper/accesscontrol"
 	couperErr "github.com/avenga/couper/errors"
@@ -40,6 +43,7 @@ func Test_NewBasicAuth(t *testing.T) {
 		{"name", "user", "pass", "testdata/htpasswd_err_malformed", `parse error: malformed password for user: foo`, true},
 		{"name", "user", "pass", "testdata/htpasswd_err_multi", `multiple user: foo`, true},
 		{"name", "user", "pass", "testdata/htpasswd_err_unsupported", "parse error: algorithm not supported", true},
+		{"name", "user", "pass", "testdata/htpasswd_err_malformed_sha", `parse error: malformed password for user: foo`, true},
 	} {
 		ba, err = ac.NewBasicAuth(tc.name, tc.user, tc.pass, tc.file)
 		if tc.shouldFail && ba != nil {
@@ -78,6 +82,12 @@ func Test_BasicAuth_Validate(t *testing.T) {
 		{"Basic " + b64.StdEncoding.EncodeToString([]byte("user:bass")), couperErr.BasicAuth},
 		{"Basic " + b64.StdEncoding.EncodeToString([]byte("john:my-pass")), nil},
 		{"Basic " + b64.StdEncoding.EncodeToString([]byte("john:my-bass")), couperErr.BasicAuth},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("sha1:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("sha256:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("sha512:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("sha512:my-bass")), couperErr.BasicAuth},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("argon2id:my-pass")), nil},
+		{"Basic " + b64.StdEncoding.EncodeToString([]byte("argon2id:my-bass")), couperErr.BasicAuth},
 	} {
 		t.Run(testcase.headerValue, func(t *testing.T) {
 			req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -152,3 +162,54 @@ func Test_BasicAuth_ValidateCases(t *testing.T) {
 		})
 	}
 }
+
+func Test_BasicAuth_ReloadFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "htpasswd")
+	writeFile := func(content string, modTime time.Time) {
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	writeFile("john:$apr1$9zGWAElT$VQXJ4anNzh6qGRCfHdrYF0\n", now.Add(-time.Minute))
+
+	ba, err := ac.NewBasicAuth("name", "", "", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(user string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(user, "my-pass")
+		return ba.Validate(req)
+	}
+
+	if err = validate("john"); err != nil {
+		t.Errorf("expected valid credentials, got: %v", err)
+	}
+	if err = validate("sha1"); err == nil {
+		t.Error("expected invalid credentials")
+	}
+
+	writeFile("sha1:{SHA}FcrlzJC8GZi2VJ8fE+4LjKSPmvc=\n", now)
+	time.Sleep(time.Second + time.Second/10)
+
+	if err = validate("sha1"); err != nil {
+		t.Errorf("expected valid credentials after reload, got: %v", err)
+	}
+	if err = validate("john"); err == nil {
+		t.Error("expected invalid credentials after reload")
+	}
+
+	// a malformed file keeps the previous data
+	writeFile("sha1\n", now.Add(time.Minute))
+	time.Sleep(time.Second + time.Second/10)
+
+	if err = validate("sha1"); err != nil {
+		t.Errorf("expected valid credentials after failed reload, got: %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package accesscontrol
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/errors"
+)
+
+const (
+	dpopHeader = "DPoP"
+	dpopType   = "dpop+jwt"
+	// dpopLeeway is the accepted clock skew for proofs issued in the future.
+	dpopLeeway = 5 * time.Second
+)
+
+// dpopAlgorithms are the accepted asymmetric proof signature algorithms.
+var dpopAlgorithms = []string{
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+}
+
+// DPoP validates the proof of possession (RFC 9449) sent with sender-constrained access tokens.
+type DPoP struct {
+	maxAge   time.Duration
+	memStore *cache.MemoryStore
+	mu       sync.Mutex
+	name     string
+}
+
+type dpopJWK struct {
+	key        interface{}
+	thumbprint string
+}
+
+// NewDPoP creates a new DPoP object. The memory store is used to detect replayed proofs.
+func NewDPoP(name, maxAge string, memStore *cache.MemoryStore) (*DPoP, error) {
+	if maxAge == "" {
+		maxAge = "60s"
+	}
+
+	age, err := time.ParseDuration(maxAge)
+	if err != nil {
+		return nil, err
+	}
+	if age <= 0 {
+		return nil, fmt.Errorf("dpop_max_age must be positive")
+	}
+
+	if memStore == nil {
+		return nil, fmt.Errorf("dpop requires a memory store")
+	}
+
+	return &DPoP{
+		maxAge:   age,
+		memStore: memStore,
+		name:     name,
+	}, nil
+}
+
+// Validate validates the DPoP proof of the given request and its binding to the access token.
+func (d *DPoP) Validate(req *http.Request, accessToken string, tokenClaims map[string]interface{}) error {
+	proofs := req.Header.Values(dpopHeader)
+	if len(proofs) == 0 {
+		return errors.JwtDpopInvalid.Message("missing DPoP proof")
+	}
+	if len(proofs) > 1 {
+		return errors.JwtDpopInvalid.Message("multiple DPoP proofs")
+	}
+
+	var (
+		jwk    *dpopJWK
+		keyErr error
+	)
+	parser := jwt.NewParser(jwt.WithValidMethods(dpopAlgorithms), jwt.WithoutAudienceValidation())
+	proof, err := parser.Parse(proofs[0], func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != dpopType {
+			keyErr = fmt.Errorf("invalid typ: %q", typ)
+			return nil, keyErr
+		}
+
+		if jwk, keyErr = parseDPoPJWK(token.Header["jwk"]); keyErr != nil {
+			return nil, keyErr
+		}
+		return jwk.key, nil
+	})
+	if err != nil {
+		if keyErr != nil {
+			err = keyErr
+		}
+		return errors.JwtDpopInvalid.With(err)
+	}
+
+	claims, ok := proof.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.JwtDpopInvalid.Message("proof has no claims")
+	}
+
+	jti, _ := claims["jti"].(string)
+	if jti == "" {
+		return errors.JwtDpopInvalid.Message("missing jti claim")
+	}
+
+	if htm, _ := claims["htm"].(string); htm != req.Method {
+		return errors.JwtDpopInvalid.Messagef("htm mismatch: %q", htm)
+	}
+
+	htu, _ := claims["htu"].(string)
+	if !matchesHTU(htu, req.URL) {
+		return errors.JwtDpopInvalid.Messagef("htu mismatch: %q", htu)
+	}
+
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return errors.JwtDpopInvalid.Message("missing iat claim")
+	}
+	issuedAt := time.Unix(int64(iat), 0)
+	now := time.Now()
+	if issuedAt.Before(now.Add(-d.maxAge)) || issuedAt.After(now.Add(dpopLeeway)) {
+		return errors.JwtDpopInvalid.Messagef("iat not within the accepted window: %d", int64(iat))
+	}
+
+	accessTokenHash := sha256.Sum256([]byte(accessToken))
+	if ath, _ := claims["ath"].(string); ath != base64.RawURLEncoding.EncodeToString(accessTokenHash[:]) {
+		return errors.JwtDpopInvalid.Message("ath mismatch")
+	}
+
+	cnf, _ := tokenClaims["cnf"].(map[string]interface{})
+	if jkt, _ := cnf["jkt"].(string); jkt == "" || jkt != jwk.thumbprint {
+		return errors.JwtDpopInvalid.Message("access token not bound to proof key")
+	}
+
+	// a proof is valid until its iat leaves the accepted window
+	key := "dpop|" + d.name + "|" + jti
+	ttl := int64((d.maxAge + dpopLeeway).Seconds()) + 1
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.memStore.Get(key) != nil {
+		return errors.JwtDpopInvalid.Messagef("replayed proof: jti %q", jti)
+	}
+	d.memStore.Set(key, true, ttl)
+
+	return nil
+}
+
+// matchesHTU compares the htu claim with the request URL without query and fragment parts.
+func matchesHTU(htu string, reqURL *url.URL) bool {
+	u, err := url.Parse(htu)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	return normalizeHTU(u) == normalizeHTU(reqURL)
+}
+
+func normalizeHTU(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if scheme == "http" {
+		host = strings.TrimSuffix(host, ":80")
+	} else if scheme == "https" {
+		host = strings.TrimSuffix(host, ":443")
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// parseDPoPJWK parses the public key of the jwk header and calculates its
+// JWK SHA-256 thumbprint (RFC 7638).
+func parseDPoPJWK(header interface{}) (*dpopJWK, error) {
+	raw, ok := header.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("missing jwk header")
+	}
+
+	member := func(name string) string {
+		s, _ := raw[name].(string)
+		return s
+	}
+
+	if _, exists := raw["d"]; exists {
+		return nil, fmt.Errorf("jwk must not contain a private key")
+	}
+
+	var (
+		key       interface{}
+		canonical interface{}
+	)
+
+	switch kty := member("kty"); kty {
+	case "EC":
+		var curve elliptic.Curve
+		switch crv := member("crv"); crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported jwk crv: %q", crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(member("x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(member("y"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk y: %w", err)
+		}
+
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("invalid jwk: point not on curve")
+		}
+		key = pub
+
+		canonical = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{member("crv"), kty, member("x"), member("y")}
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(member("n"))
+		if err != nil || len(n) == 0 {
+			return nil, fmt.Errorf("invalid jwk n")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(member("e"))
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid jwk e")
+		}
+
+		key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+		canonical = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{member("e"), kty, member("n")}
+	default:
+		return nil, fmt.Errorf("unsupported jwk kty: %q", kty)
+	}
+
+	b, err := json.Marshal(canonical)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+
+	return &dpopJWK{
+		key:        key,
+		thumbprint: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}, nil
+}
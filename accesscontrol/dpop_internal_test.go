@@ -0,0 +1,32 @@
+package accesscontrol
+
+import "testing"
+
+func Test_DPoPJWKThumbprint(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc7638#section-3.1
+	jwk, err := parseDPoPJWK(map[string]interface{}{
+		"kty": "RSA",
+		"n":   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		"e":   "AQAB",
+		"alg": "RS256",
+		"kid": "2011-04-29",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"; jwk.thumbprint != exp {
+		t.Errorf("expected thumbprint %q, got: %q", exp, jwk.thumbprint)
+	}
+
+	for _, invalid := range []map[string]interface{}{
+		{"kty": "oct", "k": "c2VjcmV0"},
+		{"kty": "EC", "crv": "P-256", "x": "AA", "y": "AA"},
+		{"kty": "EC", "crv": "secp256k1", "x": "AA", "y": "AA"},
+		{"kty": "RSA", "n": "AQAB", "e": "AQAB", "d": "AQAB"},
+	} {
+		if _, err = parseDPoPJWK(invalid); err == nil {
+			t.Errorf("expected error for jwk: %v", invalid)
+		}
+	}
+}
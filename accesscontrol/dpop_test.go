@@ -0,0 +1,198 @@
+package accesscontrol_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/internal/test"
+)
+
+func Test_JWT_DPoP(t *testing.T) {
+	helper := test.New(t)
+
+	dpop, err := ac.NewDPoP("test_ac", "1m", cache.New(nil, nil))
+	helper.Must(err)
+
+	key := []byte("mySecretK3y")
+	j, err := ac.NewJWT(&ac.JWTOptions{
+		Algorithm: "HS256",
+		DPoP:      dpop,
+		Name:      "test_ac",
+		Source:    ac.NewJWTSource("", "Authorization"),
+		Key:       key,
+	})
+	helper.Must(err)
+
+	proofKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	helper.Must(err)
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	helper.Must(err)
+
+	newJWK := func(k *ecdsa.PrivateKey) map[string]interface{} {
+		return map[string]interface{}{
+			"kty": "EC",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, 32))),
+		}
+	}
+
+	thumbprint := func(k *ecdsa.PrivateKey) string {
+		jwk := newJWK(k)
+		b, _ := json.Marshal(map[string]string{ // keys are sorted
+			"crv": jwk["crv"].(string), "kty": jwk["kty"].(string), "x": jwk["x"].(string), "y": jwk["y"].(string),
+		})
+		sum := sha256.Sum256(b)
+		return base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "alice",
+		"cnf": map[string]string{"jkt": thumbprint(proofKey)},
+	}).SignedString(key)
+	helper.Must(err)
+
+	unboundToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "alice"}).SignedString(key)
+	helper.Must(err)
+
+	ath := func(token string) string {
+		sum := sha256.Sum256([]byte(token))
+		return base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	var jtiCounter int
+	newProof := func(modify func(header map[string]interface{}, claims jwt.MapClaims)) string {
+		jtiCounter++
+		claims := jwt.MapClaims{
+			"jti": "proof-" + string(rune('a'+jtiCounter)),
+			"htm": http.MethodPost,
+			"htu": "https://api.example.com/resource",
+			"iat": time.Now().Unix(),
+			"ath": ath(accessToken),
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["typ"] = "dpop+jwt"
+		token.Header["jwk"] = newJWK(proofKey)
+		signingKey := proofKey
+		if modify != nil {
+			modify(token.Header, claims)
+			if _, ok := token.Header["other"]; ok {
+				delete(token.Header, "other")
+				signingKey = otherKey
+			}
+		}
+		proof, perr := token.SignedString(signingKey)
+		helper.Must(perr)
+		return proof
+	}
+
+	newRequest := func(scheme, token string, proofs ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "https://api.example.com/resource?q=1", nil)
+		req.Header.Set("Authorization", scheme+" "+token)
+		for _, p := range proofs {
+			req.Header.Add("DPoP", p)
+		}
+		return req
+	}
+
+	replayed := newProof(nil)
+	helper.Must(j.Validate(newRequest("DPoP", accessToken, replayed)))
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		expErr string
+	}{
+		{"valid", newRequest("DPoP", accessToken, newProof(nil)), ""},
+		{"default port", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["htu"] = "https://API.example.com:443/resource"
+		})), ""},
+		{"bearer scheme", newRequest("Bearer", accessToken, newProof(nil)), "DPoP scheme required with authorization header"},
+		{"missing proof", newRequest("DPoP", accessToken), "missing DPoP proof"},
+		{"multiple proofs", newRequest("DPoP", accessToken, newProof(nil), newProof(nil)), "multiple DPoP proofs"},
+		{"replayed proof", newRequest("DPoP", accessToken, replayed), `replayed proof: jti`},
+		{"wrong typ", newRequest("DPoP", accessToken, newProof(func(h map[string]interface{}, _ jwt.MapClaims) {
+			h["typ"] = "JWT"
+		})), `invalid typ: "JWT"`},
+		{"missing jwk", newRequest("DPoP", accessToken, newProof(func(h map[string]interface{}, _ jwt.MapClaims) {
+			delete(h, "jwk")
+		})), "missing jwk header"},
+		{"invalid signature", newRequest("DPoP", accessToken, newProof(func(h map[string]interface{}, _ jwt.MapClaims) {
+			h["other"] = true
+		})), "token signature is invalid"},
+		{"missing jti", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			delete(c, "jti")
+		})), "missing jti claim"},
+		{"htm mismatch", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["htm"] = http.MethodGet
+		})), `htm mismatch: "GET"`},
+		{"htu mismatch", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["htu"] = "https://api.example.com/other"
+		})), `htu mismatch: "https://api.example.com/other"`},
+		{"iat too old", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["iat"] = time.Now().Add(-2 * time.Minute).Unix()
+		})), "iat not within the accepted window"},
+		{"iat in the future", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["iat"] = time.Now().Add(time.Minute).Unix()
+		})), "iat not within the accepted window"},
+		{"ath mismatch", newRequest("DPoP", accessToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["ath"] = ath(unboundToken)
+		})), "ath mismatch"},
+		{"unbound access token", newRequest("DPoP", unboundToken, newProof(func(_ map[string]interface{}, c jwt.MapClaims) {
+			c["ath"] = ath(unboundToken)
+		})), "access token not bound to proof key"},
+		{"other proof key", newRequest("DPoP", accessToken, newProof(func(h map[string]interface{}, _ jwt.MapClaims) {
+			h["jwk"] = newJWK(otherKey)
+			h["other"] = true
+		})), "access token not bound to proof key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			err := j.Validate(tt.req)
+			if tt.expErr == "" {
+				if err != nil {
+					subT.Fatalf("expected no error, got: %v", err.(errors.GoError).LogError())
+				}
+				return
+			}
+
+			if err == nil {
+				subT.Fatalf("expected error %q", tt.expErr)
+			}
+			gerr, ok := err.(*errors.Error)
+			if !ok || gerr.Kinds()[0] != "jwt_dpop_invalid" {
+				subT.Fatalf("expected jwt_dpop_invalid error, got: %v", err)
+			}
+			if msg := gerr.LogError(); !strings.Contains(msg, tt.expErr) {
+				subT.Errorf("expected error message %q, got: %q", tt.expErr, msg)
+			}
+		})
+	}
+}
+
+func Test_NewDPoP(t *testing.T) {
+	if _, err := ac.NewDPoP("test_ac", "", nil); err == nil {
+		t.Error("expected error without memory store")
+	}
+	if _, err := ac.NewDPoP("test_ac", "-1s", cache.New(nil, nil)); err == nil {
+		t.Error("expected error for negative max age")
+	}
+	if _, err := ac.NewDPoP("test_ac", "1x", cache.New(nil, nil)); err == nil {
+		t.Error("expected error for invalid max age")
+	}
+}
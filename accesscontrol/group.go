@@ -0,0 +1,90 @@
+package accesscontrol
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/errors"
+)
+
+var (
+	_ AccessControl = &AllOf{}
+	_ AccessControl = &AnyOf{}
+)
+
+// AllOf represents a group of access controls which must all grant access.
+type AllOf struct {
+	list List
+}
+
+// AnyOf represents a group of access controls where one granting alternative is sufficient.
+type AnyOf struct {
+	list List
+	name string
+}
+
+// NewAllOf creates a new access control group which requires every given control to pass.
+func NewAllOf(list List) (*AllOf, error) {
+	if len(list) == 0 {
+		return nil, fmt.Errorf("all_of requires at least one access control")
+	}
+	return &AllOf{list: list}, nil
+}
+
+// NewAnyOf creates a new access control group which requires at least one given control to pass.
+func NewAnyOf(name string, list List) (*AnyOf, error) {
+	if len(list) == 0 {
+		return nil, fmt.Errorf("any_of requires at least one access control")
+	}
+	return &AnyOf{list: list, name: name}, nil
+}
+
+// Validate implements the AccessControl interface.
+func (a *AllOf) Validate(req *http.Request) error {
+	for _, item := range a.list {
+		if err := item.Validate(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate implements the AccessControl interface. The alternatives are
+// validated in the configured order, the first granting one wins.
+func (a *AnyOf) Validate(req *http.Request) error {
+	var failures []string
+	for _, item := range a.list {
+		if err := item.Validate(req); err != nil {
+			msg := err.Error()
+			if gerr, ok := err.(errors.GoError); ok { // omit the repeating synopsis
+				msg = strings.TrimPrefix(gerr.LogError(), msg+": ")
+			}
+			failures = append(failures, msg)
+			continue
+		}
+
+		setMatched(req, a.name, item.label)
+		return nil
+	}
+
+	return errors.AnyOf.Messagef("no alternative granted access: [%s]", strings.Join(failures, ", "))
+}
+
+// setMatched stores the label of the granting alternative
+// as request.context.<name>.matched value.
+func setMatched(req *http.Request, name, label string) {
+	ctx := req.Context()
+	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
+	if !ok {
+		acMap = make(map[string]interface{})
+	}
+
+	acMap[name] = map[string]interface{}{
+		"matched": label,
+	}
+	ctx = context.WithValue(ctx, request.AccessControls, acMap)
+	*req = *req.WithContext(ctx)
+}
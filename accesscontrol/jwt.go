@@ -40,6 +40,7 @@ type JWT struct {
 	algorithms     []acjwt.Algorithm
 	claims         hcl.Expression
 	claimsRequired []string
+	dpop           *DPoP
 	source         JWTSource
 	hmacSecret     []byte
 	name           string
@@ -48,12 +49,14 @@ type JWT struct {
 	roleMap        map[string][]string
 	scopeClaim     string
 	jwks           *JWKS
+	revocation     *JWTRevocation
 }
 
 type JWTOptions struct {
 	Algorithm      string
 	Claims         hcl.Expression
 	ClaimsRequired []string
+	DPoP           *DPoP
 	Name           string // TODO: more generic (validate)
 	RoleClaim      string
 	RoleMap        map[string][]string
@@ -61,6 +64,7 @@ type JWTOptions struct {
 	Source         JWTSource
 	Key            []byte
 	JWKS           *JWKS
+	Revocation     *JWTRevocation
 }
 
 func NewJWTSource(cookie, header string) JWTSource {
@@ -139,8 +143,10 @@ func newJWT(options *JWTOptions) (*JWT, error) {
 	jwtAC := &JWT{
 		claims:         options.Claims,
 		claimsRequired: options.ClaimsRequired,
+		dpop:           options.DPoP,
 		name:           options.Name,
 		roleClaim:      options.RoleClaim,
+		revocation:     options.Revocation,
 		roleMap:        options.RoleMap,
 		scopeClaim:     options.ScopeClaim,
 		source:         options.Source,
@@ -162,7 +168,12 @@ func (j *JWT) Validate(req *http.Request) error {
 	case Header:
 		if strings.ToLower(j.source.Name) == "authorization" {
 			if tokenValue = req.Header.Get(j.source.Name); tokenValue != "" {
-				if tokenValue, err = getBearer(tokenValue); err != nil {
+				if j.dpop != nil {
+					tokenValue, err = getDPoPToken(tokenValue)
+				} else {
+					tokenValue, err = getBearer(tokenValue)
+				}
+				if err != nil {
 					return err
 				}
 			}
@@ -207,6 +218,18 @@ func (j *JWT) Validate(req *http.Request) error {
 		return err
 	}
 
+	if j.revocation != nil {
+		if err = j.revocation.Validate(tokenClaims); err != nil {
+			return err
+		}
+	}
+
+	if j.dpop != nil {
+		if err = j.dpop.Validate(req, tokenValue, tokenClaims); err != nil {
+			return err
+		}
+	}
+
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
@@ -389,6 +412,14 @@ func getBearer(val string) (string, error) {
 	return "", errors.JwtTokenExpired.Message("bearer required with authorization header")
 }
 
+func getDPoPToken(val string) (string, error) {
+	const dpop = "dpop "
+	if strings.HasPrefix(strings.ToLower(val), dpop) {
+		return strings.Trim(val[len(dpop):], " "), nil
+	}
+	return "", errors.JwtDpopInvalid.Message("DPoP scheme required with authorization header")
+}
+
 func newParser(algos []acjwt.Algorithm, claims map[string]interface{}) (*jwt.Parser, error) {
 	var algorithms []string
 	for _, a := range algos {
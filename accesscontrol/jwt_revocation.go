@@ -0,0 +1,227 @@
+package accesscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/avenga/couper/config/reader"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/errors"
+)
+
+// JWTRevocation represents a denylist of revoked tokens which is loaded
+// from a file or from an URL and reloaded in the background after the configured TTL.
+//
+// Format:
+//
+//	{
+//	  "jti": ["<revoked token id>", ...],
+//	  "sub": {"<subject>": <unix timestamp, tokens issued before are revoked>, ...}
+//	}
+type JWTRevocation struct {
+	context   context.Context
+	file      string
+	list      *revocationList
+	loadErr   error
+	loadMu    sync.Mutex
+	modTime   time.Time
+	mu        sync.RWMutex
+	ready     chan struct{}
+	uri       string
+	transport http.RoundTripper
+	ttl       time.Duration
+}
+
+type revocationList struct {
+	JTI []string         `json:"jti"`
+	Sub map[string]int64 `json:"sub"`
+
+	jti map[string]struct{}
+}
+
+// NewJWTRevocation creates a new JWTRevocation object and starts loading the list
+// until the given context is done. The given uri must either have the "file:" or
+// the "http(s):" scheme.
+func NewJWTRevocation(uri string, ttl string, transport http.RoundTripper, confContext context.Context) (*JWTRevocation, error) {
+	if ttl == "" {
+		ttl = "10s"
+	}
+
+	timetolive, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, err
+	}
+	if timetolive <= 0 {
+		return nil, fmt.Errorf("revocation_ttl must be positive: %q", ttl)
+	}
+
+	var file string
+	if strings.HasPrefix(uri, "file:") {
+		file = uri[5:]
+	} else if !strings.HasPrefix(uri, "http:") && !strings.HasPrefix(uri, "https:") {
+		return nil, fmt.Errorf("unsupported revocation URI scheme: %q", uri)
+	}
+
+	if confContext == nil {
+		confContext = context.Background()
+	}
+
+	r := &JWTRevocation{
+		context:   confContext,
+		file:      file,
+		ready:     make(chan struct{}),
+		uri:       uri,
+		transport: transport,
+		ttl:       timetolive,
+	}
+
+	go r.refresh()
+
+	return r, nil
+}
+
+// Validate checks the given token claims against the denylist.
+func (r *JWTRevocation) Validate(tokenClaims map[string]interface{}) error {
+	list, err := r.getList()
+	if err != nil {
+		return err
+	}
+
+	if jti, ok := tokenClaims["jti"].(string); ok {
+		if _, revoked := list.jti[jti]; revoked {
+			return errors.JwtTokenRevoked.Messagef("token id %q is revoked", jti)
+		}
+	}
+
+	if sub, ok := tokenClaims["sub"].(string); ok {
+		if before, exist := list.Sub[sub]; exist {
+			iat, ok := tokenClaims["iat"].(float64)
+			if !ok || int64(iat) < before {
+				return errors.JwtTokenRevoked.Messagef("tokens for subject %q issued before %d are revoked", sub, before)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getList returns the last successfully loaded denylist. It waits for the initial load only.
+func (r *JWTRevocation) getList() (*revocationList, error) {
+	<-r.ready
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.list == nil {
+		return nil, fmt.Errorf("error loading revocation list: %v", r.loadErr)
+	}
+	return r.list, nil
+}
+
+// refresh loads the denylist initially and after each TTL until the context is done.
+func (r *JWTRevocation) refresh() {
+	ticker := time.NewTicker(r.ttl)
+	defer ticker.Stop()
+
+	_ = r.Load()
+	close(r.ready)
+
+	for {
+		select {
+		case <-r.context.Done():
+			return
+		case <-ticker.C:
+			_ = r.Load() // a failed reload keeps the previously loaded list
+		}
+	}
+}
+
+// Load reads the denylist from the configured source and replaces the current one on success.
+// A file is read again only if it has been modified.
+func (r *JWTRevocation) Load() error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	list, err := r.read()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loadErr = err
+	if list != nil {
+		r.list = list
+	}
+	return err
+}
+
+// read returns the parsed denylist or nil if an already loaded file has not been modified.
+func (r *JWTRevocation) read() (*revocationList, error) {
+	var rawJSON []byte
+
+	if r.file != "" {
+		absPath, err := filepath.Abs(r.file)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return nil, err
+		}
+
+		if !r.modTime.IsZero() && info.ModTime().Equal(r.modTime) {
+			return nil, nil
+		}
+
+		b, err := reader.ReadFromFile("revocation_url file", r.file)
+		if err != nil {
+			return nil, err
+		}
+		rawJSON = b
+		r.modTime = info.ModTime()
+	} else if r.transport != nil {
+		req, err := http.NewRequest(http.MethodGet, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		ctx := context.WithValue(r.context, request.URLAttribute, r.uri)
+		ctx = context.WithValue(ctx, request.RoundTripName, "revocation")
+		req = req.WithContext(ctx)
+		response, err := r.transport.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("status code %d", response.StatusCode)
+		}
+
+		body, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading revocation list response for %q: %v", r.uri, err)
+		}
+		rawJSON = body
+	} else {
+		return nil, fmt.Errorf("revocation list: missing both file and request")
+	}
+
+	list := &revocationList{}
+	if err := json.Unmarshal(rawJSON, list); err != nil {
+		return nil, err
+	}
+
+	list.jti = make(map[string]struct{}, len(list.JTI))
+	for _, jti := range list.JTI {
+		list.jti[jti] = struct{}{}
+	}
+
+	return list, nil
+}
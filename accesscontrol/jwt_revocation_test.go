@@ -0,0 +1,145 @@
+package accesscontrol_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/internal/test"
+)
+
+func Test_JWT_Revocation(t *testing.T) {
+	helper := test.New(t)
+
+	dir, err := ioutil.TempDir("", "revocation")
+	helper.Must(err)
+	defer os.RemoveAll(dir)
+
+	listFile := filepath.Join(dir, "revoked.json")
+	helper.Must(ioutil.WriteFile(listFile, []byte(`{"jti": ["abc"], "sub": {"bob": 1500000000}}`), 0644))
+
+	revocation, err := ac.NewJWTRevocation("file:"+listFile, "1h", nil, nil)
+	helper.Must(err)
+
+	key := []byte("mySecretK3y")
+	j, err := ac.NewJWT(&ac.JWTOptions{
+		Algorithm:  "HS256",
+		Name:       "test_ac",
+		Revocation: revocation,
+		Source:     ac.NewJWTSource("", "Authorization"),
+		Key:        key,
+	})
+	helper.Must(err)
+
+	newRequest := func(claims jwt.MapClaims) *http.Request {
+		token, terr := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		helper.Must(terr)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		wantErr bool
+	}{
+		{"revoked jti", jwt.MapClaims{"jti": "abc", "sub": "alice"}, true},
+		{"valid jti", jwt.MapClaims{"jti": "def", "sub": "alice"}, false},
+		{"without jti", jwt.MapClaims{"sub": "alice"}, false},
+		{"revoked sub, issued before", jwt.MapClaims{"sub": "bob", "iat": 1400000000}, true},
+		{"revoked sub, without iat", jwt.MapClaims{"sub": "bob"}, true},
+		{"revoked sub, issued after", jwt.MapClaims{"sub": "bob", "iat": 1600000000}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			err := j.Validate(newRequest(tt.claims))
+			if (err != nil) != tt.wantErr {
+				subT.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if gerr, ok := err.(*errors.Error); !ok || gerr.Kinds()[0] != "jwt_token_revoked" {
+					subT.Errorf("expected jwt_token_revoked error, got: %v", err)
+				}
+			}
+		})
+	}
+
+	// a modified file gets reloaded
+	helper.Must(ioutil.WriteFile(listFile, []byte(`{"jti": ["def"]}`), 0644))
+	modTime := time.Now().Add(time.Second)
+	helper.Must(os.Chtimes(listFile, modTime, modTime))
+	helper.Must(revocation.Load())
+
+	if err = j.Validate(newRequest(jwt.MapClaims{"jti": "abc"})); err != nil {
+		t.Errorf("expected no longer revoked token, got: %v", err)
+	}
+	if err = j.Validate(newRequest(jwt.MapClaims{"jti": "def"})); err == nil {
+		t.Error("expected revoked token")
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func Test_JWT_Revocation_BackgroundReload(t *testing.T) {
+	helper := test.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	hang := make(chan struct{})
+	defer close(hang)
+
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			select { // a slow revocation backend
+			case <-hang:
+			case <-req.Context().Done():
+			}
+			return nil, context.Canceled
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"jti": ["abc"]}`)),
+		}, nil
+	})
+
+	revocation, err := ac.NewJWTRevocation("https://revocation.example.com/list", "50ms", transport, ctx)
+	helper.Must(err)
+
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		err = revocation.Validate(map[string]interface{}{"jti": "abc"})
+		if err == nil {
+			t.Fatal("expected revoked token")
+		}
+		if d := time.Since(start); d > 20*time.Millisecond {
+			t.Fatalf("expected the last loaded list without waiting for the reload, took: %s", d)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if atomic.LoadInt32(&calls) < 2 {
+		t.Error("expected a background reload")
+	}
+}
@@ -681,7 +681,7 @@ func TestJwtConfig(t *testing.T) {
 			  backend "foo" {}
 			}
 			`,
-			"backend not needed without jwks_url",
+			"backend not needed without jwks_url or revocation_url",
 		},
 		{
 			"ok: jwks_url + backend reference",
@@ -711,7 +711,7 @@ func TestJwtConfig(t *testing.T) {
 				  }
 				}
 				`,
-				"backend not needed without jwks_url",
+				"backend not needed without jwks_url or revocation_url",
 			},
 		*/
 	}
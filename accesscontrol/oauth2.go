@@ -14,17 +14,50 @@ var _ AccessControl = &OAuth2Callback{}
 // OAuth2Callback represents the access control for the OAuth2 authorization code flow callback.
 type OAuth2Callback struct {
 	oauth2Client oauth2.AcClient
+	session      *Session
 }
 
 // NewOAuth2Callback creates a new access control for the OAuth2 authorization code flow callback.
-func NewOAuth2Callback(oauth2Client oauth2.AcClient) (*OAuth2Callback, error) {
+// With a given session the token response data is kept for the following requests.
+func NewOAuth2Callback(oauth2Client oauth2.AcClient, session *Session) (*OAuth2Callback, error) {
 	return &OAuth2Callback{
 		oauth2Client: oauth2Client,
+		session:      session,
 	}, nil
 }
 
 // Validate implements the AccessControl interface
 func (oa *OAuth2Callback) Validate(req *http.Request) error {
+	if oa.session != nil {
+		if oa.session.IsBackChannelLogout(req) {
+			claims, err := oa.session.BackChannelLogout(req)
+			if err != nil {
+				return errors.Oauth2.Message("back-channel logout error").With(err)
+			}
+			oa.setContext(req, claims)
+			return nil
+		}
+
+		if oa.session.IsLogout(req) {
+			// a cross-site link or image must not end the session
+			if req.Method != http.MethodPost {
+				return errors.Oauth2.Messagef("wrong logout method (%s)", req.Method)
+			}
+
+			logoutURL, err := oa.session.End(req)
+			if err != nil {
+				return errors.Oauth2.Message("session logout error").With(err)
+			}
+			oa.setContext(req, map[string]interface{}{SessionLogoutURL: logoutURL})
+			return nil
+		}
+
+		if sessionData := oa.loadSession(req); sessionData != nil {
+			oa.setContext(req, sessionData)
+			return nil
+		}
+	}
+
 	if req.Method != http.MethodGet {
 		return errors.Oauth2.Messagef("wrong method (%s)", req.Method)
 	}
@@ -34,14 +67,41 @@ func (oa *OAuth2Callback) Validate(req *http.Request) error {
 		return err
 	}
 
+	if oa.session != nil {
+		if err = oa.session.Start(req, tokenResponseData); err != nil {
+			return errors.Oauth2.Message("session error").With(err)
+		}
+	}
+
+	oa.setContext(req, tokenResponseData)
+	return nil
+}
+
+// loadSession returns the session data and refreshes an expired access token if possible.
+func (oa *OAuth2Callback) loadSession(req *http.Request) map[string]interface{} {
+	sessionData := oa.session.Load(req)
+	if sessionData == nil || !oa.session.AccessTokenExpired(sessionData) {
+		return sessionData
+	}
+
+	refreshed, err := oa.oauth2Client.RefreshTokenResponse(req.Context(), sessionData)
+	if err != nil {
+		return nil
+	}
+
+	if err = oa.session.Update(req, sessionData, refreshed); err != nil {
+		return nil
+	}
+	return sessionData
+}
+
+func (oa *OAuth2Callback) setContext(req *http.Request, data map[string]interface{}) {
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
 		acMap = make(map[string]interface{})
 	}
-	acMap[oa.oauth2Client.GetName()] = tokenResponseData
+	acMap[oa.oauth2Client.GetName()] = data
 	ctx = context.WithValue(ctx, request.AccessControls, acMap)
 	*req = *req.WithContext(ctx)
-
-	return nil
 }
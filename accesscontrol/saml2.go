@@ -2,11 +2,13 @@ package accesscontrol
 
 import (
 	"context"
+	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
 	"net/http"
+	"net/url"
 	"sort"
 
 	saml2 "github.com/russellhaering/gosaml2"
@@ -21,7 +23,10 @@ import (
 
 type Saml2 struct {
 	arrayAttributes []string
+	idpSloURL       string
 	name            string
+	signResponses   bool
+	sloURL          string
 	sp              *saml2.SAMLServiceProvider
 }
 
@@ -68,9 +73,27 @@ func NewSAML2ACS(metadata []byte, name string, acsUrl string, spEntityId string,
 		name:            name,
 		sp:              sp,
 	}
+
+	for _, sloService := range metadataEntity.IDPSSODescriptor.SingleLogoutServices {
+		if sloService.Binding == saml2.BindingHttpPost {
+			samlObj.idpSloURL = sloService.Location
+		}
+	}
+
 	return samlObj, nil
 }
 
+// ConfigureServiceProvider sets the SP key pair to decrypt encrypted assertions and to sign
+// logout responses, and the URL of the SP Single Logout endpoint. Both are optional.
+func (s *Saml2) ConfigureServiceProvider(keyPair *tls.Certificate, sloURL string) {
+	if keyPair != nil {
+		s.sp.SPKeyStore = dsig.TLSCertKeyStore(*keyPair)
+		s.sp.SignAuthnRequestsAlgorithm = dsig.RSASHA256SignatureMethod
+		s.signResponses = true
+	}
+	s.sloURL = sloURL
+}
+
 func contains(s []string, searchterm string) bool {
 	i := sort.SearchStrings(s, searchterm)
 	return i < len(s) && s[i] == searchterm
@@ -83,6 +106,16 @@ func (s *Saml2) Validate(req *http.Request) error {
 	}
 
 	origin := eval.NewRawOrigin(req.URL)
+	if s.sloURL != "" {
+		absSloUrl, serr := lib.AbsoluteURL(s.sloURL, origin)
+		if serr != nil {
+			return serr
+		}
+		if sloURL, perr := url.Parse(absSloUrl); perr == nil && sloURL.Path == req.URL.Path {
+			return s.validateLogout(req, s.logoutServiceProvider(absSloUrl))
+		}
+	}
+
 	absAcsUrl, err := lib.AbsoluteURL(s.sp.AssertionConsumerServiceURL, origin)
 	if err != nil {
 		return err
@@ -103,17 +136,107 @@ func (s *Saml2) Validate(req *http.Request) error {
 	}
 
 	ass := s.GetAssertionData(assertionInfo)
+	s.setContext(req, ass)
 
+	return nil
+}
+
+// logoutServiceProvider returns a copy of the SP for a single logout request with the
+// given absolute SLO URL. The shared SP is not modified since requests run concurrently.
+func (s *Saml2) logoutServiceProvider(sloURL string) *saml2.SAMLServiceProvider {
+	return &saml2.SAMLServiceProvider{
+		AudienceURI:                s.sp.AudienceURI,
+		IDPCertificateStore:        s.sp.IDPCertificateStore,
+		IdentityProviderIssuer:     s.sp.IdentityProviderIssuer,
+		IdentityProviderSLOURL:     s.idpSloURL,
+		SPKeyStore:                 s.sp.SPKeyStore,
+		ServiceProviderSLOURL:      sloURL,
+		SignAuthnRequestsAlgorithm: s.sp.SignAuthnRequestsAlgorithm,
+	}
+}
+
+// validateLogout handles a logout request of the IdP or the logout response
+// to a logout request of the SP sent to the Single Logout endpoint.
+func (s *Saml2) validateLogout(req *http.Request, sp *saml2.SAMLServiceProvider) error {
+	relayState := req.FormValue("RelayState")
+	req.ContentLength = 0
+
+	if encodedRequest := req.FormValue("SAMLRequest"); encodedRequest != "" {
+		logoutRequest, err := sp.ValidateEncodedLogoutRequestPOST(encodedRequest)
+		if err != nil {
+			return errors.Saml2.Message("logout request error").With(err)
+		}
+		if !logoutRequest.SignatureValidated {
+			return errors.Saml2.Message("logout request error: missing signature")
+		}
+
+		logoutResponse, err := s.buildLogoutResponse(sp, logoutRequest.ID, relayState)
+		if err != nil {
+			return errors.Saml2.Message("logout response error").With(err)
+		}
+
+		var nameID string
+		if logoutRequest.NameID != nil {
+			nameID = logoutRequest.NameID.Value
+		}
+
+		s.setContext(req, map[string]interface{}{
+			"logout_response": logoutResponse,
+			"sub":             nameID,
+		})
+		return nil
+	}
+
+	if encodedResponse := req.FormValue("SAMLResponse"); encodedResponse != "" {
+		logoutResponse, err := sp.ValidateEncodedLogoutResponsePOST(encodedResponse)
+		if err != nil {
+			return errors.Saml2.Message("logout response error").With(err)
+		}
+		if !logoutResponse.SignatureValidated {
+			return errors.Saml2.Message("logout response error: missing signature")
+		}
+
+		s.setContext(req, map[string]interface{}{
+			"relay_state": relayState,
+		})
+		return nil
+	}
+
+	return errors.Saml2.Message("missing SAMLRequest or SAMLResponse")
+}
+
+// buildLogoutResponse returns the HTML form posting the logout response to the IdP.
+func (s *Saml2) buildLogoutResponse(sp *saml2.SAMLServiceProvider, requestID, relayState string) (string, error) {
+	if sp.IdentityProviderSLOURL == "" {
+		return "", fmt.Errorf("missing SingleLogoutService with %s binding in metadata", saml2.BindingHttpPost)
+	}
+
+	buildDocument := sp.BuildLogoutResponseDocumentNoSig
+	if s.signResponses {
+		buildDocument = sp.BuildLogoutResponseDocument
+	}
+
+	doc, err := buildDocument(saml2.StatusCodeSuccess, requestID)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := sp.BuildLogoutResponseBodyPostFromDocument(relayState, doc)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func (s *Saml2) setContext(req *http.Request, data map[string]interface{}) {
 	ctx := req.Context()
 	acMap, ok := ctx.Value(request.AccessControls).(map[string]interface{})
 	if !ok {
 		acMap = make(map[string]interface{})
 	}
-	acMap[s.name] = ass
+	acMap[s.name] = data
 	ctx = context.WithValue(ctx, request.AccessControls, acMap)
 	*req = *req.WithContext(ctx)
-
-	return nil
 }
 
 func (s *Saml2) ValidateAssertionInfo(assertionInfo *saml2.AssertionInfo) error {
@@ -143,6 +266,9 @@ func (s *Saml2) GetAssertionData(assertionInfo *saml2.AssertionInfo) map[string]
 	ass := make(map[string]interface{})
 	ass["attributes"] = attributes
 	ass["sub"] = assertionInfo.NameID
+	if assertionInfo.SessionIndex != "" {
+		ass["session_index"] = assertionInfo.SessionIndex
+	}
 	if assertionInfo.SessionNotOnOrAfter != nil {
 		ass["exp"] = assertionInfo.SessionNotOnOrAfter.Unix()
 	}
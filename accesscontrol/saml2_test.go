@@ -2,21 +2,33 @@ package accesscontrol_test
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/beevik/etree"
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
 
 	ac "github.com/avenga/couper/accesscontrol"
 	"github.com/avenga/couper/config/reader"
+	"github.com/avenga/couper/config/request"
 	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/eval/lib"
 	"github.com/avenga/couper/internal/test"
 )
 
@@ -228,3 +240,124 @@ func Test_SAML2ACS_GetAssertionData(t *testing.T) {
 		})
 	}
 }
+
+func Test_SAML2ACS_Logout(t *testing.T) {
+	helper := test.New(t)
+
+	idpKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	helper.Must(err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.org"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	idpCert, err := x509.CreateCertificate(rand.Reader, template, template, &idpKey.PublicKey, idpKey)
+	helper.Must(err)
+
+	idpEntityID := "https://idp.example.org/saml"
+	idpSloURL := "https://idp.example.org/saml/SLOService"
+	spSloURL := "http://www.example.org/saml/slo"
+
+	metadata := []byte(`<?xml version="1.0"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="` + idpEntityID + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing">
+      <ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+        <ds:X509Data>
+          <ds:X509Certificate>` + base64.StdEncoding.EncodeToString(idpCert) + `</ds:X509Certificate>
+        </ds:X509Data>
+      </ds:KeyInfo>
+    </md:KeyDescriptor>
+    <md:SingleLogoutService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST" Location="` + idpSloURL + `"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`)
+
+	sa, err := ac.NewSAML2ACS(metadata, "test", "http://www.example.org/saml/acs", "my-sp-entity-id", nil)
+	helper.Must(err)
+	sa.ConfigureServiceProvider(nil, "/saml/slo")
+
+	// the IdP side creating the logout messages
+	idp := &saml2.SAMLServiceProvider{
+		IdentityProviderSLOURL:     spSloURL,
+		NameIdFormat:               lib.NameIdFormatUnspecified,
+		ServiceProviderIssuer:      idpEntityID,
+		SPKeyStore:                 dsig.TLSCertKeyStore(tls.Certificate{Certificate: [][]byte{idpCert}, PrivateKey: idpKey}),
+		SignAuthnRequestsAlgorithm: dsig.RSASHA256SignatureMethod,
+	}
+
+	encode := func(doc *etree.Document, err error) string {
+		helper.Must(err)
+		b, err := doc.WriteToBytes()
+		helper.Must(err)
+		return url.QueryEscape(base64.StdEncoding.EncodeToString(b))
+	}
+
+	type testCase struct {
+		name    string
+		payload string
+		wantErr string
+		want    map[string]interface{}
+	}
+
+	for _, tc := range []testCase{
+		{"missing message", "RelayState=abc", "missing SAMLRequest or SAMLResponse", nil},
+		{"unsigned logout request", "SAMLRequest=" + encode(idp.BuildLogoutRequestDocumentNoSig("me", "s1")), "logout request error: missing signature", nil},
+		{"logout request", "SAMLRequest=" + encode(idp.BuildLogoutRequestDocument("me", "s1")), "", map[string]interface{}{"sub": "me"}},
+		{"unsigned logout response", "SAMLResponse=" + encode(idp.BuildLogoutResponseDocumentNoSig(saml2.StatusCodeSuccess, "id")), "logout response error: missing signature", nil},
+		{"logout response", "SAMLResponse=" + encode(idp.BuildLogoutResponseDocument(saml2.StatusCodeSuccess, "id")) + "&RelayState=abc", "", map[string]interface{}{"relay_state": "abc"}},
+		{"failed logout response", "SAMLResponse=" + encode(idp.BuildLogoutResponseDocument("urn:oasis:names:tc:SAML:2.0:status:Requester", "id")), "logout response error", nil},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, spSloURL, strings.NewReader(tc.payload))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			err := sa.Validate(req)
+			if tc.wantErr != "" {
+				if err == nil {
+					subT.Fatalf("expected error %q", tc.wantErr)
+				}
+				if msg := err.(errors.GoError).LogError(); !strings.Contains(msg, tc.wantErr) {
+					subT.Errorf("expected error %q, got: %q", tc.wantErr, msg)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatal(err)
+			}
+
+			acMap := req.Context().Value(request.AccessControls).(map[string]interface{})
+			data := acMap["test"].(map[string]interface{})
+			for k, v := range tc.want {
+				if data[k] != v {
+					subT.Errorf("expected %s %v, got: %v", k, v, data[k])
+				}
+			}
+
+			if _, ok := tc.want["sub"]; ok {
+				logoutResponse, _ := data["logout_response"].(string)
+				if !strings.Contains(logoutResponse, `action="`+idpSloURL+`"`) || !strings.Contains(logoutResponse, `name="SAMLResponse"`) {
+					subT.Errorf("unexpected logout response: %s", logoutResponse)
+				}
+			}
+		})
+	}
+	// run with -race: concurrent requests must not share the SLO URLs of the SP
+	t.Run("concurrent logout requests", func(subT *testing.T) {
+		payload := "SAMLRequest=" + encode(idp.BuildLogoutRequestDocument("me", "s1"))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodPost, spSloURL, strings.NewReader(payload))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				if err := sa.Validate(req); err != nil {
+					subT.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,426 @@
+package accesscontrol
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/eval/lib"
+)
+
+// Session store types.
+const (
+	SessionStoreCookie = "cookie"
+	SessionStoreMemory = "memory"
+)
+
+const (
+	// SessionExpiresAt is the key of the access token expiration (unix time) within the session data.
+	SessionExpiresAt = "expires_at"
+	// SessionLogoutURL is the key of the logout URL within the context of a logout request.
+	SessionLogoutURL = "logout_url"
+
+	maxSessionCookieSize = 4000
+)
+
+// SessionOptions represents the options of a <Session> object.
+type SessionOptions struct {
+	BackChannelLogout  *BackChannelLogout
+	ClientID           string
+	Config             *config.Session
+	EndSessionEndpoint func() (string, error)
+	MemStore           *cache.MemoryStore
+	Name               string
+}
+
+// Session keeps the token response data of an authorization code flow in an
+// encrypted cookie or in the memory store keyed by a random cookie value.
+type Session struct {
+	aead                  cipher.AEAD
+	backChannelLogout     *BackChannelLogout
+	backChannelLogoutPath string
+	clientID              string
+	cookieName            string
+	endSessionEndpoint    func() (string, error)
+	logoutPath            string
+	memStore              *cache.MemoryStore
+	name                  string
+	postLogoutRedirectURI string
+	store                 string
+	ttl                   time.Duration
+}
+
+type sessionData struct {
+	Created int64                  `json:"iat"`
+	Data    map[string]interface{} `json:"data"`
+	Expires int64                  `json:"exp"`
+	Sid     string                 `json:"sid,omitempty"`
+	Sub     string                 `json:"sub,omitempty"`
+}
+
+// NewSession creates a new <*Session> object.
+func NewSession(opts *SessionOptions) (*Session, error) {
+	conf := opts.Config
+
+	ttl := conf.TTL
+	if ttl == "" {
+		ttl = "1h"
+	}
+	duration, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("session ttl: %w", err)
+	}
+
+	s := &Session{
+		backChannelLogout:     opts.BackChannelLogout,
+		backChannelLogoutPath: conf.BackChannelLogoutPath,
+		clientID:              opts.ClientID,
+		cookieName:            conf.CookieName,
+		endSessionEndpoint:    opts.EndSessionEndpoint,
+		logoutPath:            conf.LogoutPath,
+		memStore:              opts.MemStore,
+		name:                  opts.Name,
+		postLogoutRedirectURI: conf.PostLogoutRedirectURI,
+		store:                 conf.Store,
+		ttl:                   duration,
+	}
+
+	if s.cookieName == "" {
+		s.cookieName = "couper_session_" + opts.Name
+	}
+
+	if conf.EndSessionEndpoint != "" {
+		s.endSessionEndpoint = func() (string, error) {
+			return conf.EndSessionEndpoint, nil
+		}
+	}
+
+	if s.backChannelLogoutPath != "" && (s.backChannelLogout == nil || s.memStore == nil) {
+		return nil, fmt.Errorf("backchannel_logout_path not supported")
+	}
+
+	switch conf.Store {
+	case "", SessionStoreCookie:
+		s.store = SessionStoreCookie
+		if conf.Secret == "" {
+			return nil, fmt.Errorf("session secret required for the %s store", SessionStoreCookie)
+		}
+
+		key := sha256.Sum256([]byte(conf.Secret))
+		block, cerr := aes.NewCipher(key[:])
+		if cerr != nil {
+			return nil, cerr
+		}
+		if s.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	case SessionStoreMemory:
+		if opts.MemStore == nil {
+			return nil, fmt.Errorf("session memory store not available")
+		}
+	default:
+		return nil, fmt.Errorf("session store %q not supported", conf.Store)
+	}
+
+	return s, nil
+}
+
+// IsLogout returns whether the given request ends the session.
+func (s *Session) IsLogout(req *http.Request) bool {
+	return s.logoutPath != "" && req.URL.Path == s.logoutPath
+}
+
+// IsBackChannelLogout returns whether the given request is a back-channel logout request of the OpenID provider.
+func (s *Session) IsBackChannelLogout(req *http.Request) bool {
+	return s.backChannelLogoutPath != "" && req.URL.Path == s.backChannelLogoutPath
+}
+
+// BackChannelLogout validates the logout token of the given request and invalidates
+// all sessions matching its sid or sub claim. The claims are returned on success.
+func (s *Session) BackChannelLogout(req *http.Request) (map[string]interface{}, error) {
+	claims, err := s.backChannelLogout.Validate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := int64(s.ttl.Seconds())
+	jti := fmt.Sprintf("%v", claims["jti"])
+	if s.memStore.Get(s.logoutKey("jti", jti)) != nil {
+		return nil, fmt.Errorf("logout token %q already used", jti)
+	}
+	s.memStore.Set(s.logoutKey("jti", jti), true, ttl)
+
+	now := time.Now().Unix()
+	for _, name := range []string{"sid", "sub"} {
+		if value, ok := claims[name].(string); ok && value != "" {
+			s.memStore.Set(s.logoutKey(name, value), now, ttl)
+		}
+	}
+
+	return claims, nil
+}
+
+// Load returns the session data of the given request or nil without a valid session.
+func (s *Session) Load(req *http.Request) map[string]interface{} {
+	cookie, err := req.Cookie(s.cookieName)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+
+	sd := s.read(cookie.Value)
+	if sd == nil || sd.Expires <= time.Now().Unix() || s.revoked(sd) {
+		return nil
+	}
+	return sd.Data
+}
+
+// AccessTokenExpired returns whether the access token of the given session data is expired.
+func (s *Session) AccessTokenExpired(data map[string]interface{}) bool {
+	expiresAt, ok := data[SessionExpiresAt].(float64)
+	if !ok {
+		return false
+	}
+	// refresh slightly before the expiration to cover the following backend requests
+	return int64(expiresAt) <= time.Now().Add(time.Second*10).Unix()
+}
+
+// Start creates a new session with the given token response data.
+func (s *Session) Start(req *http.Request, data map[string]interface{}) error {
+	setExpiresAt(data)
+
+	var value string
+	if s.store == SessionStoreMemory {
+		id := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, id); err != nil {
+			return err
+		}
+		value = base64.RawURLEncoding.EncodeToString(id)
+	}
+
+	sd := &sessionData{
+		Created: time.Now().Unix(),
+		Data:    data,
+		Expires: time.Now().Add(s.ttl).Unix(),
+	}
+	if claims, ok := data["id_token_claims"]; ok {
+		sd.Sid, sd.Sub = subjectClaims(claims)
+	}
+
+	return s.write(req, value, sd)
+}
+
+// Update replaces the session data with the refreshed token response data.
+// The expiration of the session itself is kept.
+func (s *Session) Update(req *http.Request, data, refreshed map[string]interface{}) error {
+	cookie, err := req.Cookie(s.cookieName)
+	if err != nil {
+		return err
+	}
+
+	sd := s.read(cookie.Value)
+	if sd == nil {
+		return fmt.Errorf("invalid session")
+	}
+
+	delete(data, "expires_in")
+	delete(data, SessionExpiresAt)
+	for k, v := range refreshed {
+		data[k] = v
+	}
+	setExpiresAt(data)
+	sd.Data = data
+	if claims, ok := refreshed["id_token_claims"]; ok {
+		sd.Sid, sd.Sub = subjectClaims(claims)
+	}
+
+	var value string
+	if s.store == SessionStoreMemory {
+		value = cookie.Value
+	}
+	return s.write(req, value, sd)
+}
+
+// End removes the session and returns the URL to continue the logout with, if any.
+func (s *Session) End(req *http.Request) (string, error) {
+	var data map[string]interface{}
+	if cookie, err := req.Cookie(s.cookieName); err == nil {
+		if sd := s.read(cookie.Value); sd != nil {
+			data = sd.Data
+		}
+		if s.store == SessionStoreMemory {
+			s.memStore.Del(s.storageKey(cookie.Value))
+		}
+	}
+
+	if err := s.addCookie(req, &http.Cookie{Name: s.cookieName, Value: "", MaxAge: -1}); err != nil {
+		return "", err
+	}
+
+	var postLogoutRedirectURI string
+	if s.postLogoutRedirectURI != "" {
+		absoluteURL, err := lib.AbsoluteURL(s.postLogoutRedirectURI, eval.NewRawOrigin(req.URL))
+		if err != nil {
+			return "", err
+		}
+		postLogoutRedirectURI = absoluteURL
+	}
+
+	var endSessionEndpoint string
+	if s.endSessionEndpoint != nil {
+		var err error
+		if endSessionEndpoint, err = s.endSessionEndpoint(); err != nil {
+			return "", err
+		}
+	}
+
+	if endSessionEndpoint == "" {
+		return postLogoutRedirectURI, nil
+	}
+
+	logoutURL, err := url.Parse(endSessionEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := logoutURL.Query()
+	query.Set("client_id", s.clientID)
+	if idToken, ok := data["id_token"].(string); ok {
+		query.Set("id_token_hint", idToken)
+	}
+	if postLogoutRedirectURI != "" {
+		query.Set("post_logout_redirect_uri", postLogoutRedirectURI)
+	}
+	logoutURL.RawQuery = query.Encode()
+
+	return logoutURL.String(), nil
+}
+
+func (s *Session) read(value string) *sessionData {
+	var b []byte
+	if s.store == SessionStoreMemory {
+		stored, ok := s.memStore.Get(s.storageKey(value)).([]byte)
+		if !ok {
+			return nil
+		}
+		b = stored
+	} else {
+		sealed, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(sealed) < s.aead.NonceSize() {
+			return nil
+		}
+
+		nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+		if b, err = s.aead.Open(nil, nonce, ciphertext, []byte(s.cookieName)); err != nil {
+			return nil
+		}
+	}
+
+	sd := &sessionData{}
+	if err := json.Unmarshal(b, sd); err != nil || sd.Data == nil {
+		return nil
+	}
+	return sd
+}
+
+// write stores the session data in the memory store with the given cookie
+// value as key or seals the data as cookie value.
+func (s *Session) write(req *http.Request, value string, sd *sessionData) error {
+	b, err := json.Marshal(sd)
+	if err != nil {
+		return err
+	}
+
+	maxAge := sd.Expires - time.Now().Unix()
+	if maxAge <= 0 {
+		return fmt.Errorf("session expired")
+	}
+
+	if s.store == SessionStoreMemory {
+		s.memStore.Set(s.storageKey(value), b, maxAge)
+	} else {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+			return err
+		}
+		value = base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, b, []byte(s.cookieName)))
+		if len(value) > maxSessionCookieSize {
+			return fmt.Errorf("session data exceeds the cookie size limit, use store = %q instead", SessionStoreMemory)
+		}
+	}
+
+	return s.addCookie(req, &http.Cookie{Name: s.cookieName, Value: value, MaxAge: int(maxAge)})
+}
+
+// revoked returns whether the session was created before a back-channel logout of its sid or sub.
+func (s *Session) revoked(sd *sessionData) bool {
+	if s.backChannelLogout == nil {
+		return false
+	}
+
+	for name, value := range map[string]string{"sid": sd.Sid, "sub": sd.Sub} {
+		if value == "" {
+			continue
+		}
+		if loggedOut, ok := s.memStore.Get(s.logoutKey(name, value)).(int64); ok && loggedOut >= sd.Created {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) storageKey(value string) string {
+	return "session|" + s.name + "|" + value
+}
+
+func (s *Session) logoutKey(claim, value string) string {
+	return "logout|" + s.name + "|" + claim + "|" + value
+}
+
+func (s *Session) addCookie(req *http.Request, cookie *http.Cookie) error {
+	rw, ok := req.Context().Value(request.ResponseWriter).(interface{ AddCookie(*http.Cookie) })
+	if !ok {
+		return fmt.Errorf("session cookie: response writer not available")
+	}
+
+	cookie.Path = "/"
+	cookie.HttpOnly = true
+	cookie.Secure = true
+	cookie.SameSite = http.SameSiteLaxMode
+	rw.AddCookie(cookie)
+	return nil
+}
+
+// subjectClaims returns the sid and sub claims of the given ID token claims.
+func subjectClaims(claims interface{}) (sid string, sub string) {
+	var claimsMap map[string]interface{}
+	switch c := claims.(type) {
+	case map[string]interface{}:
+		claimsMap = c
+	case jwt.MapClaims:
+		claimsMap = c
+	}
+
+	sid, _ = claimsMap["sid"].(string)
+	sub, _ = claimsMap["sub"].(string)
+	return sid, sub
+}
+
+func setExpiresAt(data map[string]interface{}) {
+	if expiresIn, ok := data["expires_in"].(float64); ok {
+		data[SessionExpiresAt] = float64(time.Now().Unix() + int64(expiresIn))
+	}
+}
@@ -0,0 +1,257 @@
+package accesscontrol
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/internal/seetie"
+)
+
+var _ AccessControl = &Signature{}
+
+// SignatureParams is the variable name for the parameters of a signature header
+// within the canonical_string and timestamp expressions.
+const SignatureParams = "signature_params"
+
+// SignatureOptions represents the options to create a Signature access control.
+type SignatureOptions struct {
+	Algorithm          string
+	BodyLimit          int64
+	CanonicalString    hcl.Expression
+	Encoding           string
+	Header             string
+	HeaderParam        string
+	Key                []byte
+	Prefix             string
+	Timestamp          hcl.Expression // nil without a configured timestamp
+	TimestampTolerance string
+}
+
+// Signature represents an access control which verifies an HMAC signature
+// of the client request, e.g. for webhooks.
+type Signature struct {
+	bodyLimit       int64
+	canonicalString hcl.Expression
+	decode          func(string) ([]byte, error)
+	header          string
+	headerParam     string
+	key             []byte
+	newHash         func() hash.Hash
+	prefix          string
+	timestamp       hcl.Expression
+	tolerance       time.Duration
+}
+
+// NewSignature creates a new Signature object.
+func NewSignature(options *SignatureOptions) (*Signature, error) {
+	if options.Header == "" {
+		return nil, fmt.Errorf("header required")
+	}
+
+	if len(options.Key) == 0 {
+		return nil, fmt.Errorf("key or key_file required")
+	}
+
+	s := &Signature{
+		bodyLimit:       options.BodyLimit,
+		canonicalString: options.CanonicalString,
+		header:          options.Header,
+		headerParam:     options.HeaderParam,
+		key:             options.Key,
+		prefix:          options.Prefix,
+		timestamp:       options.Timestamp,
+	}
+
+	switch strings.ToLower(options.Algorithm) {
+	case "", "sha256":
+		s.newHash = sha256.New
+	case "sha1":
+		s.newHash = sha1.New
+	case "sha512":
+		s.newHash = sha512.New
+	default:
+		return nil, fmt.Errorf("algorithm %q is not supported", options.Algorithm)
+	}
+
+	switch strings.ToLower(options.Encoding) {
+	case "", "hex":
+		s.decode = hex.DecodeString
+	case "base64":
+		s.decode = base64.StdEncoding.DecodeString
+	case "base64url":
+		s.decode = base64.RawURLEncoding.DecodeString
+	default:
+		return nil, fmt.Errorf("encoding %q is not supported", options.Encoding)
+	}
+
+	tolerance := options.TimestampTolerance
+	if tolerance == "" {
+		tolerance = "5m"
+	}
+	d, err := time.ParseDuration(tolerance)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp_tolerance: %v", err)
+	}
+	s.tolerance = d
+
+	return s, nil
+}
+
+// Validate implements the AccessControl interface.
+func (s *Signature) Validate(req *http.Request) error {
+	headerValue := req.Header.Get(s.header)
+	if headerValue == "" {
+		return errors.Signature.Messagef("missing signature header %q", s.header)
+	}
+
+	params := parseSignatureParams(headerValue)
+
+	var signatures []string
+	if s.headerParam != "" {
+		signatures = params[s.headerParam]
+	} else {
+		signatures = []string{headerValue}
+	}
+
+	if len(signatures) == 0 {
+		return errors.Signature.Messagef("missing signature parameter %q", s.headerParam)
+	}
+
+	if req.GetBody == nil {
+		if err := eval.SetGetBody(req, s.bodyLimit); err != nil {
+			return err
+		}
+		*req = *req.WithContext(eval.ContextFromRequest(req).WithClientRequest(req))
+	} else if req.ContentLength > s.bodyLimit { // already buffered with the endpoint limit
+		return errors.ClientRequest.
+			Status(http.StatusRequestEntityTooLarge).
+			Message("body size exceeded: " + units.HumanSize(float64(s.bodyLimit)))
+	}
+
+	firstParams := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		firstParams[k] = v[0]
+	}
+	hclCtx := eval.ContextFromRequest(req).HCLContext().NewChild()
+	hclCtx.Variables = map[string]cty.Value{
+		SignatureParams: seetie.MapToValue(firstParams),
+	}
+
+	if err := s.validateTimestamp(hclCtx); err != nil {
+		return err
+	}
+
+	canonical, err := s.getCanonicalString(req, hclCtx)
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(s.newHash, s.key)
+	mac.Write(canonical)
+	expected := mac.Sum(nil)
+
+	for _, signature := range signatures {
+		provided, derr := s.decode(strings.TrimPrefix(strings.TrimSpace(signature), s.prefix))
+		if derr != nil {
+			continue
+		}
+		if hmac.Equal(expected, provided) {
+			return nil
+		}
+	}
+
+	return errors.SignatureInvalid.Message("signature mismatch")
+}
+
+func (s *Signature) getCanonicalString(req *http.Request, hclCtx *hcl.EvalContext) ([]byte, error) {
+	if s.canonicalString != nil {
+		val, diags := s.canonicalString.Value(hclCtx)
+		if diags.HasErrors() {
+			return nil, errors.Signature.Message("canonical_string").With(diags)
+		}
+		if !val.IsNull() {
+			if val.Type() != cty.String {
+				return nil, errors.Signature.Messagef("canonical_string must evaluate to string, got: %s", val.Type().FriendlyName())
+			}
+			return []byte(val.AsString()), nil
+		}
+	}
+
+	if req.GetBody == nil { // no body
+		return []byte{}, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, errors.Signature.With(err)
+	}
+	return ioutil.ReadAll(body)
+}
+
+func (s *Signature) validateTimestamp(hclCtx *hcl.EvalContext) error {
+	if s.timestamp == nil {
+		return nil
+	}
+
+	val, diags := s.timestamp.Value(hclCtx)
+	if diags.HasErrors() {
+		return errors.SignatureInvalid.Message("missing timestamp").With(diags)
+	}
+
+	if val.IsNull() {
+		return errors.SignatureInvalid.Message("missing timestamp")
+	}
+
+	var ts int64
+	switch val.Type() {
+	case cty.Number:
+		ts, _ = val.AsBigFloat().Int64()
+	case cty.String:
+		i, err := strconv.ParseInt(val.AsString(), 10, 64)
+		if err != nil {
+			return errors.SignatureInvalid.Messagef("invalid timestamp %q", val.AsString())
+		}
+		ts = i
+	default:
+		return errors.Signature.Messagef("timestamp must evaluate to number, got: %s", val.Type().FriendlyName())
+	}
+
+	diff := time.Now().Unix() - ts
+	if math.Abs(float64(diff)) > s.tolerance.Seconds() {
+		return errors.SignatureInvalid.Messagef("timestamp %d exceeds tolerance of %s", ts, s.tolerance)
+	}
+
+	return nil
+}
+
+// parseSignatureParams parses a header value with a comma separated list of key=value pairs,
+// e.g. "t=1492774577,v1=5257a869...".
+func parseSignatureParams(value string) map[string][]string {
+	params := make(map[string][]string)
+	for _, pair := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			continue
+		}
+		params[kv[0]] = append(params[kv[0]], kv[1])
+	}
+	return params
+}
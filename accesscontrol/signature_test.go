@@ -0,0 +1,122 @@
+package accesscontrol_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	ac "github.com/avenga/couper/accesscontrol"
+	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/internal/test"
+)
+
+func Test_Signature_Validate(t *testing.T) {
+	helper := test.New(t)
+
+	key := []byte("whs3cr3t")
+	payload := `{"action":"opened"}`
+
+	sign := func(s string) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(s))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	expr := func(src string) hcl.Expression {
+		e, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		return e
+	}
+
+	github, err := ac.NewSignature(&ac.SignatureOptions{
+		BodyLimit: 1024,
+		Header:    "X-Hub-Signature-256",
+		Key:       key,
+		Prefix:    "sha256=",
+	})
+	helper.Must(err)
+
+	stripe, err := ac.NewSignature(&ac.SignatureOptions{
+		BodyLimit:       1024,
+		CanonicalString: expr(`"${signature_params.t}.${request.body}"`),
+		Header:          "Stripe-Signature",
+		HeaderParam:     "v1",
+		Key:             key,
+		Timestamp:       expr(`signature_params.t`),
+	})
+	helper.Must(err)
+
+	headerTimestamp, err := ac.NewSignature(&ac.SignatureOptions{
+		BodyLimit: 1024,
+		Header:    "X-Signature",
+		Key:       key,
+		Timestamp: expr(`request.headers.x-timestamp`),
+	})
+	helper.Must(err)
+
+	nullTimestamp, err := ac.NewSignature(&ac.SignatureOptions{
+		BodyLimit: 1024,
+		Header:    "X-Signature",
+		Key:       key,
+		Timestamp: expr(`null`),
+	})
+	helper.Must(err)
+
+	now := time.Now().Unix()
+	old := now - 3600
+
+	tests := []struct {
+		name      string
+		signature *ac.Signature
+		header    string
+		value     string
+		expErr    *errors.Error
+	}{
+		{"github: valid", github, "X-Hub-Signature-256", "sha256=" + sign(payload), nil},
+		{"github: invalid", github, "X-Hub-Signature-256", "sha256=" + sign(payload+" "), errors.SignatureInvalid},
+		{"github: malformed", github, "X-Hub-Signature-256", "sha256=zz", errors.SignatureInvalid},
+		{"github: missing", github, "X-Other", "sha256=" + sign(payload), errors.Signature},
+		{"stripe: valid", stripe, "Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", now, sign(fmt.Sprintf("%d.%s", now, payload))), nil},
+		{"stripe: rotated secret", stripe, "Stripe-Signature", fmt.Sprintf("t=%d,v1=%s,v1=%s", now, sign("other"), sign(fmt.Sprintf("%d.%s", now, payload))), nil},
+		{"stripe: replay", stripe, "Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", old, sign(fmt.Sprintf("%d.%s", old, payload))), errors.SignatureInvalid},
+		{"stripe: tampered timestamp", stripe, "Stripe-Signature", fmt.Sprintf("t=%d,v1=%s", now, sign(fmt.Sprintf("%d.%s", old, payload))), errors.SignatureInvalid},
+		{"header timestamp: missing", headerTimestamp, "X-Signature", sign(payload), errors.SignatureInvalid},
+		{"null timestamp", nullTimestamp, "X-Signature", sign(payload), errors.SignatureInvalid},
+		{"stripe: missing param", stripe, "Stripe-Signature", fmt.Sprintf("t=%d", now), errors.Signature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+			req.Header.Set(tt.header, tt.value)
+			req = setContext(req)
+
+			err := tt.signature.Validate(req)
+			if tt.expErr == nil {
+				if err != nil {
+					subT.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+
+			gerr, ok := err.(*errors.Error)
+			if !ok {
+				subT.Fatalf("expected error %q, got: %v", tt.expErr.Kinds()[0], err)
+			}
+			if gerr.Kinds()[0] != tt.expErr.Kinds()[0] {
+				subT.Errorf("expected error %q, got: %q", tt.expErr.Kinds()[0], gerr.Kinds()[0])
+			}
+		})
+	}
+}
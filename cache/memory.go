@@ -36,6 +36,12 @@ func New(log *logrus.Entry, quitCh <-chan struct{}) *MemoryStore {
 	return store
 }
 
+// Done returns the channel which is closed when the <MemoryStore> is not used anymore,
+// e.g. on configuration reloads.
+func (ms *MemoryStore) Done() <-chan struct{} {
+	return ms.quitCh
+}
+
 // Del deletes the value by the key from the <MemoryStore>.
 func (ms *MemoryStore) Del(k string) {
 	ms.mu.Lock()
@@ -18,8 +18,12 @@ func NewCommand(ctx context.Context, cmd string) Cmd {
 	switch strings.ToLower(cmd) {
 	case "run":
 		return NewRun(ContextWithSignal(ctx))
+	case "eval":
+		return NewEval(ctx)
 	case "help":
 		return NewHelp(ctx)
+	case "verify":
+		return NewVerify(ctx)
 	case "version":
 		return NewVersion()
 	default:
@@ -0,0 +1,263 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/configload"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/config/runtime"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/handler/middleware"
+)
+
+var _ Cmd = &Eval{}
+
+// evalExpressionFilename is used as filename for diagnostic ranges of evaluated expressions.
+const evalExpressionFilename = "<expression>"
+
+// Eval evaluates expressions with the context of a configuration file
+// and a mocked client request.
+type Eval struct {
+	context context.Context
+	input   io.Reader
+	output  io.Writer
+}
+
+func NewEval(ctx context.Context) *Eval {
+	return &Eval{
+		context: ctx,
+		input:   os.Stdin,
+		output:  os.Stdout,
+	}
+}
+
+// headerFlags collects repeated "Name: value" header flag values.
+type headerFlags http.Header
+
+func (h headerFlags) String() string {
+	var result []string
+	for name, values := range h {
+		for _, value := range values {
+			result = append(result, name+": "+value)
+		}
+	}
+	return strings.Join(result, ", ")
+}
+
+func (h headerFlags) Set(s string) error {
+	idx := strings.Index(s, ":")
+	if idx < 1 || strings.TrimSpace(s[:idx]) == "" {
+		return fmt.Errorf("invalid header: %q, expected format 'Name: value'", s)
+	}
+	http.Header(h).Add(strings.TrimSpace(s[:idx]), strings.TrimSpace(s[idx+1:]))
+	return nil
+}
+
+func (e *Eval) Execute(args Args, _ *config.Couper, logEntry *logrus.Entry) error {
+	var (
+		bodyFile   string
+		dirPaths   StringList
+		filePaths  StringList
+		method     string
+		rawURL     string
+		remoteAddr string
+	)
+	header := make(headerFlags)
+
+	set := flag.NewFlagSet("eval", flag.ContinueOnError)
+	set.Var(&filePaths, "f", "-f ./my-path/couper.hcl")
+	set.Var(&dirPaths, "d", "-d ./my-path/conf.d")
+	set.StringVar(&method, "method", http.MethodGet, "-method POST")
+	set.StringVar(&rawURL, "url", "http://localhost:8080/", "-url http://localhost:8080/path?query=value")
+	set.Var(header, "H", "-H 'Content-Type: application/json'")
+	set.StringVar(&bodyFile, "body-file", "", "-body-file ./request.json")
+	set.StringVar(&remoteAddr, "remote-addr", "127.0.0.1:1234", "-remote-addr 192.0.2.1:1234")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	// Read the body first, loading the configuration changes the working directory.
+	var body []byte
+	if bodyFile != "" {
+		b, err := os.ReadFile(bodyFile)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+
+	if len(filePaths) == 0 && len(dirPaths) == 0 {
+		filePaths = StringList{config.DefaultFilename}
+	}
+
+	couperConfig, err := configload.LoadFiles(filePaths, dirPaths)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(e.context, method, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header = http.Header(header).Clone()
+	req.RemoteAddr = remoteAddr
+	if err = eval.SetGetBody(req, int64(len(body))+1); err != nil {
+		return err
+	}
+
+	// Set the context values of the server and the uid middleware.
+	ctx := context.WithValue(req.Context(), request.StartTime, time.Now())
+	ctx = context.WithValue(ctx, request.UID, middleware.NewUIDFunc(couperConfig.Settings.RequestIDFormat)())
+	ctx = context.WithValue(ctx, request.XFF, req.Header.Get("X-Forwarded-For"))
+	ctx = context.WithValue(ctx, request.ClientIP, couperConfig.Settings.ClientIP(req))
+	req = req.WithContext(ctx)
+
+	evalContext, ok := couperConfig.Context.Value(request.ContextType).(*eval.Context)
+	if !ok {
+		return fmt.Errorf("missing evaluation context")
+	}
+
+	if logEntry == nil {
+		logEntry = logrus.NewEntry(logrus.StandardLogger())
+	}
+	stores, err := runtime.NewKeyValueStores(couperConfig, cache.New(logEntry, e.context.Done()))
+	if err != nil {
+		return err
+	}
+	evalContext = evalContext.WithStores(stores)
+	// The evaluated expressions are unknown before, provide the multipart file content anyway.
+	evalContext = evalContext.WithMultipartContent(true).WithClientRequest(req)
+
+	if set.NArg() > 0 {
+		for _, src := range set.Args() {
+			if err = e.evaluate(evalContext, couperConfig.Defaults, src); err != nil {
+				return fmt.Errorf("evaluation of %q failed", src)
+			}
+		}
+		return nil
+	}
+
+	return e.repl(evalContext, couperConfig.Defaults)
+}
+
+// repl evaluates each line of the input until its end or an exit command.
+func (e *Eval) repl(evalContext *eval.Context, defaults *config.Defaults) error {
+	interactive := false
+	if f, ok := e.input.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			interactive = info.Mode()&os.ModeCharDevice != 0
+		}
+	}
+
+	scanner := bufio.NewScanner(e.input)
+	for {
+		if interactive {
+			fmt.Fprint(e.output, "> ")
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+
+		src := strings.TrimSpace(scanner.Text())
+		switch src {
+		case "":
+			continue
+		case "exit", "quit":
+			return nil
+		}
+		// errors are already written to the output, go on with the next expression
+		_ = e.evaluate(evalContext, defaults, src)
+	}
+}
+
+// evaluate writes the resulting value of the given expression or its diagnostics to the output.
+func (e *Eval) evaluate(evalContext *eval.Context, defaults *config.Defaults, src string) error {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), evalExpressionFilename, hcl.InitialPos)
+	if !diags.HasErrors() {
+		hclContext := evalContext.WithEnvironmentRefs([]byte(src), defaults).HCLContext()
+		val, valDiags := expr.Value(hclContext)
+		diags = append(diags, valDiags...)
+		if !diags.HasErrors() {
+			if !val.IsWhollyKnown() {
+				_, err := fmt.Fprintln(e.output, "(unknown)")
+				return err
+			}
+			_, err := fmt.Fprintln(e.output, string(hclwrite.TokensForValue(val).Bytes()))
+			return err
+		}
+	}
+
+	if err := e.writeDiagnostics(diags, src); err != nil {
+		return err
+	}
+	return diags
+}
+
+// writeDiagnostics writes the given diagnostics with their range within the expression source.
+func (e *Eval) writeDiagnostics(diags hcl.Diagnostics, src string) error {
+	lines := strings.Split(src, "\n")
+	for _, diag := range diags {
+		severity := "Error"
+		if diag.Severity == hcl.DiagWarning {
+			severity = "Warning"
+		}
+
+		out := &strings.Builder{}
+		fmt.Fprintf(out, "%s: %s\n", severity, diag.Summary)
+		if r := diag.Subject; r != nil {
+			fmt.Fprintf(out, "  on %s:\n", r.String())
+			if r.Start.Line > 0 && r.Start.Line <= len(lines) {
+				line := lines[r.Start.Line-1]
+				end := r.End.Column
+				if r.End.Line > r.Start.Line || end > len(line)+1 {
+					end = len(line) + 1
+				}
+				width := end - r.Start.Column
+				if width < 1 {
+					width = 1
+				}
+				fmt.Fprintf(out, "    %s\n    %s%s\n", line,
+					strings.Repeat(" ", r.Start.Column-1), strings.Repeat("^", width))
+			}
+		}
+		if diag.Detail != "" {
+			fmt.Fprintf(out, "%s\n", diag.Detail)
+		}
+
+		if _, err := fmt.Fprintln(e.output, out.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Eval) Usage() {
+	println(`Usage of eval:
+  eval [<options>] [<expression>...]	Evaluate expressions with the given configuration files.
+					Without expressions, each line of the standard input gets evaluated.
+
+Options:
+  -f		Path to a configuration file. Can be repeated. Default: couper.hcl
+  -d		Directory with *.hcl configuration files. Can be repeated.
+  -method	Method of the mocked client request. Default: GET
+  -url		URL of the mocked client request. Default: http://localhost:8080/
+  -H		Header of the mocked client request, e.g. 'Content-Type: application/json'. Can be repeated.
+  -body-file	File with the body of the mocked client request.
+  -remote-addr	Remote address of the mocked client request. Default: 127.0.0.1:1234`)
+}
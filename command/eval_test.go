@@ -0,0 +1,74 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	_, currFile, _, _ := runtime.Caller(0)
+	testdata := filepath.Join(filepath.Dir(currFile), "testdata", "eval")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	withConfig := func(args ...string) Args {
+		return append(Args{"-f", filepath.Join(testdata, "01_couper.hcl")}, args...)
+	}
+	requestArgs := []string{"-method", "POST", "-url", "http://couper.local/path?q=a&q=b",
+		"-H", "Content-Type: application/json", "-H", "X-Test: value",
+		"-body-file", filepath.Join(testdata, "body.json")}
+
+	tests := []struct {
+		name      string
+		args      Args
+		input     string
+		expOutput string
+		expErr    string
+	}{
+		{"literal", withConfig(`1 + 1`), "", "2\n", ""},
+		{"user function", withConfig(`greet("couper")`), "", "\"Hello couper!\"\n", ""},
+		{"default env", withConfig(`env.COUPER_EVAL_TEST`), "", "\"default\"\n", ""},
+		{"request", withConfig(append(requestArgs, `request.method`, `request.query.q`, `request.headers.x-test`, `request.json_body.list[1]`)...), "",
+			"\"POST\"\n[\"a\", \"b\"]\n\"value\"\n2\n", ""},
+		{"store", withConfig(`store_incr("counter", "a", 2)`, `store_get("counter", "a")`), "", "2\n2\n", ""},
+		{"connection", withConfig("-remote-addr", "192.0.2.1:1234", `request.remote_ip`, `request.remote_port`, `request.received_at != null`, `request.id != ""`), "",
+			"\"192.0.2.1\"\n1234\ntrue\ntrue\n", ""},
+		{"object", withConfig(`{ a = "b" }`), "", "{\n  a = \"b\"\n}\n", ""},
+		{"diagnostic", withConfig(`request.foo`), "",
+			"Error: Unsupported attribute\n  on <expression>:1,8-12:\n    request.foo\n           ^^^^\nThis object does not have an attribute named \"foo\".\n\n",
+			`evaluation of "request.foo" failed`},
+		{"repl", withConfig(), "to_upper(\"a\")\n\n\"a\" +\n1 + 2\nexit\n4\n",
+			"\"A\"\nError: Invalid expression\n  on <expression>:1,6-6:\n    \"a\" +\n         ^\nExpected the start of an expression, but found an invalid expression token.\n\n3\n", ""},
+		{"missing config", Args{"-f", filepath.Join(testdata, "missing.hcl"), `1`}, "", "", "missing.hcl"},
+		{"invalid header", withConfig("-H", "invalid"), "", "", "invalid header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			output := &bytes.Buffer{}
+			e := NewEval(context.Background())
+			e.input = strings.NewReader(tt.input)
+			e.output = output
+
+			err := e.Execute(tt.args, nil, nil)
+			if tt.expErr == "" && err != nil {
+				subT.Fatalf("unexpected error: %v", err)
+			} else if tt.expErr != "" && (err == nil || !strings.Contains(err.Error(), tt.expErr)) {
+				subT.Fatalf("expected error containing %q, got: %v", tt.expErr, err)
+			}
+
+			if output.String() != tt.expOutput {
+				subT.Errorf("expected output:\n%q\ngot:\n%q", tt.expOutput, output.String())
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package command
 
 import (
 	"flag"
+	"path/filepath"
 	"strings"
 )
 
@@ -44,3 +45,28 @@ func (a Args) Filter(set *flag.FlagSet) Args {
 	}
 	return args
 }
+
+// StringList is a flag value which collects the values of a repeated flag.
+type StringList []string
+
+func (s *StringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *StringList) Set(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
+// AbsPaths returns the absolute paths of the given list.
+func (s StringList) AbsPaths() (StringList, error) {
+	result := make(StringList, 0, len(s))
+	for _, path := range s {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, absPath)
+	}
+	return result, nil
+}
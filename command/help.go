@@ -17,14 +17,18 @@ Couper usage:
   couper <cmd> <options>
 
 Available commands:
+  eval		Evaluate expressions with given configuration file.
   help		Usage for given command.
   run		Start the server with given configuration file.
+  verify	Verify the given configuration file without starting the server.
   version	Print the current version and build information.
 
 Examples:
   couper run
   couper run -f couper.hcl
   couper run -watch -log-format json -log-pretty -p 3000
+  couper verify -f couper.hcl -format json
+  couper eval -f couper.hcl -url 'http://localhost:8080/?q=1' 'request.query.q[0]'
 `)
 }
 
@@ -44,6 +44,7 @@ func NewRun(ctx context.Context) *Run {
 	set.BoolVar(&settings.XForwardedHost, "xfh", settings.XForwardedHost, "-xfh")
 	set.Var(&AcceptForwardedValue{settings: &settings}, "accept-forwarded-url", "-accept-forwarded-url [proto][,host][,port]")
 	set.Var(&settings.TLSDevProxy, "https-dev-proxy", "-https-dev-proxy 8443:8080,9443:9000")
+	set.Var(&settings.TrustedProxies, "trusted-proxies", "-trusted-proxies 10.0.0.0/8,192.168.0.1")
 	set.BoolVar(&settings.NoProxyFromEnv, "no-proxy-from-env", settings.NoProxyFromEnv, "-no-proxy-from-env")
 	set.StringVar(&settings.RequestIDAcceptFromHeader, "request-id-accept-from-header", settings.RequestIDAcceptFromHeader, "-request-id-accept-from-header X-UID")
 	set.StringVar(&settings.RequestIDBackendHeader, "request-id-backend-header", settings.RequestIDBackendHeader, "-request-id-backend-header Couper-Request-ID")
@@ -110,6 +111,9 @@ func (r *Run) Execute(args Args, config *config.Couper, logEntry *logrus.Entry)
 	if err != nil {
 		return err
 	}
+	if err = r.settings.SetTrustedProxies(); err != nil {
+		return err
+	}
 	r.settingsMu.Lock()
 	config.Settings = r.settings
 	r.settingsMu.Unlock()
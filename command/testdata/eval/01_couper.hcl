@@ -0,0 +1,16 @@
+server "eval" {}
+
+definitions {
+  function "greet" {
+    params = ["name"]
+    result = "Hello ${name}!"
+  }
+
+  store "counter" {}
+}
+
+defaults {
+  environment_variables = {
+    COUPER_EVAL_TEST = "default"
+  }
+}
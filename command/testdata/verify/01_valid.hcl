@@ -0,0 +1,13 @@
+server "verify" {
+  endpoint "/" {
+    proxy {
+      backend = "origin"
+    }
+  }
+}
+
+definitions {
+  backend "origin" {
+    origin = "http://localhost:8080"
+  }
+}
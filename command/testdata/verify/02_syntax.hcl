@@ -0,0 +1,5 @@
+server "verify" {
+  endpoint "/" {
+    path =
+  }
+}
@@ -0,0 +1,7 @@
+server "verify" {
+  endpoint "/" {
+    proxy {
+      backend = "missing"
+    }
+  }
+}
@@ -0,0 +1,9 @@
+server "verify" {
+  access_control = ["missing"]
+
+  endpoint "/" {
+    response {
+      body = "OK"
+    }
+  }
+}
@@ -0,0 +1,16 @@
+server "verify" {
+  access_control = ["token"]
+
+  endpoint "/" {
+    response {
+      body = "OK"
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    signature_algorithm = "RS256"
+    key_file = "missing.pem"
+  }
+}
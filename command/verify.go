@@ -0,0 +1,240 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/configload"
+	"github.com/avenga/couper/config/runtime"
+	"github.com/avenga/couper/errors"
+)
+
+var _ Cmd = &Verify{}
+
+const (
+	verifyFormatJSON = "json"
+	verifyFormatText = "text"
+)
+
+// Verify loads a configuration file and constructs the server configuration
+// without listening on any port.
+type Verify struct {
+	context context.Context
+	output  io.Writer
+}
+
+func NewVerify(ctx context.Context) *Verify {
+	return &Verify{
+		context: ctx,
+		output:  os.Stdout,
+	}
+}
+
+// verifyResult is the JSON representation of a verification.
+type verifyResult struct {
+	Valid       bool                `json:"valid"`
+	Diagnostics []*verifyDiagnostic `json:"diagnostics"`
+}
+
+type verifyDiagnostic struct {
+	Severity string       `json:"severity"`
+	Summary  string       `json:"summary"`
+	Detail   string       `json:"detail,omitempty"`
+	Range    *verifyRange `json:"range,omitempty"`
+}
+
+type verifyRange struct {
+	Filename string    `json:"filename"`
+	Start    verifyPos `json:"start"`
+	End      verifyPos `json:"end"`
+}
+
+type verifyPos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+func (v *Verify) Execute(args Args, _ *config.Couper, _ *logrus.Entry) error {
+	var (
+		dirPaths  StringList
+		filePaths StringList
+		format    string
+	)
+
+	set := flag.NewFlagSet("verify", flag.ContinueOnError)
+	set.Var(&filePaths, "f", "-f ./my-path/couper.hcl")
+	set.Var(&dirPaths, "d", "-d ./my-path/conf.d")
+	set.StringVar(&format, "format", verifyFormatText, "-format json")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	if format != verifyFormatText && format != verifyFormatJSON {
+		return fmt.Errorf("invalid format: %q, expected %q or %q", format, verifyFormatText, verifyFormatJSON)
+	}
+
+	if len(filePaths) == 0 && len(dirPaths) == 0 {
+		filePaths = StringList{config.DefaultFilename}
+	}
+	// diagnostic ranges are relative to the directory of the first configuration file
+	files, _ := configload.ConfigFiles(filePaths, dirPaths)
+	name := strings.Join(append(append([]string{}, filePaths...), dirPaths...), ", ")
+
+	diags := v.verify(filePaths, dirPaths)
+
+	if format == verifyFormatJSON {
+		if err := v.writeJSON(files, diags); err != nil {
+			return err
+		}
+	} else if err := v.writeText(name, files, diags); err != nil {
+		return err
+	}
+
+	if diags.HasErrors() {
+		return fmt.Errorf("configuration %q is invalid", name)
+	}
+	return nil
+}
+
+// verify returns the diagnostics of loading the given configuration files and constructing the server configuration.
+// The syntax errors of all files are reported, the server configuration is only constructed for a loaded configuration.
+// Loading and constructing stop at the first semantic error, so at most one of those is reported per run.
+func (v *Verify) verify(filePaths, dirPaths []string) hcl.Diagnostics {
+	couperConfig, err := configload.LoadFiles(filePaths, dirPaths)
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+
+	logger := logrus.New()
+	logger.Out = io.Discard
+
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+
+	_, err = runtime.NewServerConfiguration(couperConfig, logger.WithContext(v.context), cache.New(logger.WithContext(v.context), quitCh))
+	if err != nil {
+		return errorDiagnostics(err)
+	}
+	return nil
+}
+
+// errorDiagnostics returns the diagnostics of the given error, or a diagnostic
+// without a source range for other errors. Diagnostics without a severity are errors.
+// Couper errors are summarized with their log message which contains the cause.
+func errorDiagnostics(err error) hcl.Diagnostics {
+	var diags hcl.Diagnostics
+	if !goerrors.As(err, &diags) || len(diags) == 0 {
+		var diag *hcl.Diagnostic
+		if goerrors.As(err, &diag) {
+			diags = hcl.Diagnostics{diag}
+		}
+	}
+
+	if len(diags) > 0 {
+		for _, diag := range diags {
+			if diag.Severity == hcl.DiagInvalid {
+				diag.Severity = hcl.DiagError
+			}
+		}
+		return diags
+	}
+
+	summary := err.Error()
+	var gerr errors.GoError
+	if goerrors.As(err, &gerr) {
+		summary = gerr.LogError()
+	}
+
+	return hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  summary,
+	}}
+}
+
+func (v *Verify) writeText(name string, configFiles []string, diags hcl.Diagnostics) error {
+	if len(diags) == 0 {
+		_, err := fmt.Fprintf(v.output, "configuration %q is valid\n", name)
+		return err
+	}
+
+	files := make(map[string]*hcl.File)
+	for _, diag := range diags {
+		if diag.Subject == nil {
+			continue
+		}
+		filename := diag.Subject.Filename
+		if _, exist := files[filename]; exist {
+			continue
+		}
+		if src, err := os.ReadFile(diagnosticFilename(configFiles, filename)); err == nil {
+			files[filename] = &hcl.File{Bytes: src}
+		}
+	}
+
+	return hcl.NewDiagnosticTextWriter(v.output, files, 0, false).WriteDiagnostics(diags)
+}
+
+// writeJSON writes the diagnostics with file names joined to the directory of the given first configuration file.
+func (v *Verify) writeJSON(configFiles []string, diags hcl.Diagnostics) error {
+	result := &verifyResult{
+		Valid:       !diags.HasErrors(),
+		Diagnostics: make([]*verifyDiagnostic, 0, len(diags)),
+	}
+
+	for _, diag := range diags {
+		d := &verifyDiagnostic{
+			Severity: "error",
+			Summary:  diag.Summary,
+			Detail:   diag.Detail,
+		}
+		if diag.Severity == hcl.DiagWarning {
+			d.Severity = "warning"
+		}
+		if r := diag.Subject; r != nil {
+			d.Range = &verifyRange{
+				Filename: diagnosticFilename(configFiles, r.Filename),
+				Start:    verifyPos{Line: r.Start.Line, Column: r.Start.Column, Byte: r.Start.Byte},
+				End:      verifyPos{Line: r.End.Line, Column: r.End.Column, Byte: r.End.Byte},
+			}
+		}
+		result.Diagnostics = append(result.Diagnostics, d)
+	}
+
+	encoder := json.NewEncoder(v.output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// diagnosticFilename returns the path of a diagnostic file name which is relative
+// to the directory of the first of the given configuration files.
+func diagnosticFilename(configFiles []string, filename string) string {
+	if filepath.IsAbs(filename) || len(configFiles) == 0 {
+		return filename
+	}
+	return filepath.Join(filepath.Dir(configFiles[0]), filename)
+}
+
+func (v *Verify) Usage() {
+	println(`Usage of verify:
+  verify [<options>]	Load the configuration files and construct all servers, endpoints, backends and
+			access controls without listening on any port. Exits non-zero on errors.
+			Reports the syntax errors of all files but only the first semantic error.
+
+Options:
+  -f		Path to a configuration file. Can be repeated. Default: couper.hcl
+  -d		Directory with *.hcl configuration files. Can be repeated.
+  -format	Output format of the diagnostics: text or json. Default: text`)
+}
@@ -0,0 +1,207 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	_, currFile, _, _ := runtime.Caller(0)
+	testdata := filepath.Join(filepath.Dir(currFile), "testdata", "verify")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := []struct {
+		file       string
+		expSummary string
+		expLine    int
+	}{
+		{"01_valid.hcl", "", 0},
+		{"02_syntax.hcl", "Invalid expression", 3},
+		{"03_backend.hcl", "backend reference 'missing' is not defined", 3},
+		{"04_access_control.hcl", "accessControl is not defined: missing", 0},
+		// loading stops at the first semantic error, the undefined access control is not reported
+		{"05_semantic.hcl", "backend reference 'missing' is not defined", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(subT *testing.T) {
+			filePath := filepath.Join(testdata, tt.file)
+
+			output := &bytes.Buffer{}
+			v := NewVerify(context.Background())
+			v.output = output
+
+			err := v.Execute(Args{"-f", filePath}, nil, nil)
+			if tt.expSummary == "" {
+				if err != nil {
+					subT.Fatalf("unexpected error: %v", err)
+				}
+				if !strings.Contains(output.String(), "is valid") {
+					subT.Errorf("expected valid output, got: %s", output.String())
+				}
+			} else {
+				if err == nil {
+					subT.Fatal("expected an error")
+				}
+				if !strings.Contains(output.String(), tt.expSummary) {
+					subT.Errorf("expected output containing %q, got: %s", tt.expSummary, output.String())
+				}
+			}
+
+			output.Reset()
+			err = v.Execute(Args{"-f", filePath, "-format", "json"}, nil, nil)
+			if (err == nil) != (tt.expSummary == "") {
+				subT.Errorf("unexpected json mode error: %v", err)
+			}
+
+			result := &verifyResult{}
+			if err = json.Unmarshal(output.Bytes(), result); err != nil {
+				subT.Fatal(err)
+			}
+
+			if result.Valid != (tt.expSummary == "") {
+				subT.Errorf("expected valid: %t, got: %t", tt.expSummary == "", result.Valid)
+			}
+
+			if tt.expSummary == "" {
+				if len(result.Diagnostics) != 0 {
+					subT.Errorf("expected no diagnostics, got: %d", len(result.Diagnostics))
+				}
+				return
+			}
+
+			if len(result.Diagnostics) != 1 {
+				subT.Fatalf("expected one diagnostic, got: %d", len(result.Diagnostics))
+			}
+
+			diag := result.Diagnostics[0]
+			if diag.Severity != "error" || diag.Summary != tt.expSummary {
+				subT.Errorf("unexpected diagnostic: %#v", diag)
+			}
+
+			if tt.expLine == 0 {
+				if diag.Range != nil {
+					subT.Errorf("expected no range, got: %#v", diag.Range)
+				}
+				return
+			}
+
+			if diag.Range == nil {
+				subT.Fatal("expected a range")
+			}
+			if diag.Range.Filename != filePath || diag.Range.Start.Line != tt.expLine {
+				subT.Errorf("expected range %s:%d, got: %s:%d", filePath, tt.expLine, diag.Range.Filename, diag.Range.Start.Line)
+			}
+		})
+	}
+
+	v := NewVerify(context.Background())
+	if err = v.Execute(Args{"-format", "xml"}, nil, nil); err == nil || !strings.Contains(err.Error(), "invalid format") {
+		t.Errorf("expected invalid format error, got: %v", err)
+	}
+}
+
+func TestVerify_Files(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err = os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		filepath.Join(dir, "couper.hcl"):   "server \"a\" {\n  hosts = [\n}\n",
+		filepath.Join(confDir, "b.hcl"):    "server \"b\" {\n  endpoint \"/\" {\n    response { status = }\n  }\n}\n",
+		filepath.Join(confDir, "c_ok.hcl"): "server \"c\" {}\n",
+	} {
+		if err = os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	output := &bytes.Buffer{}
+	v := NewVerify(context.Background())
+	v.output = output
+
+	if err = v.Execute(Args{"-f", filepath.Join(dir, "couper.hcl"), "-d", confDir}, nil, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// the source lines of both files are part of the output
+	for _, expLine := range []string{"couper.hcl line 3", "conf.d/b.hcl line 3", "response { status = }"} {
+		if !strings.Contains(output.String(), expLine) {
+			t.Errorf("expected output containing %q, got: %s", expLine, output.String())
+		}
+	}
+
+	output.Reset()
+	if err = v.Execute(Args{"-f", filepath.Join(dir, "couper.hcl"), "-d", confDir, "-format", "json"}, nil, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	result := &verifyResult{}
+	if err = json.Unmarshal(output.Bytes(), result); err != nil {
+		t.Fatal(err)
+	}
+
+	var filenames []string
+	for _, diag := range result.Diagnostics {
+		if diag.Range != nil {
+			filenames = append(filenames, diag.Range.Filename)
+		}
+	}
+	expFilenames := []string{filepath.Join(dir, "couper.hcl"), filepath.Join(confDir, "b.hcl")}
+	if strings.Join(filenames, ",") != strings.Join(expFilenames, ",") {
+		t.Errorf("expected diagnostics of %v, got: %v", expFilenames, filenames)
+	}
+}
+
+func TestVerify_ErrorCause(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	_, currFile, _, _ := runtime.Caller(0)
+	filePath := filepath.Join(filepath.Dir(currFile), "testdata", "verify", "06_key_file.hcl")
+
+	output := &bytes.Buffer{}
+	v := NewVerify(context.Background())
+	v.output = output
+
+	if err = v.Execute(Args{"-f", filePath, "-format", "json"}, nil, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	result := &verifyResult{}
+	if err = json.Unmarshal(output.Bytes(), result); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Diagnostics) != 1 {
+		t.Fatalf("expected one diagnostic, got: %d", len(result.Diagnostics))
+	}
+
+	summary := result.Diagnostics[0].Summary
+	for _, exp := range []string{"configuration error", "token", "jwt key: read error", "missing.pem"} {
+		if !strings.Contains(summary, exp) {
+			t.Errorf("expected summary containing %q, got: %q", exp, summary)
+		}
+	}
+}
@@ -13,16 +13,34 @@ var _ Body = &BasicAuth{}
 // BasicAuth represents the "basic_auth" config block
 type BasicAuth struct {
 	AccessControlSetter
-	File  string `hcl:"htpasswd_file,optional"`
-	Name  string `hcl:"name,label"`
-	User  string `hcl:"user,optional"`
-	Pass  string `hcl:"password,optional"`
-	Realm string `hcl:"realm,optional"`
+	File  string         `hcl:"htpasswd_file,optional"`
+	LDAP  *BasicAuthLDAP `hcl:"ldap,block"`
+	Name  string         `hcl:"name,label"`
+	User  string         `hcl:"user,optional"`
+	Pass  string         `hcl:"password,optional"`
+	Realm string         `hcl:"realm,optional"`
 
 	// Internally used for 'error_handler'.
 	Remain hcl.Body `hcl:",remain"`
 }
 
+// BasicAuthLDAP represents the "ldap" block of a "basic_auth" config block.
+type BasicAuthLDAP struct {
+	BindDN            string              `hcl:"bind_dn,optional"`
+	BindDNTemplate    string              `hcl:"bind_dn_template,optional"`
+	BindPassword      string              `hcl:"bind_password,optional"`
+	CACertificateFile string              `hcl:"ca_certificate_file,optional"`
+	CacheTTL          string              `hcl:"cache_ttl,optional"`
+	GroupAttribute    string              `hcl:"group_attribute,optional"`
+	RequiredGroups    []string            `hcl:"required_groups,optional"`
+	RoleMap           map[string][]string `hcl:"beta_role_map,optional"`
+	SearchBaseDN      string              `hcl:"search_base_dn,optional"`
+	SearchFilter      string              `hcl:"search_filter,optional"`
+	StartTLS          bool                `hcl:"start_tls,optional"`
+	Timeout           string              `hcl:"timeout,optional"`
+	URL               string              `hcl:"url"`
+}
+
 // HCLBody implements the <Inline> interface. Internally used for 'error_handler'.
 func (b *BasicAuth) HCLBody() hcl.Body {
 	return b.Remain
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Internally used for 'error_handler'.
+var _ Body = &AnyOf{}
+
+// AllOf represents the "all_of" config block which requires
+// every referenced access control to grant access.
+type AllOf struct {
+	AccessControl []string `hcl:"access_control"`
+	Name          string   `hcl:"name,label"`
+}
+
+// AnyOf represents the "any_of" config block which requires
+// at least one referenced access control to grant access.
+type AnyOf struct {
+	AccessControlSetter
+	AccessControl []string `hcl:"access_control"`
+	Name          string   `hcl:"name,label"`
+
+	// Internally used for 'error_handler'.
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (a *AnyOf) HCLBody() hcl.Body {
+	return a.Remain
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
@@ -19,6 +20,8 @@ type JWT struct {
 	Claims             Claims              `hcl:"claims,optional"`
 	ClaimsRequired     []string            `hcl:"required_claims,optional"`
 	Cookie             string              `hcl:"cookie,optional"`
+	DPoP               bool                `hcl:"dpop,optional"`
+	DPoPMaxAge         string              `hcl:"dpop_max_age,optional"`
 	Header             string              `hcl:"header,optional"`
 	JWKsURL            string              `hcl:"jwks_url,optional"`
 	JWKsTTL            string              `hcl:"jwks_ttl,optional"`
@@ -28,12 +31,15 @@ type JWT struct {
 	Name               string              `hcl:"name,label"`
 	PostParam          string              `hcl:"post_param,optional"`
 	QueryParam         string              `hcl:"query_param,optional"`
+	RevocationURL      string              `hcl:"revocation_url,optional"`
+	RevocationTTL      string              `hcl:"revocation_ttl,optional"`
 	RoleClaim          string              `hcl:"beta_role_claim,optional"`
 	RoleMap            map[string][]string `hcl:"beta_role_map,optional"`
 	ScopeClaim         string              `hcl:"beta_scope_claim,optional"`
 	SignatureAlgorithm string              `hcl:"signature_algorithm,optional"`
 	SigningKey         string              `hcl:"signing_key,optional"`
 	SigningKeyFile     string              `hcl:"signing_key_file,optional"`
+	SigningKeyID       string              `hcl:"signing_key_id,optional"`
 	SigningTTL         string              `hcl:"signing_ttl,optional"`
 
 	// Internally used
@@ -65,8 +71,8 @@ func (j *JWT) Check() error {
 			}
 		}
 	} else {
-		if j.JWKSBackendRef != "" || j.JWKSBackendBody != nil {
-			return errors.New("backend not needed without jwks_url")
+		if (j.JWKSBackendRef != "" || j.JWKSBackendBody != nil) && !j.FetchesRevocationList() {
+			return errors.New("backend not needed without jwks_url or revocation_url")
 		}
 
 		if j.SignatureAlgorithm == "" {
@@ -77,6 +83,11 @@ func (j *JWT) Check() error {
 	return nil
 }
 
+// FetchesRevocationList returns true if the revocation list is loaded via http(s).
+func (j *JWT) FetchesRevocationList() bool {
+	return strings.HasPrefix(j.RevocationURL, "http:") || strings.HasPrefix(j.RevocationURL, "https:")
+}
+
 // Reference implements the <BackendReference> interface.
 func (j *JWT) Reference() string {
 	return j.JWKSBackendRef
@@ -16,17 +16,19 @@ var (
 // OAuth2AC represents an oauth2 block for an OAuth2 client using the authorization code flow.
 type OAuth2AC struct {
 	AccessControlSetter
-	AuthorizationEndpoint   string   `hcl:"authorization_endpoint"`
-	BackendName             string   `hcl:"backend,optional"`
-	ClientID                string   `hcl:"client_id"`
-	ClientSecret            string   `hcl:"client_secret"`
-	GrantType               string   `hcl:"grant_type"`
-	Name                    string   `hcl:"name,label"`
-	Remain                  hcl.Body `hcl:",remain"`
-	Scope                   *string  `hcl:"scope,optional"`
-	TokenEndpoint           string   `hcl:"token_endpoint"`
-	TokenEndpointAuthMethod *string  `hcl:"token_endpoint_auth_method,optional"`
-	VerifierMethod          string   `hcl:"verifier_method"`
+	AuthorizationEndpoint   string                  `hcl:"authorization_endpoint"`
+	BackendName             string                  `hcl:"backend,optional"`
+	ClientAssertionProfile  *ClientAssertionProfile `hcl:"jwt_signing_profile,block"`
+	ClientID                string                  `hcl:"client_id"`
+	ClientSecret            string                  `hcl:"client_secret,optional"`
+	GrantType               string                  `hcl:"grant_type"`
+	Name                    string                  `hcl:"name,label"`
+	Remain                  hcl.Body                `hcl:",remain"`
+	Scope                   *string                 `hcl:"scope,optional"`
+	Session                 *Session                `hcl:"session,block"`
+	TokenEndpoint           string                  `hcl:"token_endpoint"`
+	TokenEndpointAuthMethod *string                 `hcl:"token_endpoint_auth_method,optional"`
+	VerifierMethod          string                  `hcl:"verifier_method"`
 
 	// internally used
 	Backend     hcl.Body
@@ -74,6 +76,10 @@ func (oa OAuth2AC) GetName() string {
 	return oa.Name
 }
 
+func (oa OAuth2AC) GetClientAssertionProfile() *ClientAssertionProfile {
+	return oa.ClientAssertionProfile
+}
+
 func (oa OAuth2AC) GetClientID() string {
 	return oa.ClientID
 }
@@ -1,6 +1,10 @@
 package config
 
-import "github.com/hashicorp/hcl/v2"
+import (
+	"crypto/tls"
+
+	"github.com/hashicorp/hcl/v2"
+)
 
 // Internally used for 'error_handler'.
 var _ Body = &SAML{}
@@ -8,14 +12,18 @@ var _ Body = &SAML{}
 // SAML represents the <SAML> object.
 type SAML struct {
 	AccessControlSetter
-	ArrayAttributes []string `hcl:"array_attributes,optional"`
-	IdpMetadataFile string   `hcl:"idp_metadata_file"`
-	Name            string   `hcl:"name,label"`
-	SpAcsUrl        string   `hcl:"sp_acs_url"`
-	SpEntityId      string   `hcl:"sp_entity_id"`
+	ArrayAttributes   []string `hcl:"array_attributes,optional"`
+	IdpMetadataFile   string   `hcl:"idp_metadata_file"`
+	Name              string   `hcl:"name,label"`
+	SpAcsUrl          string   `hcl:"sp_acs_url"`
+	SpCertificateFile string   `hcl:"sp_certificate_file,optional"`
+	SpEntityId        string   `hcl:"sp_entity_id"`
+	SpPrivateKeyFile  string   `hcl:"sp_private_key_file,optional"`
+	SpSloUrl          string   `hcl:"sp_slo_url,optional"`
 
 	// internally used
 	MetadataBytes []byte
+	SpKeyPair     *tls.Certificate
 
 	// Internally used for 'error_handler'.
 	Remain hcl.Body `hcl:",remain"`
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+// Internally used for 'error_handler'.
+var _ Body = &Signature{}
+
+// SignatureTimestampSchema is used to decode the optional timestamp expression
+// which must not be evaluated if unset.
+var SignatureTimestampSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "timestamp"},
+	},
+}
+
+// Signature represents the "signature" config block
+type Signature struct {
+	AccessControlSetter
+	Algorithm          string         `hcl:"algorithm,optional"`
+	CanonicalString    hcl.Expression `hcl:"canonical_string,optional"`
+	Encoding           string         `hcl:"encoding,optional"`
+	Header             string         `hcl:"header"`
+	HeaderParam        string         `hcl:"header_param,optional"`
+	Key                string         `hcl:"key,optional"`
+	KeyFile            string         `hcl:"key_file,optional"`
+	Name               string         `hcl:"name,label"`
+	Prefix             string         `hcl:"prefix,optional"`
+	RequestBodyLimit   string         `hcl:"request_body_limit,optional"`
+	TimestampTolerance string         `hcl:"timestamp_tolerance,optional"`
+
+	// Internally used for 'error_handler' and 'timestamp'.
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (s *Signature) HCLBody() hcl.Body {
+	return s.Remain
+}
@@ -0,0 +1,20 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// Internally used for 'error_handler'.
+var _ Body = &Authorization{}
+
+// Authorization represents the <Authorization> object.
+type Authorization struct {
+	AccessControlSetter
+	Rules hcl.Expression `hcl:"rules"`
+
+	// Internally used for 'error_handler'.
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (a *Authorization) HCLBody() hcl.Body {
+	return a.Remain
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/hashicorp/hcl/v2"
+)
+
+var AWSSigV4BlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type: "aws_sigv4",
+		},
+	},
+}
+
+// AWSSigV4 represents the aws_sigv4 block in a backend block.
+type AWSSigV4 struct {
+	AccessKeyID     string `hcl:"access_key_id"`
+	PayloadSigning  string `hcl:"payload_signing,optional"`
+	Region          string `hcl:"region"`
+	SecretAccessKey string `hcl:"secret_access_key"`
+	Service         string `hcl:"service"`
+	SessionToken    string `hcl:"session_token,optional"`
+}
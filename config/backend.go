@@ -16,6 +16,8 @@ var (
 
 // Backend represents the <Backend> object.
 type Backend struct {
+	ClientCertificateFile  string   `hcl:"client_certificate_file,optional"`
+	ClientPrivateKeyFile   string   `hcl:"client_private_key_file,optional"`
 	ConnectTimeout         string   `hcl:"connect_timeout,optional"`
 	DisableCertValidation  bool     `hcl:"disable_certificate_validation,optional"`
 	DisableConnectionReuse bool     `hcl:"disable_connection_reuse,optional"`
@@ -28,7 +30,8 @@ type Backend struct {
 	Timeout                string   `hcl:"timeout,optional"`
 
 	// explicit configuration on load
-	OAuth2 *OAuth2ReqAuth
+	AWSSigV4 *AWSSigV4
+	OAuth2   *OAuth2ReqAuth
 }
 
 // Reference implements the <BackendReference> interface.
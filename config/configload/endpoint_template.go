@@ -0,0 +1,414 @@
+package configload
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const (
+	endpoint         = "endpoint"
+	endpointTemplate = "endpoint_template"
+	templateAttr     = "template"
+	templateParams   = "template_params"
+)
+
+var endpointTemplateBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       endpointTemplate,
+			LabelNames: []string{nameLabel},
+		},
+	},
+}
+
+// endpointTemplates holds the bodies of the endpoint_template definitions by their name.
+type endpointTemplates map[string]*hclsyntax.Body
+
+func newEndpointTemplates(blocks hcl.Blocks) (endpointTemplates, error) {
+	templates := make(endpointTemplates)
+	for _, block := range blocks {
+		name := block.Labels[0]
+		if _, exist := templates[name]; exist {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("duplicate endpoint_template name: %q", name),
+				Subject:  &block.LabelRanges[0],
+			}}
+		}
+
+		body, ok := block.Body.(*hclsyntax.Body)
+		if !ok {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "endpoint_template: only HCL native syntax is supported",
+				Subject:  &block.DefRange,
+			}}
+		}
+		templates[name] = body
+	}
+	return templates, nil
+}
+
+// expandServer returns the given server body with all endpoint blocks of the server and
+// its api blocks which reference an endpoint_template merged with the template body.
+func (t endpointTemplates) expandServer(body hcl.Body) (hcl.Body, error) {
+	serverBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return body, nil
+	}
+
+	result := *serverBody
+	result.Blocks = make(hclsyntax.Blocks, 0, len(serverBody.Blocks))
+	for _, block := range serverBody.Blocks {
+		switch block.Type {
+		case api:
+			apiBlock := *block
+			apiBody := *block.Body
+			apiBody.Blocks = make(hclsyntax.Blocks, 0, len(block.Body.Blocks))
+			for _, child := range block.Body.Blocks {
+				if child.Type == endpoint {
+					expanded, diags := t.expandEndpoint(child)
+					if diags.HasErrors() {
+						return nil, diags
+					}
+					child = expanded
+				}
+				apiBody.Blocks = append(apiBody.Blocks, child)
+			}
+			apiBlock.Body = &apiBody
+			block = &apiBlock
+		case endpoint:
+			expanded, diags := t.expandEndpoint(block)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			block = expanded
+		}
+		result.Blocks = append(result.Blocks, block)
+	}
+	return &result, nil
+}
+
+func (t endpointTemplates) expandEndpoint(block *hclsyntax.Block) (*hclsyntax.Block, hcl.Diagnostics) {
+	body, diags := t.expand(block.Body, nil)
+	if diags.HasErrors() || body == block.Body {
+		return block, diags
+	}
+
+	result := *block
+	result.Body = body
+	return &result, nil
+}
+
+// expand merges the given body with its referenced endpoint_template whose template_params
+// references are replaced with the given parameter expressions. Templates may reference other
+// templates. Attributes and blocks with the same type and labels of the given body take precedence.
+func (t endpointTemplates) expand(body *hclsyntax.Body, seen []string) (*hclsyntax.Body, hcl.Diagnostics) {
+	attr, exist := body.Attributes[templateAttr]
+	if !exist {
+		if paramsAttr, paramsExist := body.Attributes[templateParams]; paramsExist {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "template_params requires a template attribute",
+				Subject:  &paramsAttr.NameRange,
+			}}
+		}
+		return body, nil
+	}
+
+	nameVal, diags := attr.Expr.Value(envContext)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if nameVal.IsNull() || !nameVal.IsKnown() || nameVal.Type() != cty.String {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "template must be a string",
+			Subject:  attr.Expr.Range().Ptr(),
+		}}
+	}
+
+	name := nameVal.AsString()
+	templateBody, exist := t[name]
+	if !exist {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("endpoint_template %q is not defined", name),
+			Subject:  attr.Expr.Range().Ptr(),
+		}}
+	}
+
+	for _, s := range seen {
+		if s == name {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  fmt.Sprintf("endpoint_template %q references itself", name),
+				Subject:  attr.Expr.Range().Ptr(),
+			}}
+		}
+	}
+
+	params, diags := newTemplateParams(body.Attributes[templateParams])
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	instance, diags := substituteBody(templateBody, name, params)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	instance, diags = t.expand(instance, append(seen, name))
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return overrideBody(instance, body), nil
+}
+
+// newTemplateParams returns the parameter expressions of the given template_params object by their name.
+func newTemplateParams(attr *hclsyntax.Attribute) (map[string]hclsyntax.Expression, hcl.Diagnostics) {
+	params := make(map[string]hclsyntax.Expression)
+	if attr == nil {
+		return params, nil
+	}
+
+	obj, ok := attr.Expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "template_params must be an object",
+			Subject:  attr.Expr.Range().Ptr(),
+		}}
+	}
+
+	for _, item := range obj.Items {
+		key, diags := item.KeyExpr.Value(envContext)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if key.IsNull() || !key.IsKnown() || key.Type() != cty.String {
+			return nil, hcl.Diagnostics{&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "template_params keys must be strings",
+				Subject:  item.KeyExpr.Range().Ptr(),
+			}}
+		}
+		params[key.AsString()] = item.ValueExpr
+	}
+	return params, nil
+}
+
+// overrideBody returns the base body with the attributes and blocks of the given body. Blocks of
+// the base body with the same type and labels are replaced. The template attributes are omitted.
+func overrideBody(base, body *hclsyntax.Body) *hclsyntax.Body {
+	result := *body
+	result.Attributes = make(hclsyntax.Attributes)
+	for name, attr := range base.Attributes {
+		result.Attributes[name] = attr
+	}
+	for name, attr := range body.Attributes {
+		if name == templateAttr || name == templateParams {
+			continue
+		}
+		result.Attributes[name] = attr
+	}
+
+	result.Blocks = make(hclsyntax.Blocks, 0, len(base.Blocks)+len(body.Blocks))
+	for _, block := range base.Blocks {
+		if indexOfBlock(body.Blocks, block) < 0 {
+			result.Blocks = append(result.Blocks, block)
+		}
+	}
+	result.Blocks = append(result.Blocks, body.Blocks...)
+	return &result
+}
+
+// substituteBody returns a copy of the given template body whose template_params
+// references are replaced with the given parameter expressions.
+func substituteBody(body *hclsyntax.Body, name string, params map[string]hclsyntax.Expression) (*hclsyntax.Body, hcl.Diagnostics) {
+	var diags hcl.Diagnostics
+
+	result := *body
+	result.Attributes = make(hclsyntax.Attributes)
+	for attrName, attr := range body.Attributes {
+		substituted := *attr
+		var exprDiags hcl.Diagnostics
+		substituted.Expr, exprDiags = substituteExpr(attr.Expr, name, params)
+		diags = append(diags, exprDiags...)
+		result.Attributes[attrName] = &substituted
+	}
+
+	result.Blocks = make(hclsyntax.Blocks, 0, len(body.Blocks))
+	for _, block := range body.Blocks {
+		substituted := *block
+		var blockDiags hcl.Diagnostics
+		substituted.Body, blockDiags = substituteBody(block.Body, name, params)
+		diags = append(diags, blockDiags...)
+		result.Blocks = append(result.Blocks, &substituted)
+	}
+	return &result, diags
+}
+
+// substituteExpr returns the given expression or a copy with replaced template_params references.
+func substituteExpr(expr hclsyntax.Expression, name string, params map[string]hclsyntax.Expression) (hclsyntax.Expression, hcl.Diagnostics) {
+	if expr == nil || !referencesTemplateParams(expr) {
+		return expr, nil
+	}
+
+	var diags hcl.Diagnostics
+	sub := func(e hclsyntax.Expression) hclsyntax.Expression {
+		result, d := substituteExpr(e, name, params)
+		diags = append(diags, d...)
+		return result
+	}
+
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		return substituteTraversal(e, name, params)
+	case *hclsyntax.RelativeTraversalExpr:
+		result := *e
+		result.Source = sub(e.Source)
+		return &result, diags
+	case *hclsyntax.FunctionCallExpr:
+		result := *e
+		result.Args = make([]hclsyntax.Expression, len(e.Args))
+		for i, arg := range e.Args {
+			result.Args[i] = sub(arg)
+		}
+		return &result, diags
+	case *hclsyntax.ConditionalExpr:
+		result := *e
+		result.Condition = sub(e.Condition)
+		result.TrueResult = sub(e.TrueResult)
+		result.FalseResult = sub(e.FalseResult)
+		return &result, diags
+	case *hclsyntax.IndexExpr:
+		result := *e
+		result.Collection = sub(e.Collection)
+		result.Key = sub(e.Key)
+		return &result, diags
+	case *hclsyntax.TupleConsExpr:
+		result := *e
+		result.Exprs = make([]hclsyntax.Expression, len(e.Exprs))
+		for i, item := range e.Exprs {
+			result.Exprs[i] = sub(item)
+		}
+		return &result, diags
+	case *hclsyntax.ObjectConsExpr:
+		result := *e
+		result.Items = make([]hclsyntax.ObjectConsItem, len(e.Items))
+		for i, item := range e.Items {
+			result.Items[i] = hclsyntax.ObjectConsItem{
+				KeyExpr:   sub(item.KeyExpr),
+				ValueExpr: sub(item.ValueExpr),
+			}
+		}
+		return &result, diags
+	case *hclsyntax.ObjectConsKeyExpr:
+		result := *e
+		result.Wrapped = sub(e.Wrapped)
+		return &result, diags
+	case *hclsyntax.ForExpr:
+		result := *e
+		result.CollExpr = sub(e.CollExpr)
+		result.KeyExpr = sub(e.KeyExpr)
+		result.ValExpr = sub(e.ValExpr)
+		result.CondExpr = sub(e.CondExpr)
+		return &result, diags
+	case *hclsyntax.SplatExpr:
+		result := *e
+		result.Source = sub(e.Source)
+		result.Each = sub(e.Each)
+		return &result, diags
+	case *hclsyntax.BinaryOpExpr:
+		result := *e
+		result.LHS = sub(e.LHS)
+		result.RHS = sub(e.RHS)
+		return &result, diags
+	case *hclsyntax.UnaryOpExpr:
+		result := *e
+		result.Val = sub(e.Val)
+		return &result, diags
+	case *hclsyntax.TemplateExpr:
+		result := *e
+		result.Parts = make([]hclsyntax.Expression, len(e.Parts))
+		for i, part := range e.Parts {
+			result.Parts[i] = sub(part)
+		}
+		return &result, diags
+	case *hclsyntax.TemplateJoinExpr:
+		result := *e
+		result.Tuple = sub(e.Tuple)
+		return &result, diags
+	case *hclsyntax.TemplateWrapExpr:
+		result := *e
+		result.Wrapped = sub(e.Wrapped)
+		return &result, diags
+	case *hclsyntax.ParenthesesExpr:
+		result := *e
+		result.Expression = sub(e.Expression)
+		return &result, diags
+	}
+
+	return expr, hcl.Diagnostics{&hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  "unsupported expression with template_params reference",
+		Subject:  expr.Range().Ptr(),
+	}}
+}
+
+// substituteTraversal replaces a template_params.<name> traversal with the expression of the parameter.
+func substituteTraversal(expr *hclsyntax.ScopeTraversalExpr, name string, params map[string]hclsyntax.Expression) (hclsyntax.Expression, hcl.Diagnostics) {
+	var key string
+	if len(expr.Traversal) > 1 {
+		switch step := expr.Traversal[1].(type) {
+		case hcl.TraverseAttr:
+			key = step.Name
+		case hcl.TraverseIndex:
+			if step.Key.Type() == cty.String && step.Key.IsKnown() && !step.Key.IsNull() {
+				key = step.Key.AsString()
+			}
+		}
+	}
+
+	if key == "" {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  "template_params must be referenced by a parameter name, e.g. template_params.path",
+			Subject:  &expr.SrcRange,
+		}}
+	}
+
+	param, exist := params[key]
+	if !exist {
+		return nil, hcl.Diagnostics{&hcl.Diagnostic{
+			Severity: hcl.DiagError,
+			Summary:  fmt.Sprintf("missing template parameter: %q", key),
+			Detail:   fmt.Sprintf("endpoint_template %q requires the template_params attribute %q", name, key),
+			Subject:  &expr.SrcRange,
+		}}
+	}
+
+	if len(expr.Traversal) == 2 {
+		return param, nil
+	}
+
+	return &hclsyntax.RelativeTraversalExpr{
+		Source:    param,
+		Traversal: expr.Traversal[2:],
+		SrcRange:  expr.SrcRange,
+	}, nil
+}
+
+func referencesTemplateParams(expr hclsyntax.Expression) bool {
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() == templateParams {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package configload_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+
+	"github.com/avenga/couper/config/configload"
+)
+
+func TestEndpointTemplates_Override(t *testing.T) {
+	couperConfig, err := configload.LoadBytes([]byte(`
+server "templates" {
+  endpoint "/" {
+    template = "base"
+    error_file = "local.html"
+    response {
+      status = 204
+    }
+  }
+}
+definitions {
+  endpoint_template "base" {
+    error_file = "template.html"
+    request_body_limit = "1MiB"
+    response {
+      status = 200
+    }
+  }
+}`), "couper.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ep := couperConfig.Servers[0].Endpoints[0]
+	if ep.ErrorFile != "local.html" || ep.RequestBodyLimit != "1MiB" {
+		t.Errorf("expected local error_file and template request_body_limit, got: %q, %q", ep.ErrorFile, ep.RequestBodyLimit)
+	}
+
+	if ep.Response == nil {
+		t.Fatal("expected a response")
+	}
+	attrs, _ := ep.Response.Remain.JustAttributes()
+	if status, _ := attrs["status"].Expr.Value(nil); status.AsBigFloat().String() != "204" {
+		t.Errorf("expected the local response block, got status: %s", status.GoString())
+	}
+}
+
+func TestEndpointTemplates_Errors(t *testing.T) {
+	type testCase struct {
+		name       string
+		endpoint   string
+		templates  string
+		expSummary string
+		expRange   string
+	}
+
+	for _, tc := range []testCase{
+		{"undefined", `template = "missing"`, ``,
+			`endpoint_template "missing" is not defined`, "couper.hcl:4,16-25"},
+		{"params without template", `template_params = { a = 1 }`, ``,
+			"template_params requires a template attribute", "couper.hcl:4,5-20"},
+		{"params not an object", `
+    template = "a"
+    template_params = "a"`, `
+  endpoint_template "a" {
+    response { status = 204 }
+  }`, "template_params must be an object", "couper.hcl:6,23-26"},
+		{"missing parameter", `template = "a"`, `
+  endpoint_template "a" {
+    response { status = template_params.status }
+  }`, `missing template parameter: "status"`, "couper.hcl:10,25-47"},
+		{"self reference", `template = "a"`, `
+  endpoint_template "a" {
+    template = "b"
+  }
+  endpoint_template "b" {
+    template = "a"
+  }`, `endpoint_template "a" references itself`, "couper.hcl:13,16-19"},
+		{"duplicate", `template = "a"`, `
+  endpoint_template "a" {}
+  endpoint_template "a" {}`, `duplicate endpoint_template name: "a"`, "couper.hcl:10,21-24"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			src := `
+server "templates" {
+  endpoint "/" {
+    ` + tc.endpoint + `
+  }
+}
+definitions {
+` + tc.templates + `
+}`
+			_, err := configload.LoadBytes([]byte(src), "couper.hcl")
+			diags, ok := err.(hcl.Diagnostics)
+			if !ok || len(diags) != 1 {
+				subT.Fatalf("expected one diagnostic, got: %v", err)
+			}
+
+			if diags[0].Summary != tc.expSummary {
+				subT.Errorf("expected summary %q, got: %q", tc.expSummary, diags[0].Summary)
+			}
+			if diags[0].Subject == nil || diags[0].Subject.String() != tc.expRange {
+				subT.Errorf("expected range %q, got: %v", tc.expRange, diags[0].Subject)
+			}
+		})
+	}
+}
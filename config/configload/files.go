@@ -0,0 +1,345 @@
+package configload
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/parser"
+	"github.com/avenga/couper/eval/lib"
+)
+
+const (
+	api      = "api"
+	defaults = "defaults"
+)
+
+// LoadFiles loads and merges the given configuration files and all *.hcl files of the given
+// directories in lexical order. The working directory is set to the directory of the first file.
+// The server blocks with the same label, the api blocks with the same base_path within those and the
+// definitions, defaults and settings blocks are combined; duplicate attributes or blocks are reported.
+func LoadFiles(filePaths, dirPaths []string) (*config.Couper, error) {
+	files, err := ConfigFiles(filePaths, dirPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 1 {
+		couperConfig, loadErr := LoadFile(files[0])
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		couperConfig.Files = files
+		return couperConfig, nil
+	}
+
+	wd, err := SetWorkingDirectory(files[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bodies     []*hclsyntax.Body
+		parseDiags hcl.Diagnostics
+		srcs       [][]byte
+	)
+	// Parse all files first to report the syntax errors of each one.
+	for _, file := range files {
+		src, readErr := os.ReadFile(file)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", readErr)
+		}
+
+		filename, relErr := filepath.Rel(wd, file)
+		if relErr != nil {
+			filename = file
+		}
+
+		body, diags := parser.Load(src, filename)
+		if diags.HasErrors() {
+			parseDiags = parseDiags.Extend(diags)
+			continue
+		}
+
+		syntaxBody, ok := body.(*hclsyntax.Body)
+		if !ok {
+			return nil, fmt.Errorf("failed to load configuration: %s: only HCL native syntax files can be merged", filename)
+		}
+		if fileDir := filepath.Dir(file); fileDir != filepath.Dir(files[0]) {
+			dir, dirErr := filepath.Rel(filepath.Dir(files[0]), fileDir)
+			if dirErr != nil {
+				dir = fileDir
+			}
+			resolveRelativePaths(syntaxBody, dir)
+		}
+
+		bodies = append(bodies, syntaxBody)
+		srcs = append(srcs, src)
+	}
+
+	if parseDiags.HasErrors() {
+		return nil, parseDiags
+	}
+
+	body, diags := mergeFileBodies(bodies)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	couperConfig, err := LoadConfig(body, bytes.Join(srcs, []byte("\n")), filepath.Base(files[0]))
+	if err != nil {
+		return nil, err
+	}
+	couperConfig.Files = files
+	return couperConfig, nil
+}
+
+// resolveRelativePaths joins the given directory with the relative paths of the file attributes
+// and template_file() arguments of a body, so they are resolved against the directory of their file
+// instead of the working directory. Only string literals are known on load and get resolved.
+func resolveRelativePaths(body *hclsyntax.Body, dir string) {
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch n := node.(type) {
+		case *hclsyntax.Attribute:
+			if n.Name == "document_root" || n.Name == "file" || strings.HasSuffix(n.Name, "_file") {
+				n.Expr = resolveRelativePath(n.Expr, dir)
+			}
+		case *hclsyntax.FunctionCallExpr:
+			if n.Name == lib.FnTemplateFile && len(n.Args) > 0 {
+				n.Args[0] = resolveRelativePath(n.Args[0], dir)
+			}
+		}
+		return nil
+	})
+}
+
+func resolveRelativePath(expr hclsyntax.Expression, dir string) hclsyntax.Expression {
+	var val cty.Value
+	switch e := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		val = e.Val
+	case *hclsyntax.TemplateExpr:
+		if !e.IsStringLiteral() {
+			return expr
+		}
+		val = e.Parts[0].(*hclsyntax.LiteralValueExpr).Val
+	default:
+		return expr
+	}
+
+	if val.Type() != cty.String || val.IsNull() || val.AsString() == "" || filepath.IsAbs(val.AsString()) {
+		return expr
+	}
+	return &hclsyntax.LiteralValueExpr{
+		Val:      cty.StringVal(filepath.Join(dir, val.AsString())),
+		SrcRange: expr.Range(),
+	}
+}
+
+// ConfigFiles returns the absolute paths of the given files followed by the *.hcl files of the
+// given directories in lexical order. Duplicates are omitted.
+func ConfigFiles(filePaths, dirPaths []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]struct{})
+	add := func(path string) error {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if _, exist := seen[absPath]; !exist {
+			seen[absPath] = struct{}{}
+			files = append(files, absPath)
+		}
+		return nil
+	}
+
+	for _, file := range filePaths {
+		if err := add(file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dir := range dirPaths {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("failed to load configuration directory: %s is not a directory", dir)
+		}
+
+		matches, err := filepath.Glob(filepath.Join(dir, "*.hcl"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range matches { // sorted by filepath.Glob
+			if err = add(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("failed to load configuration: no configuration files found")
+	}
+	return files, nil
+}
+
+// mergeFileBodies combines the blocks of the given file bodies.
+func mergeFileBodies(bodies []*hclsyntax.Body) (*hclsyntax.Body, hcl.Diagnostics) {
+	result := &hclsyntax.Body{
+		Attributes: make(hclsyntax.Attributes),
+		SrcRange:   bodies[0].SrcRange,
+		EndRange:   bodies[0].EndRange,
+	}
+
+	var diags hcl.Diagnostics
+	for _, body := range bodies {
+		diags = append(diags, mergeSyntaxAttributes(result.Attributes, body.Attributes)...)
+
+		for _, block := range body.Blocks {
+			switch block.Type {
+			case server:
+				result.Blocks, diags = mergeSyntaxBlock(result.Blocks, block, true, diags)
+			case definitions, defaults, settings:
+				result.Blocks, diags = mergeSyntaxBlock(result.Blocks, block, false, diags)
+			default:
+				result.Blocks = append(result.Blocks, block)
+			}
+		}
+	}
+	return result, diags
+}
+
+// mergeSyntaxBlock merges the given block into an existing one with the same type and labels or appends it.
+func mergeSyntaxBlock(blocks hclsyntax.Blocks, block *hclsyntax.Block, isServer bool, diags hcl.Diagnostics) (hclsyntax.Blocks, hcl.Diagnostics) {
+	idx := indexOfBlock(blocks, block)
+	if idx < 0 {
+		return append(blocks, block), diags
+	}
+
+	existing := blocks[idx]
+	merged := *existing
+	merged.Body = &hclsyntax.Body{
+		Attributes: make(hclsyntax.Attributes),
+		Blocks:     append(hclsyntax.Blocks{}, existing.Body.Blocks...),
+		SrcRange:   existing.Body.SrcRange,
+		EndRange:   existing.Body.EndRange,
+	}
+	for name, attr := range existing.Body.Attributes {
+		merged.Body.Attributes[name] = attr
+	}
+
+	attributes := block.Body.Attributes
+	if block.Type == api { // equal by definition
+		attributes = make(hclsyntax.Attributes)
+		for name, attr := range block.Body.Attributes {
+			if name != "base_path" {
+				attributes[name] = attr
+			}
+		}
+	}
+	diags = append(diags, mergeSyntaxAttributes(merged.Body.Attributes, attributes)...)
+
+	for _, child := range block.Body.Blocks {
+		// api blocks of a server with the same base_path are combined
+		if isServer && child.Type == api {
+			merged.Body.Blocks, diags = mergeSyntaxBlock(merged.Body.Blocks, child, false, diags)
+			continue
+		}
+
+		if i := indexOfBlock(merged.Body.Blocks, child); i >= 0 {
+			diags = append(diags, duplicateBlockDiagnostic(merged.Body.Blocks[i], child))
+			continue
+		}
+		merged.Body.Blocks = append(merged.Body.Blocks, child)
+	}
+
+	blocks[idx] = &merged
+	return blocks, diags
+}
+
+func indexOfBlock(blocks hclsyntax.Blocks, block *hclsyntax.Block) int {
+	for i, b := range blocks {
+		if b.Type != block.Type || !equalLabels(b.Labels, block.Labels) {
+			continue
+		}
+		if block.Type == api && apiBasePath(b) != apiBasePath(block) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// apiBasePath returns the static base_path of the given api block or an empty string.
+func apiBasePath(block *hclsyntax.Block) string {
+	attr, exist := block.Body.Attributes["base_path"]
+	if !exist {
+		return ""
+	}
+	val, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || val.IsNull() || !val.IsKnown() || val.Type() != cty.String {
+		return ""
+	}
+	return val.AsString()
+}
+
+func equalLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeSyntaxAttributes(left, right hclsyntax.Attributes) (diags hcl.Diagnostics) {
+	names := make([]string, 0, len(right))
+	for name := range right {
+		names = append(names, name)
+	}
+	sort.Strings(names) // deterministic diagnostics
+
+	for _, name := range names {
+		attr := right[name]
+		if existing, exist := left[name]; exist {
+			diags = diags.Append(&hcl.Diagnostic{
+				Severity: hcl.DiagError,
+				Summary:  "Duplicate argument",
+				Detail: fmt.Sprintf("Argument %q was already set at %s",
+					name, existing.NameRange.String()),
+				Subject: attr.NameRange.Ptr(),
+			})
+			continue
+		}
+		left[name] = attr
+	}
+	return diags
+}
+
+func duplicateBlockDiagnostic(existing, block *hclsyntax.Block) *hcl.Diagnostic {
+	name := block.Type
+	if len(block.Labels) > 0 {
+		name += fmt.Sprintf(" %q", strings.Join(block.Labels, " "))
+	}
+
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf("Duplicate %s block", block.Type),
+		Detail: fmt.Sprintf("Block %s was already defined at %s",
+			name, existing.DefRange().String()),
+		Subject: block.DefRange().Ptr(),
+	}
+}
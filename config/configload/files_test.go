@@ -0,0 +1,290 @@
+package configload_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/avenga/couper/config/configload"
+)
+
+func writeConfigFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadFiles_Merge(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfigFiles(t, dir, map[string]string{
+		"couper.hcl": `
+server "api" {
+  hosts = ["*:8080"]
+  api {
+    base_path = "/v1"
+    endpoint "/a" {
+      response { body = "a" }
+    }
+  }
+}
+settings {
+  log_level = "debug"
+}`,
+	})
+	writeConfigFiles(t, confDir, map[string]string{
+		"10_team_b.hcl": `
+server "api" {
+  api {
+    base_path = "/v1"
+    endpoint "/b" {
+      response { body = "b" }
+    }
+  }
+  api {
+    base_path = "/v2"
+    endpoint "/c" {
+      response { body = "c" }
+    }
+  }
+}
+definitions {
+  basic_auth "ba1" {
+    password = "secret"
+  }
+}`,
+		"20_team_c.hcl": `
+server "other" {
+  hosts = ["*:9090"]
+  endpoint "/d" {
+    response { body = "d" }
+  }
+}
+definitions {
+  basic_auth "ba2" {
+    password = "secret"
+  }
+}
+settings {
+  health_path = "/status"
+}`,
+		"ignored.txt": `invalid`,
+	})
+
+	couperConfig, err := configload.LoadFiles([]string{filepath.Join(dir, "couper.hcl")}, []string{confDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expFiles := []string{
+		filepath.Join(dir, "couper.hcl"),
+		filepath.Join(confDir, "10_team_b.hcl"),
+		filepath.Join(confDir, "20_team_c.hcl"),
+	}
+	if strings.Join(couperConfig.Files, ",") != strings.Join(expFiles, ",") {
+		t.Errorf("expected files %v, got: %v", expFiles, couperConfig.Files)
+	}
+
+	if len(couperConfig.Servers) != 2 {
+		t.Fatalf("expected two servers, got: %d", len(couperConfig.Servers))
+	}
+
+	apiServer := couperConfig.Servers[0]
+	if apiServer.Name != "api" || len(apiServer.APIs) != 2 {
+		t.Fatalf("expected server 'api' with two apis, got: %q with %d", apiServer.Name, len(apiServer.APIs))
+	}
+	if n := len(apiServer.APIs[0].Endpoints); apiServer.APIs[0].BasePath != "/v1" || n != 2 {
+		t.Errorf("expected api '/v1' with two endpoints, got: %q with %d", apiServer.APIs[0].BasePath, n)
+	}
+	if n := len(apiServer.APIs[1].Endpoints); apiServer.APIs[1].BasePath != "/v2" || n != 1 {
+		t.Errorf("expected api '/v2' with one endpoint, got: %q with %d", apiServer.APIs[1].BasePath, n)
+	}
+
+	if couperConfig.Servers[1].Name != "other" || len(couperConfig.Servers[1].Endpoints) != 1 {
+		t.Errorf("expected server 'other' with one endpoint")
+	}
+
+	if couperConfig.Definitions == nil || len(couperConfig.Definitions.BasicAuth) != 2 {
+		t.Errorf("expected two merged basic_auth definitions")
+	}
+
+	if couperConfig.Settings.LogLevel != "debug" || couperConfig.Settings.HealthPath != "/status" {
+		t.Errorf("expected merged settings, got: %q, %q", couperConfig.Settings.LogLevel, couperConfig.Settings.HealthPath)
+	}
+}
+
+func TestLoadFiles_Duplicates(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	type testCase struct {
+		name       string
+		second     string
+		expSummary string
+		expDetail  string
+		expRange   string
+	}
+
+	first := `
+server "api" {
+  api {
+    endpoint "/a" {
+      response { body = "a" }
+    }
+  }
+}
+definitions {
+  backend "be" {
+    origin = "http://localhost"
+  }
+}
+settings {
+  log_level = "debug"
+}`
+
+	for _, tc := range []testCase{
+		{"endpoint", `
+server "api" {
+  api {
+    endpoint "/a" {
+      response { body = "b" }
+    }
+  }
+}`, "Duplicate endpoint block", `Block endpoint "/a" was already defined at a.hcl:4,5-20`, "b.hcl:4,5-20"},
+		{"backend", `
+definitions {
+  backend "be" {
+    origin = "http://127.0.0.1"
+  }
+}`, "Duplicate backend block", `Block backend "be" was already defined at a.hcl:10,3-17`, "b.hcl:3,3-17"},
+		{"attribute", `
+settings {
+  log_level = "info"
+}`, "Duplicate argument", `Argument "log_level" was already set at a.hcl:15,3-12`, "b.hcl:3,3-12"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			dir := subT.TempDir()
+			writeConfigFiles(subT, dir, map[string]string{"a.hcl": first, "b.hcl": tc.second})
+
+			_, err := configload.LoadFiles(nil, []string{dir})
+			diags, ok := err.(hcl.Diagnostics)
+			if !ok || len(diags) != 1 {
+				subT.Fatalf("expected one diagnostic, got: %v", err)
+			}
+
+			diag := diags[0]
+			if diag.Summary != tc.expSummary {
+				subT.Errorf("expected summary %q, got: %q", tc.expSummary, diag.Summary)
+			}
+			if diag.Detail != tc.expDetail {
+				subT.Errorf("expected detail %q, got: %q", tc.expDetail, diag.Detail)
+			}
+			if diag.Subject == nil || diag.Subject.String() != tc.expRange {
+				subT.Errorf("expected range %q, got: %v", tc.expRange, diag.Subject)
+			}
+		})
+	}
+}
+
+func TestConfigFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "couper.hcl")
+	writeConfigFiles(t, dir, map[string]string{"couper.hcl": ""})
+
+	for _, tc := range []struct {
+		name      string
+		filePaths []string
+		dirPaths  []string
+		expErr    string
+	}{
+		{"missing directory", nil, []string{filepath.Join(dir, "missing")}, "failed to load configuration directory"},
+		{"file as directory", nil, []string{file}, "is not a directory"},
+		{"empty directory", nil, []string{t.TempDir()}, "no configuration files found"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			_, err := configload.ConfigFiles(tc.filePaths, tc.dirPaths)
+			if err == nil || !strings.Contains(err.Error(), tc.expErr) {
+				subT.Errorf("expected error containing %q, got: %v", tc.expErr, err)
+			}
+		})
+	}
+
+	files, err := configload.ConfigFiles([]string{file, file}, []string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != file {
+		t.Errorf("expected deduplicated files, got: %v", files)
+	}
+}
+
+func TestLoadFiles_RelativePaths(t *testing.T) {
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfigFiles(t, dir, map[string]string{
+		"couper.hcl": `
+server "a" {
+  endpoint "/a" {
+    error_file = "a.html"
+    response { status = 204 }
+  }
+}`,
+	})
+	writeConfigFiles(t, confDir, map[string]string{
+		"b.hcl": `
+server "b" {
+  endpoint "/b" {
+    error_file = "b.html"
+    response {
+      body = template_file("b.tmpl")
+    }
+  }
+  endpoint "/c" {
+    error_file = "/abs/c.html"
+    response { status = 204 }
+  }
+}`,
+	})
+
+	couperConfig, err := configload.LoadFiles([]string{filepath.Join(dir, "couper.hcl")}, []string{confDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints := append(couperConfig.Servers[0].Endpoints, couperConfig.Servers[1].Endpoints...)
+	for i, expFile := range []string{"a.html", filepath.Join("conf.d", "b.html"), "/abs/c.html"} {
+		if endpoints[i].ErrorFile != expFile {
+			t.Errorf("%s: expected error_file %q, got: %q", endpoints[i].Pattern, expFile, endpoints[i].ErrorFile)
+		}
+	}
+
+	attrs, _ := couperConfig.Servers[1].Endpoints[0].Response.Remain.JustAttributes()
+	call, ok := attrs["body"].Expr.(*hclsyntax.FunctionCallExpr)
+	if !ok {
+		t.Fatalf("expected a function call, got: %T", attrs["body"].Expr)
+	}
+	if arg, _ := call.Args[0].Value(nil); arg.AsString() != filepath.Join("conf.d", "b.tmpl") {
+		t.Errorf("expected resolved template_file argument, got: %q", arg.AsString())
+	}
+}
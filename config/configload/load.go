@@ -1,6 +1,7 @@
 package configload
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"os"
@@ -23,19 +24,30 @@ import (
 )
 
 const (
-	backend      = "backend"
-	definitions  = "definitions"
-	errorHandler = "error_handler"
-	nameLabel    = "name"
-	oauth2       = "oauth2"
-	proxy        = "proxy"
-	request      = "request"
-	server       = "server"
-	settings     = "settings"
+	backend           = "backend"
+	definitions       = "definitions"
+	errorHandler      = "error_handler"
+	function          = "function"
+	jwtSigningProfile = "jwt_signing_profile"
+	nameLabel         = "name"
+	oauth2            = "oauth2"
+	proxy             = "proxy"
+	request           = "request"
+	server            = "server"
+	settings          = "settings"
 	// defaultNameLabel maps the the hcl label attr 'name'.
 	defaultNameLabel = "default"
 )
 
+var functionBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{
+			Type:       function,
+			LabelNames: []string{nameLabel},
+		},
+	},
+}
+
 var regexProxyRequestLabel = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 var envContext *hcl.EvalContext
 var configBytes []byte
@@ -114,6 +126,7 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 	// Read possible reference definitions first. Those are the
 	// base for refinement merges during server block read out.
 	var definedBackends Backends
+	var templates endpointTemplates
 
 	for _, outerBlock := range content.Blocks {
 		switch outerBlock.Type {
@@ -142,10 +155,26 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 				}
 			}
 
+			templateContent, leftOver, diags := leftOver.PartialContent(endpointTemplateBlockSchema)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+
+			var err error
+			if templates, err = newEndpointTemplates(templateContent.Blocks); err != nil {
+				return nil, err
+			}
+
 			if diags = gohcl.DecodeBody(leftOver, envContext, couperConfig.Definitions); diags.HasErrors() {
 				return nil, diags
 			}
 
+			// gohcl does not expose block ranges, read them for function diagnostics
+			functionContent, _, _ := leftOver.PartialContent(functionBlockSchema)
+			for i, block := range functionContent.Blocks {
+				couperConfig.Definitions.Function[i].DefRange = block.DefRange
+			}
+
 			for _, oauth2Config := range couperConfig.Definitions.OAuth2AC {
 				err := uniqueAttributeKey(oauth2Config.Remain)
 				if err != nil {
@@ -188,7 +217,7 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 					return nil, err
 				}
 
-				if jwtConfig.JWKsURL != "" {
+				if jwtConfig.JWKsURL != "" || jwtConfig.FetchesRevocationList() {
 					bodyContent, _, diags := jwtConfig.HCLBody().PartialContent(jwtConfig.Schema(true))
 					if diags.HasErrors() {
 						return nil, diags
@@ -209,6 +238,9 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 
 			// access control - error_handler
 			var acErrorHandler []AccessControlSetter
+			for _, acConfig := range couperConfig.Definitions.AnyOf {
+				acErrorHandler = append(acErrorHandler, acConfig)
+			}
 			for _, acConfig := range couperConfig.Definitions.BasicAuth {
 				acErrorHandler = append(acErrorHandler, acConfig)
 			}
@@ -218,6 +250,9 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 			for _, acConfig := range couperConfig.Definitions.SAML {
 				acErrorHandler = append(acErrorHandler, acConfig)
 			}
+			for _, acConfig := range couperConfig.Definitions.Signature {
+				acErrorHandler = append(acErrorHandler, acConfig)
+			}
 			for _, acConfig := range couperConfig.Definitions.OAuth2AC {
 				acErrorHandler = append(acErrorHandler, acConfig)
 			}
@@ -225,63 +260,8 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 				acErrorHandler = append(acErrorHandler, acConfig)
 			}
 
-			for _, ac := range acErrorHandler {
-				acBody, ok := ac.(config.Body)
-				if !ok {
-					continue
-				}
-				acContent := bodyToContent(acBody.HCLBody())
-				configuredLabels := map[string]struct{}{}
-				for _, block := range acContent.Blocks.OfType(errorHandler) {
-					errHandlerConf, err := newErrorHandlerConf(block.Labels, block.Body, definedBackends)
-					if err != nil {
-						return nil, err
-					}
-
-					for _, k := range errHandlerConf.Kinds {
-						if _, exist := configuredLabels[k]; exist {
-							return nil, hcl.Diagnostics{&hcl.Diagnostic{
-								Severity: hcl.DiagError,
-								Summary:  fmt.Sprintf("duplicate error type registration: %q", k),
-								Subject:  &block.LabelRanges[0],
-							}}
-						}
-
-						if k != errors.Wildcard && !errors.IsKnown(k) {
-							subjRange := block.DefRange
-							if len(block.LabelRanges) > 0 {
-								subjRange = block.LabelRanges[0]
-							}
-							diag := &hcl.Diagnostic{
-								Severity: hcl.DiagError,
-								Summary:  fmt.Sprintf("error type is unknown: %q", k),
-								Subject:  &subjRange,
-							}
-							return nil, hcl.Diagnostics{diag}
-						}
-
-						configuredLabels[k] = struct{}{}
-					}
-
-					ac.Set(errHandlerConf)
-				}
-
-				if acDefault, has := ac.(config.ErrorHandlerGetter); has {
-					defaultHandler := acDefault.DefaultErrorHandler()
-					_, exist := configuredLabels[errors.Wildcard]
-					if !exist {
-						for _, kind := range defaultHandler.Kinds {
-							_, exist = configuredLabels[kind]
-							if exist {
-								break
-							}
-						}
-					}
-
-					if !exist {
-						ac.Set(acDefault.DefaultErrorHandler())
-					}
-				}
+			if err := configureErrorHandler(acErrorHandler, definedBackends); err != nil {
+				return nil, err
 			}
 
 		case settings:
@@ -296,6 +276,13 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 				}
 				return nil, diag
 			}
+			if err := couperConfig.Settings.SetTrustedProxies(); err != nil {
+				return nil, &hcl.Diagnostic{
+					Severity: hcl.DiagError,
+					Summary:  err.Error(),
+					Subject:  &outerBlock.DefRange,
+				}
+			}
 		}
 	}
 
@@ -314,6 +301,28 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 			return nil, errors.Configuration.Label(saml.Name).With(err)
 		}
 		saml.MetadataBytes = metadata
+
+		if saml.SpCertificateFile == "" && saml.SpPrivateKeyFile == "" {
+			continue
+		}
+		if saml.SpCertificateFile == "" || saml.SpPrivateKeyFile == "" {
+			return nil, errors.Configuration.Label(saml.Name).
+				Message("sp_certificate_file and sp_private_key_file must be configured together")
+		}
+
+		certificate, err := reader.ReadFromFile("saml2 sp_certificate_file", saml.SpCertificateFile)
+		if err != nil {
+			return nil, errors.Configuration.Label(saml.Name).With(err)
+		}
+		privateKey, err := reader.ReadFromFile("saml2 sp_private_key_file", saml.SpPrivateKeyFile)
+		if err != nil {
+			return nil, errors.Configuration.Label(saml.Name).With(err)
+		}
+		keyPair, err := tls.X509KeyPair(certificate, privateKey)
+		if err != nil {
+			return nil, errors.Configuration.Label(saml.Name).With(err)
+		}
+		saml.SpKeyPair = &keyPair
 	}
 
 	jwtSigningConfigs := make(map[string]*lib.JWTSigningConfig, 0)
@@ -341,15 +350,28 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 		}
 	}
 
+	userFunctions, err := lib.NewUserFunctions(couperConfig.Definitions.Function, evalContext.HCLContext().Functions)
+	if err != nil {
+		return nil, err
+	}
+
 	couperConfig.Context = evalContext.
 		WithJWTSigningConfigs(jwtSigningConfigs).
+		WithUserFunctions(userFunctions).
+		WithMultipartContent(eval.MustReadMultipartContent(body)).
 		WithOAuth2AC(couperConfig.Definitions.OAuth2AC).
 		WithSAML(couperConfig.Definitions.SAML)
 
 	// Read per server block and merge backend settings which results in a final server configuration.
 	for _, serverBlock := range content.Blocks.OfType(server) {
+		// Merge endpoints with their referenced endpoint_template first.
+		serverBody, err := templates.expandServer(serverBlock.Body)
+		if err != nil {
+			return nil, err
+		}
+
 		serverConfig := &config.Server{}
-		if diags = gohcl.DecodeBody(serverBlock.Body, envContext, serverConfig); diags.HasErrors() {
+		if diags = gohcl.DecodeBody(serverBody, envContext, serverConfig); diags.HasErrors() {
 			return nil, diags
 		}
 
@@ -358,6 +380,17 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 			serverConfig.Name = serverBlock.Labels[0]
 		}
 
+		csrfConfigs := []*config.CSRF{serverConfig.CSRF}
+		if serverConfig.Spa != nil {
+			csrfConfigs = append(csrfConfigs, serverConfig.Spa.CSRF)
+		}
+		for _, apiBlock := range serverConfig.APIs {
+			csrfConfigs = append(csrfConfigs, apiBlock.CSRF)
+		}
+		if err = configureCSRFErrorHandler(csrfConfigs, definedBackends); err != nil {
+			return nil, err
+		}
+
 		// Read api blocks and merge backends with server and definitions backends.
 		for _, apiBlock := range serverConfig.APIs {
 			err := refineEndpoints(definedBackends, apiBlock.Endpoints, true)
@@ -369,7 +402,7 @@ func LoadConfig(body hcl.Body, src []byte, filename string) (*config.Couper, err
 		}
 
 		// standalone endpoints
-		err := refineEndpoints(definedBackends, serverConfig.Endpoints, true)
+		err = refineEndpoints(definedBackends, serverConfig.Endpoints, true)
 		if err != nil {
 			return nil, err
 		}
@@ -493,6 +526,16 @@ func refineEndpoints(definedBackends Backends, endpoints config.Endpoints, check
 			}}
 		}
 
+		if endpoint.Authorization != nil {
+			if err := configureErrorHandler([]AccessControlSetter{endpoint.Authorization}, definedBackends); err != nil {
+				return err
+			}
+		}
+
+		if err := configureCSRFErrorHandler([]*config.CSRF{endpoint.CSRF}, definedBackends); err != nil {
+			return err
+		}
+
 		endpointContent := bodyToContent(endpoint.Remain)
 
 		proxies := endpointContent.Blocks.OfType(proxy)
@@ -781,10 +824,17 @@ func newBackend(definedBackends Backends, inlineConfig config.Inline) (hcl.Body,
 	}
 
 	if oauth2Backend != nil {
+		oauthBlocks := hcl.Blocks{{Type: backend, Body: oauth2Backend}}
+
+		// The merged oauth2 block keeps the child blocks of the wrapped one only.
+		profileBlocks, perr := oauthChildBlocks(jwtSigningProfile, bend)
+		if perr != nil {
+			return nil, perr
+		}
+		oauthBlocks = append(oauthBlocks, profileBlocks...)
+
 		wrapped := hclbody.New(&hcl.BodyContent{Blocks: []*hcl.Block{
-			{Type: oauth2, Body: hclbody.New(&hcl.BodyContent{Blocks: []*hcl.Block{
-				{Type: backend, Body: oauth2Backend},
-			}})},
+			{Type: oauth2, Body: hclbody.New(&hcl.BodyContent{Blocks: oauthBlocks})},
 		}})
 		bend = MergeBodies([]hcl.Body{bend, wrapped})
 	}
@@ -814,6 +864,25 @@ func createCatchAllEndpoint() *config.Endpoint {
 	}
 }
 
+// oauthChildBlocks returns the blocks of the given type within the oauth2 block of the given backend body.
+func oauthChildBlocks(blockType string, parent hcl.Body) (hcl.Blocks, error) {
+	innerContent, err := contentByType(oauth2, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthBlocks := innerContent.Blocks.OfType(oauth2)
+	if len(oauthBlocks) == 0 {
+		return nil, nil
+	}
+
+	content, err := contentByType(blockType, oauthBlocks[0].Body)
+	if err != nil {
+		return nil, err
+	}
+	return content.Blocks.OfType(blockType), nil
+}
+
 func newOAuthBackend(definedBackends Backends, parent hcl.Body) (hcl.Body, error) {
 	innerContent, err := contentByType(oauth2, parent)
 	if err != nil {
@@ -842,6 +911,81 @@ func newOAuthBackend(definedBackends Backends, parent hcl.Body) (hcl.Body, error
 	})})
 }
 
+// configureErrorHandler reads the error_handler blocks of the given access control setters
+// and applies a possible default error handler.
+func configureErrorHandler(setters []AccessControlSetter, definedBackends Backends) error {
+	for _, ac := range setters {
+		acBody, ok := ac.(config.Body)
+		if !ok {
+			continue
+		}
+		acContent := bodyToContent(acBody.HCLBody())
+		configuredLabels := map[string]struct{}{}
+		for _, block := range acContent.Blocks.OfType(errorHandler) {
+			errHandlerConf, err := newErrorHandlerConf(block.Labels, block.Body, definedBackends)
+			if err != nil {
+				return err
+			}
+
+			for _, k := range errHandlerConf.Kinds {
+				if _, exist := configuredLabels[k]; exist {
+					return hcl.Diagnostics{&hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  fmt.Sprintf("duplicate error type registration: %q", k),
+						Subject:  &block.LabelRanges[0],
+					}}
+				}
+
+				if k != errors.Wildcard && !errors.IsKnown(k) {
+					subjRange := block.DefRange
+					if len(block.LabelRanges) > 0 {
+						subjRange = block.LabelRanges[0]
+					}
+					diag := &hcl.Diagnostic{
+						Severity: hcl.DiagError,
+						Summary:  fmt.Sprintf("error type is unknown: %q", k),
+						Subject:  &subjRange,
+					}
+					return hcl.Diagnostics{diag}
+				}
+
+				configuredLabels[k] = struct{}{}
+			}
+
+			ac.Set(errHandlerConf)
+		}
+
+		if acDefault, has := ac.(config.ErrorHandlerGetter); has {
+			defaultHandler := acDefault.DefaultErrorHandler()
+			_, exist := configuredLabels[errors.Wildcard]
+			if !exist {
+				for _, kind := range defaultHandler.Kinds {
+					_, exist = configuredLabels[kind]
+					if exist {
+						break
+					}
+				}
+			}
+
+			if !exist {
+				ac.Set(acDefault.DefaultErrorHandler())
+			}
+		}
+	}
+	return nil
+}
+
+// configureCSRFErrorHandler reads the error_handler blocks of the given, possibly undefined, csrf blocks.
+func configureCSRFErrorHandler(csrfConfigs []*config.CSRF, definedBackends Backends) error {
+	var setters []AccessControlSetter
+	for _, csrf := range csrfConfigs {
+		if csrf != nil {
+			setters = append(setters, csrf)
+		}
+	}
+	return configureErrorHandler(setters, definedBackends)
+}
+
 func newErrorHandlerConf(kindLabels []string, body hcl.Body, definedBackends Backends) (*config.ErrorHandler, error) {
 	var allKinds []string // Support for all events within one label separated by space
 
@@ -9,9 +9,11 @@ const DefaultFilename = "couper.hcl"
 
 // Couper represents the <Couper> config object.
 type Couper struct {
-	Bytes       []byte
-	Context     context.Context
-	Filename    string
+	Bytes    []byte
+	Context  context.Context
+	Filename string
+	// Files lists the absolute paths of all participating configuration files.
+	Files       []string
 	Definitions *Definitions `hcl:"definitions,block"`
 	Servers     Servers      `hcl:"server,block"`
 	Settings    *Settings    `hcl:"settings,block"`
@@ -0,0 +1,26 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// Internally used for 'error_handler'.
+var _ Body = &CSRF{}
+
+// CSRF represents the <CSRF> object.
+type CSRF struct {
+	AccessControlSetter
+	AllowedOrigins []string `hcl:"allowed_origins,optional"`
+	CookieName     string   `hcl:"cookie_name,optional"`
+	Disable        bool     `hcl:"disable,optional"`
+	HeaderName     string   `hcl:"header_name,optional"`
+	Mode           string   `hcl:"mode,optional"`
+	SessionCookie  string   `hcl:"session_cookie,optional"`
+	TTL            string   `hcl:"ttl,optional"`
+
+	// Internally used for 'error_handler'.
+	Remain hcl.Body `hcl:",remain"`
+}
+
+// HCLBody implements the <Body> interface. Internally used for 'error_handler'.
+func (c *CSRF) HCLBody() hcl.Body {
+	return c.Remain
+}
@@ -2,10 +2,15 @@ package config
 
 // Definitions represents the <Definitions> object.
 type Definitions struct {
+	AllOf             []*AllOf             `hcl:"all_of,block"`
+	AnyOf             []*AnyOf             `hcl:"any_of,block"`
 	BasicAuth         []*BasicAuth         `hcl:"basic_auth,block"`
+	Function          []*Function          `hcl:"function,block"`
 	JWT               []*JWT               `hcl:"jwt,block"`
 	JWTSigningProfile []*JWTSigningProfile `hcl:"jwt_signing_profile,block"`
 	SAML              []*SAML              `hcl:"saml,block"`
+	Signature         []*Signature         `hcl:"signature,block"`
+	Store             []*Store             `hcl:"store,block"`
 	OAuth2AC          []*OAuth2AC          `hcl:"beta_oauth2,block"`
 	OIDC              []*OIDC              `hcl:"beta_oidc,block"`
 }
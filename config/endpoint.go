@@ -12,14 +12,16 @@ var _ Inline = &Endpoint{}
 
 // Endpoint represents the <Endpoint> object.
 type Endpoint struct {
-	AccessControl        []string  `hcl:"access_control,optional"`
-	DisableAccessControl []string  `hcl:"disable_access_control,optional"`
-	ErrorFile            string    `hcl:"error_file,optional"`
-	Pattern              string    `hcl:"pattern,label"`
-	Remain               hcl.Body  `hcl:",remain"`
-	RequestBodyLimit     string    `hcl:"request_body_limit,optional"`
-	Response             *Response `hcl:"response,block"`
-	Scope                cty.Value `hcl:"beta_scope,optional"`
+	AccessControl        []string       `hcl:"access_control,optional"`
+	Authorization        *Authorization `hcl:"authorization,block"`
+	CSRF                 *CSRF          `hcl:"csrf,block"`
+	DisableAccessControl []string       `hcl:"disable_access_control,optional"`
+	ErrorFile            string         `hcl:"error_file,optional"`
+	Pattern              string         `hcl:"pattern,label"`
+	Remain               hcl.Body       `hcl:",remain"`
+	RequestBodyLimit     string         `hcl:"request_body_limit,optional"`
+	Response             *Response      `hcl:"response,block"`
+	Scope                cty.Value      `hcl:"beta_scope,optional"`
 
 	// internally configured due to multi-label options
 	Proxies  Proxies
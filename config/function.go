@@ -0,0 +1,13 @@
+package config
+
+import "github.com/hashicorp/hcl/v2"
+
+// Function represents the <Function> object.
+type Function struct {
+	Name   string         `hcl:"name,label"`
+	Params []string       `hcl:"params"`
+	Result hcl.Expression `hcl:"result"`
+
+	// DefRange is the source range of the block header, set on load.
+	DefRange hcl.Range
+}
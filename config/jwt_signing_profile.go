@@ -4,6 +4,7 @@ type JWTSigningProfile struct {
 	Claims             Claims `hcl:"claims,optional"`
 	Key                string `hcl:"key,optional"`
 	KeyFile            string `hcl:"key_file,optional"`
+	KeyID              string `hcl:"key_id,optional"`
 	Name               string `hcl:"name,label"`
 	SignatureAlgorithm string `hcl:"signature_algorithm"`
 	TTL                string `hcl:"ttl"`
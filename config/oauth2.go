@@ -30,6 +30,7 @@ type OidcAS interface {
 // OAuth2Client represents the client configuration for OAuth2 clients.
 type OAuth2Client interface {
 	Inline
+	GetClientAssertionProfile() *ClientAssertionProfile
 	GetClientID() string
 	GetClientSecret() string
 	GetGrantType() string
@@ -37,6 +38,16 @@ type OAuth2Client interface {
 	GetTokenEndpointAuthMethod() *string
 }
 
+// ClientAssertionProfile represents the jwt_signing_profile block of an OAuth2 client. It configures the
+// client assertion for the private_key_jwt token endpoint authentication method.
+type ClientAssertionProfile struct {
+	Key                string `hcl:"key,optional"`
+	KeyFile            string `hcl:"key_file,optional"`
+	KeyID              string `hcl:"key_id,optional"`
+	SignatureAlgorithm string `hcl:"signature_algorithm"`
+	TTL                string `hcl:"ttl,optional"`
+}
+
 // OAuth2AcClient represents the client configuration for OAuth2 clients using the authorization code flow.
 type OAuth2AcClient interface {
 	OAuth2Client
@@ -22,15 +22,20 @@ var (
 
 // OAuth2ReqAuth represents the the oauth2 block in a backend block.
 type OAuth2ReqAuth struct {
-	BackendName             string   `hcl:"backend,optional"`
-	ClientID                string   `hcl:"client_id"`
-	ClientSecret            string   `hcl:"client_secret"`
-	GrantType               string   `hcl:"grant_type"`
-	Remain                  hcl.Body `hcl:",remain"`
-	Retries                 *uint8   `hcl:"retries,optional"`
-	Scope                   *string  `hcl:"scope,optional"`
-	TokenEndpoint           string   `hcl:"token_endpoint,optional"`
-	TokenEndpointAuthMethod *string  `hcl:"token_endpoint_auth_method,optional"`
+	Audience                string                  `hcl:"audience,optional"`
+	BackendName             string                  `hcl:"backend,optional"`
+	ClientAssertionProfile  *ClientAssertionProfile `hcl:"jwt_signing_profile,block"`
+	ClientID                string                  `hcl:"client_id"`
+	ClientSecret            string                  `hcl:"client_secret,optional"`
+	GrantType               string                  `hcl:"grant_type"`
+	Remain                  hcl.Body                `hcl:",remain"`
+	RequestedTokenType      string                  `hcl:"requested_token_type,optional"`
+	Resource                string                  `hcl:"resource,optional"`
+	Retries                 *uint8                  `hcl:"retries,optional"`
+	Scope                   *string                 `hcl:"scope,optional"`
+	SubjectTokenType        string                  `hcl:"subject_token_type,optional"`
+	TokenEndpoint           string                  `hcl:"token_endpoint,optional"`
+	TokenEndpointAuthMethod *string                 `hcl:"token_endpoint_auth_method,optional"`
 }
 
 // Reference implements the <BackendReference> interface.
@@ -51,7 +56,8 @@ func (oa OAuth2ReqAuth) Schema(inline bool) *hcl.BodySchema {
 	}
 
 	type Inline struct {
-		Backend *Backend `hcl:"backend,block"`
+		Backend      *Backend `hcl:"backend,block"`
+		SubjectToken string   `hcl:"subject_token,optional"`
 	}
 
 	schema, _ := gohcl.ImpliedBodySchema(&Inline{})
@@ -64,6 +70,10 @@ func (oa OAuth2ReqAuth) Schema(inline bool) *hcl.BodySchema {
 	return newBackendSchema(schema, oa.HCLBody())
 }
 
+func (oa OAuth2ReqAuth) GetClientAssertionProfile() *ClientAssertionProfile {
+	return oa.ClientAssertionProfile
+}
+
 func (oa OAuth2ReqAuth) GetClientID() string {
 	return oa.ClientID
 }
@@ -13,16 +13,19 @@ var (
 // OIDC represents an oidc block.
 type OIDC struct {
 	AccessControlSetter
-	BackendName             string   `hcl:"backend,optional"`
-	ClientID                string   `hcl:"client_id"`
-	ClientSecret            string   `hcl:"client_secret"`
-	ConfigurationURL        string   `hcl:"configuration_url"`
-	Name                    string   `hcl:"name,label"`
-	Remain                  hcl.Body `hcl:",remain"`
-	Scope                   *string  `hcl:"scope,optional"`
-	TokenEndpointAuthMethod *string  `hcl:"token_endpoint_auth_method,optional"`
-	ConfigurationTTL        string   `hcl:"configuration_ttl,optional"`
-	VerifierMethod          string   `hcl:"verifier_method,optional"`
+	BackendName             string                  `hcl:"backend,optional"`
+	ClientAssertionProfile  *ClientAssertionProfile `hcl:"jwt_signing_profile,block"`
+	ClientID                string                  `hcl:"client_id"`
+	ClientSecret            string                  `hcl:"client_secret,optional"`
+	ConfigurationURL        string                  `hcl:"configuration_url"`
+	DisableUserinfo         bool                    `hcl:"disable_userinfo,optional"`
+	Name                    string                  `hcl:"name,label"`
+	Remain                  hcl.Body                `hcl:",remain"`
+	Scope                   *string                 `hcl:"scope,optional"`
+	Session                 *Session                `hcl:"session,block"`
+	TokenEndpointAuthMethod *string                 `hcl:"token_endpoint_auth_method,optional"`
+	ConfigurationTTL        string                  `hcl:"configuration_ttl,optional"`
+	VerifierMethod          string                  `hcl:"verifier_method,optional"`
 
 	// internally used
 	Backend     hcl.Body
@@ -70,6 +73,10 @@ func (o OIDC) GetName() string {
 	return o.Name
 }
 
+func (o OIDC) GetClientAssertionProfile() *ClientAssertionProfile {
+	return o.ClientAssertionProfile
+}
+
 func (o OIDC) GetClientID() string {
 	return o.ClientID
 }
@@ -6,6 +6,9 @@ const (
 	ContextType ContextKey = iota
 	AccessControls
 	BackendName
+	BackendTimings
+	ClientIP
+	CSRFToken
 	Endpoint
 	EndpointKind
 	Error
@@ -0,0 +1,23 @@
+package request
+
+// Timings is the BackendTimings context value and holds the
+// timings of the first (outermost) backend round trip in milliseconds.
+type Timings struct {
+	values func() map[string]float64
+}
+
+// Set registers the timings source unless one is already set, so
+// nested round trips like token requests do not overwrite them.
+func (t *Timings) Set(values func() map[string]float64) {
+	if t.values == nil {
+		t.values = values
+	}
+}
+
+// Values returns the timings or nil if no round trip has been started.
+func (t *Timings) Values() map[string]float64 {
+	if t == nil || t.values == nil {
+		return nil
+	}
+	return t.values()
+}
@@ -14,6 +14,8 @@ type ACDefinitions map[string]*AccessControl
 type AccessControl struct {
 	Control      accesscontrol.AccessControl
 	ErrorHandler []*config.ErrorHandler
+	// Members holds the references of an all_of group which gets listed in place.
+	Members []string
 }
 
 func (m ACDefinitions) Add(name string, ac accesscontrol.AccessControl, eh []*config.ErrorHandler) error {
@@ -46,3 +48,31 @@ func (m ACDefinitions) MustExist(name string) error {
 
 	return nil
 }
+
+// Expand resolves the given references and replaces all_of groups with their
+// members. Duplicate references are listed once.
+func (m ACDefinitions) Expand(names []string) []string {
+	var result []string
+	seen := make(map[string]struct{})
+
+	var expand func(name string)
+	expand = func(name string) {
+		if ac, ok := m[name]; ok && len(ac.Members) > 0 {
+			for _, member := range ac.Members {
+				expand(member)
+			}
+			return
+		}
+
+		if _, ok := seen[name]; ok {
+			return
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+
+	for _, name := range names {
+		expand(name)
+	}
+	return result
+}
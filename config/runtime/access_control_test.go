@@ -84,6 +84,73 @@ func TestACDefinitions_errors(t *testing.T) {
 			`,
 			"configuration error: accessControl requires a label",
 		},
+		{
+			"any_of with undefined reference",
+			`
+			server "test" {
+			}
+			definitions {
+				basic_auth "ba" {
+				}
+				any_of "group" {
+					access_control = ["ba", "undefined"]
+				}
+			}
+			`,
+			"configuration error: group: accessControl is not defined: undefined",
+		},
+		{
+			"collision: all_of/any_of",
+			`
+			server "test" {
+			}
+			definitions {
+				basic_auth "ba" {
+				}
+				all_of "foo" {
+					access_control = ["ba"]
+				}
+				any_of "foo" {
+					access_control = ["ba"]
+				}
+			}
+			`,
+			"configuration error: foo: accessControl already defined",
+		},
+		{
+			"group reference cycle",
+			`
+			server "test" {
+			}
+			definitions {
+				basic_auth "ba" {
+				}
+				all_of "b" {
+					access_control = ["ba", "c"]
+				}
+				any_of "a" {
+					access_control = ["ba", "b"]
+				}
+				any_of "c" {
+					access_control = ["a"]
+				}
+			}
+			`,
+			"configuration error: b: access control reference cycle: b -> c -> a -> b",
+		},
+		{
+			"empty group",
+			`
+			server "test" {
+			}
+			definitions {
+				any_of "a" {
+					access_control = []
+				}
+			}
+			`,
+			"configuration error: a: any_of requires at least one access control",
+		},
 	}
 
 	for _, tt := range tests {
@@ -4,6 +4,8 @@ package runtime
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
 	"net/http"
@@ -27,6 +29,7 @@ import (
 	"github.com/avenga/couper/config/runtime/server"
 	"github.com/avenga/couper/errors"
 	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/eval/lib"
 	"github.com/avenga/couper/handler"
 	"github.com/avenga/couper/handler/middleware"
 	"github.com/avenga/couper/handler/transport"
@@ -95,6 +98,13 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 	noopResp := httptest.NewRecorder().Result()
 	noopResp.Request = noopReq
 	evalContext := conf.Context.Value(request.ContextType).(*eval.Context)
+
+	stores, err := NewKeyValueStores(conf, memStore)
+	if err != nil {
+		return nil, err
+	}
+	evalContext.WithStores(stores)
+
 	confCtx := evalContext.WithClientRequest(noopReq).WithBeresps(noopResp).HCLContext()
 
 	oidcConfigs, ocErr := configureOidcConfigs(conf, confCtx, log, memStore)
@@ -147,7 +157,15 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 
 		var spaHandler http.Handler
 		if srvConf.Spa != nil {
-			spaHandler, err = handler.NewSpa(srvConf.Spa.BootstrapFile, serverOptions, []hcl.Body{srvConf.Spa.Remain, srvConf.Remain})
+			spaContent, _, diags := srvConf.Spa.Remain.PartialContent(config.SpaBootstrapDataSchema)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			if attr, exist := spaContent.Attributes["bootstrap_data"]; exist {
+				srvConf.Spa.BootstrapData = attr.Expr
+			}
+
+			spaHandler, err = handler.NewSpa(srvConf.Spa, serverOptions, []hcl.Body{srvConf.Spa.Remain, srvConf.Remain})
 			if err != nil {
 				return nil, err
 			}
@@ -156,22 +174,34 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			if cerr != nil {
 				return nil, cerr
 			}
-			h := middleware.NewCORSHandler(corsOptions, spaHandler)
+			spaOptions := &protectedOptions{
+				epOpts:       &handler.EndpointOptions{Error: serverOptions.ServerErrTpl},
+				memStore:     memStore,
+				proxyFromEnv: conf.Settings.NoProxyFromEnv,
+				srvOpts:      serverOptions,
+			}
+
+			csrfConf := whichCSRF(srvConf.CSRF, srvConf.Spa.CSRF)
+			csrfOptions, cerr := middleware.NewCSRFOptions(csrfConf, memStore)
+			if cerr != nil {
+				return nil, cerr
+			}
+			csrfErrorHandler, cerr := newCSRFErrorHandler(confCtx, csrfConf, spaOptions, log)
+			if cerr != nil {
+				return nil, cerr
+			}
+			spaOptions.handler = middleware.NewCORSHandler(corsOptions, spaHandler)
 
 			spaHandler, err = configureProtectedHandler(accessControls, confCtx,
 				config.NewAccessControl(srvConf.AccessControl, srvConf.DisableAccessControl),
 				config.NewAccessControl(srvConf.Spa.AccessControl, srvConf.Spa.DisableAccessControl),
-				&protectedOptions{
-					epOpts:       &handler.EndpointOptions{Error: serverOptions.ServerErrTpl},
-					handler:      h,
-					memStore:     memStore,
-					proxyFromEnv: conf.Settings.NoProxyFromEnv,
-					srvOpts:      serverOptions,
-				}, nil, log)
+				spaOptions, nil, log)
 
 			if err != nil {
 				return nil, err
 			}
+			// validate before any access control could change a state, e.g. a session
+			spaHandler = middleware.NewCSRFHandler(csrfOptions, csrfErrorHandler, spaHandler)
 
 			for _, spaPath := range srvConf.Spa.Paths {
 				err = setRoutesFromHosts(serverConfiguration, portsHosts, path.Join(serverOptions.SPABasePath, spaPath), spaHandler, spa)
@@ -250,6 +280,25 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 				return nil, err
 			}
 
+			csrfConfs := []*config.CSRF{srvConf.CSRF}
+			if parentAPI != nil {
+				csrfConfs = append(csrfConfs, parentAPI.CSRF)
+			}
+			csrfConf := whichCSRF(append(csrfConfs, endpointConf.CSRF)...)
+			csrfOptions, err := middleware.NewCSRFOptions(csrfConf, memStore)
+			if err != nil {
+				return nil, err
+			}
+			csrfErrorHandler, err := newCSRFErrorHandler(confCtx, csrfConf, &protectedOptions{
+				epOpts:       epOpts,
+				memStore:     memStore,
+				proxyFromEnv: conf.Settings.NoProxyFromEnv,
+				srvOpts:      serverOptions,
+			}, log)
+			if err != nil {
+				return nil, err
+			}
+
 			modifier := []hcl.Body{srvConf.Remain}
 
 			kind := endpoint
@@ -264,7 +313,8 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			protectedHandler := middleware.NewCORSHandler(corsOptions, epHandler)
 
 			accessControl := newAC(srvConf, parentAPI)
-			if parentAPI != nil && parentAPI.CatchAllEndpoint == endpointConf {
+			isCatchAll := parentAPI != nil && parentAPI.CatchAllEndpoint == endpointConf
+			if isCatchAll {
 				protectedHandler = epOpts.Error.ServeError(errors.RouteNotFound)
 			}
 			scopeMaps := []map[string]string{}
@@ -284,15 +334,20 @@ func NewServerConfiguration(conf *config.Couper, log *logrus.Entry, memStore *ca
 			endpointHandlers[endpointConf], err = configureProtectedHandler(accessControls, confCtx, accessControl,
 				config.NewAccessControl(endpointConf.AccessControl, endpointConf.DisableAccessControl),
 				&protectedOptions{
-					epOpts:       epOpts,
-					handler:      protectedHandler,
-					memStore:     memStore,
-					proxyFromEnv: conf.Settings.NoProxyFromEnv,
-					srvOpts:      serverOptions,
+					authorization: endpointConf.Authorization,
+					epOpts:        epOpts,
+					handler:       protectedHandler,
+					memStore:      memStore,
+					proxyFromEnv:  conf.Settings.NoProxyFromEnv,
+					srvOpts:       serverOptions,
 				}, scopeControl, log)
 			if err != nil {
 				return nil, err
 			}
+			if !isCatchAll {
+				// validate before any access control could change a state, e.g. a session
+				endpointHandlers[endpointConf] = middleware.NewCSRFHandler(csrfOptions, csrfErrorHandler, endpointHandlers[endpointConf])
+			}
 
 			err = setRoutesFromHosts(serverConfiguration, portsHosts, pattern, endpointHandlers[endpointConf], kind)
 			if err != nil {
@@ -328,6 +383,14 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry
 		MaxConnections:         beConf.MaxConnections,
 	}
 
+	if beConf.ClientCertificateFile != "" || beConf.ClientPrivateKeyFile != "" {
+		cert, err := readClientCertificate(beConf.ClientCertificateFile, beConf.ClientPrivateKeyFile)
+		if err != nil {
+			return nil, errors.Configuration.Label(beConf.Name).With(err)
+		}
+		tc.ClientCertificate = cert
+	}
+
 	if err := parseDuration(beConf.ConnectTimeout, &tc.ConnectTimeout); err != nil {
 		return nil, err
 	}
@@ -348,6 +411,23 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry
 	options := &transport.BackendOptions{
 		OpenAPI: openAPIopts,
 	}
+
+	sigContent, _, _ := backendCtx.PartialContent(config.AWSSigV4BlockSchema)
+	if sigContent != nil {
+		if blocks := sigContent.Blocks.OfType("aws_sigv4"); len(blocks) > 0 {
+			beConf.AWSSigV4 = &config.AWSSigV4{}
+			if diags := gohcl.DecodeBody(blocks[0].Body, evalCtx, beConf.AWSSigV4); diags.HasErrors() {
+				return nil, diags
+			}
+
+			signer, signErr := transport.NewAWSSigV4(beConf.AWSSigV4)
+			if signErr != nil {
+				return nil, signErr
+			}
+			options.AWSSigV4 = signer
+		}
+	}
+
 	backend := transport.NewBackend(backendCtx, tc, options, log)
 
 	oauthContent, _, _ := backendCtx.PartialContent(config.OAuthBlockSchema)
@@ -389,6 +469,28 @@ func newBackend(evalCtx *hcl.EvalContext, backendCtx hcl.Body, log *logrus.Entry
 	return backend, nil
 }
 
+func readClientCertificate(certFile, keyFile string) (*tls.Certificate, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, fmt.Errorf("client_certificate_file and client_private_key_file must be configured together")
+	}
+
+	certBytes, err := reader.ReadFromFile("client_certificate_file", certFile)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes, err := reader.ReadFromFile("client_private_key_file", keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.X509KeyPair(certBytes, keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
 func getBackendName(evalCtx *hcl.EvalContext, backendCtx hcl.Body) (string, error) {
 	content, _, _ := backendCtx.PartialContent(&hcl.BodySchema{Attributes: []hcl.AttributeSchema{
 		{Name: "name"}},
@@ -425,6 +527,22 @@ func whichCORS(parent *config.Server, this interface{}) *config.CORS {
 	return corsData
 }
 
+// whichCSRF returns the most specific of the given csrf configurations, ordered
+// from the server to the endpoint, or nil if it has been disabled.
+func whichCSRF(confs ...*config.CSRF) *config.CSRF {
+	var csrf *config.CSRF
+	for _, c := range confs {
+		if c != nil {
+			csrf = c
+		}
+	}
+
+	if csrf != nil && csrf.Disable {
+		return nil
+	}
+	return csrf
+}
+
 func configureOidcConfigs(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry, memStore *cache.MemoryStore) (oidc.Configs, error) {
 	oidcConfigs := make(oidc.Configs)
 	if conf.Definitions != nil {
@@ -447,6 +565,58 @@ func configureOidcConfigs(conf *config.Couper, confCtx *hcl.EvalContext, log *lo
 	return oidcConfigs, nil
 }
 
+// NewKeyValueStores creates the key/value stores of the configured store blocks.
+func NewKeyValueStores(conf *config.Couper, memStore *cache.MemoryStore) (map[string]*lib.KeyValueStore, error) {
+	const (
+		defaultMaxEntries   = 10000
+		defaultMaxValueSize = "64KiB"
+		defaultTTL          = "1h"
+	)
+
+	stores := make(map[string]*lib.KeyValueStore)
+	if conf.Definitions == nil {
+		return stores, nil
+	}
+
+	for _, storeConf := range conf.Definitions.Store {
+		confErr := errors.Configuration.Label(storeConf.Name)
+		if _, exist := stores[storeConf.Name]; exist {
+			return nil, confErr.Message("store block with this label already defined")
+		}
+
+		maxEntries := storeConf.MaxEntries
+		if maxEntries == 0 {
+			maxEntries = defaultMaxEntries
+		}
+
+		maxValueSize := storeConf.MaxValueSize
+		if maxValueSize == "" {
+			maxValueSize = defaultMaxValueSize
+		}
+		size, err := units.RAMInBytes(maxValueSize)
+		if err != nil {
+			return nil, confErr.With(err)
+		}
+
+		ttl := storeConf.DefaultTTL
+		if ttl == "" {
+			ttl = defaultTTL
+		}
+		duration, err := time.ParseDuration(ttl)
+		if err != nil {
+			return nil, confErr.With(err)
+		}
+
+		store, err := lib.NewKeyValueStore(storeConf.Name, maxEntries, size, duration, memStore)
+		if err != nil {
+			return nil, confErr.With(err)
+		}
+		stores[storeConf.Name] = store
+	}
+
+	return stores, nil
+}
+
 func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log *logrus.Entry,
 	memStore *cache.MemoryStore, oidcConfigs oidc.Configs) (ACDefinitions, error) {
 
@@ -455,7 +625,23 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 	if conf.Definitions != nil {
 		for _, baConf := range conf.Definitions.BasicAuth {
 			confErr := errors.Configuration.Label(baConf.Name)
-			basicAuth, err := ac.NewBasicAuth(baConf.Name, baConf.User, baConf.Pass, baConf.File)
+			var (
+				basicAuth *ac.BasicAuth
+				err       error
+			)
+			if baConf.LDAP != nil {
+				if baConf.User != "" || baConf.Pass != "" || baConf.File != "" {
+					return nil, confErr.Message("ldap cannot be combined with user, password or htpasswd_file")
+				}
+
+				ldapOptions, lerr := newLDAPOptions(baConf.LDAP, memStore)
+				if lerr != nil {
+					return nil, confErr.With(lerr)
+				}
+				basicAuth, err = ac.NewLDAPBasicAuth(baConf.Name, ldapOptions)
+			} else {
+				basicAuth, err = ac.NewBasicAuth(baConf.Name, baConf.User, baConf.Pass, baConf.File)
+			}
 			if err != nil {
 				return nil, confErr.With(err)
 			}
@@ -468,10 +654,42 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 		for _, jwtConf := range conf.Definitions.JWT {
 			confErr := errors.Configuration.Label(jwtConf.Name)
 
+			var backend http.RoundTripper
+			if jwtConf.JWKSBackendBody != nil {
+				b, err := newBackend(confCtx, jwtConf.JWKSBackendBody, log, conf.Settings.NoProxyFromEnv, memStore)
+				if err != nil {
+					return nil, confErr.With(err)
+				}
+				backend = b
+			}
+
+			var revocation *ac.JWTRevocation
+			if jwtConf.RevocationURL != "" {
+				// the background reload of the list ends with the lifetime of this configuration
+				revocationCtx, cancel := context.WithCancel(conf.Context.Value(request.ContextType).(context.Context))
+				go func() {
+					<-memStore.Done()
+					cancel()
+				}()
+				r, err := ac.NewJWTRevocation(jwtConf.RevocationURL, jwtConf.RevocationTTL, backend, revocationCtx)
+				if err != nil {
+					return nil, confErr.With(err)
+				}
+				revocation = r
+			}
+
+			var dpop *ac.DPoP
+			if jwtConf.DPoP {
+				d, err := ac.NewDPoP(jwtConf.Name, jwtConf.DPoPMaxAge, memStore)
+				if err != nil {
+					return nil, confErr.With(err)
+				}
+				dpop = d
+			}
+
 			var jwt *ac.JWT
 			if jwtConf.JWKsURL != "" {
-				noProxy := conf.Settings.NoProxyFromEnv
-				jwks, err := configureJWKS(jwtConf, conf, confCtx, log, noProxy, memStore)
+				jwks, err := configureJWKS(jwtConf, conf, backend)
 				if err != nil {
 					return nil, confErr.With(err)
 				}
@@ -479,12 +697,14 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 				jwt, err = ac.NewJWTFromJWKS(&ac.JWTOptions{
 					Claims:         jwtConf.Claims,
 					ClaimsRequired: jwtConf.ClaimsRequired,
+					DPoP:           dpop,
 					Name:           jwtConf.Name,
 					RoleClaim:      jwtConf.RoleClaim,
 					RoleMap:        jwtConf.RoleMap,
 					ScopeClaim:     jwtConf.ScopeClaim,
 					Source:         ac.NewJWTSource(jwtConf.Cookie, jwtConf.Header),
 					JWKS:           jwks,
+					Revocation:     revocation,
 				})
 				if err != nil {
 					return nil, confErr.With(err)
@@ -499,8 +719,10 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 					Algorithm:      jwtConf.SignatureAlgorithm,
 					Claims:         jwtConf.Claims,
 					ClaimsRequired: jwtConf.ClaimsRequired,
+					DPoP:           dpop,
 					Key:            key,
 					Name:           jwtConf.Name,
+					Revocation:     revocation,
 					RoleClaim:      jwtConf.RoleClaim,
 					RoleMap:        jwtConf.RoleMap,
 					ScopeClaim:     jwtConf.ScopeClaim,
@@ -528,12 +750,55 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 			if err != nil {
 				return nil, confErr.With(err)
 			}
+			s.ConfigureServiceProvider(saml.SpKeyPair, saml.SpSloUrl)
 
 			if err = accessControls.Add(saml.Name, s, saml.ErrorHandler); err != nil {
 				return nil, confErr.With(err)
 			}
 		}
 
+		for _, signatureConf := range conf.Definitions.Signature {
+			confErr := errors.Configuration.Label(signatureConf.Name)
+			key, err := reader.ReadFromAttrFile("signature key", signatureConf.Key, signatureConf.KeyFile)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			bodyLimit, err := parseBodyLimit(signatureConf.RequestBodyLimit)
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			var timestamp hcl.Expression
+			content, _, diags := signatureConf.Remain.PartialContent(config.SignatureTimestampSchema)
+			if diags.HasErrors() {
+				return nil, confErr.With(diags)
+			}
+			if attr, exist := content.Attributes["timestamp"]; exist {
+				timestamp = attr.Expr
+			}
+
+			s, err := ac.NewSignature(&ac.SignatureOptions{
+				Algorithm:          signatureConf.Algorithm,
+				BodyLimit:          bodyLimit,
+				CanonicalString:    signatureConf.CanonicalString,
+				Encoding:           signatureConf.Encoding,
+				Header:             signatureConf.Header,
+				HeaderParam:        signatureConf.HeaderParam,
+				Key:                key,
+				Prefix:             signatureConf.Prefix,
+				Timestamp:          timestamp,
+				TimestampTolerance: signatureConf.TimestampTolerance,
+			})
+			if err != nil {
+				return nil, confErr.With(err)
+			}
+
+			if err = accessControls.Add(signatureConf.Name, s, signatureConf.ErrorHandler); err != nil {
+				return nil, confErr.With(err)
+			}
+		}
+
 		for _, oauth2Conf := range conf.Definitions.OAuth2AC {
 			confErr := errors.Configuration.Label(oauth2Conf.Name)
 			backend, err := newBackend(confCtx, oauth2Conf.Backend, log, conf.Settings.NoProxyFromEnv, memStore)
@@ -546,7 +811,24 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 				return nil, confErr.With(err)
 			}
 
-			oa, err := ac.NewOAuth2Callback(oauth2Client)
+			var session *ac.Session
+			if oauth2Conf.Session != nil {
+				if oauth2Conf.Session.BackChannelLogoutPath != "" {
+					return nil, confErr.Message("backchannel_logout_path is only supported by beta_oidc")
+				}
+
+				session, err = ac.NewSession(&ac.SessionOptions{
+					ClientID: oauth2Conf.ClientID,
+					Config:   oauth2Conf.Session,
+					MemStore: memStore,
+					Name:     oauth2Conf.Name,
+				})
+				if err != nil {
+					return nil, confErr.With(err)
+				}
+			}
+
+			oa, err := ac.NewOAuth2Callback(oauth2Client, session)
 			if err != nil {
 				return nil, confErr.With(err)
 			}
@@ -572,7 +854,36 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 					Messagef("verifier_method %s not supported", oidcConfig.VerifierMethod)
 			}
 
-			oa, err := ac.NewOAuth2Callback(oidcClient)
+			var session *ac.Session
+			if oidcConf.Session != nil {
+				var backChannelLogout *ac.BackChannelLogout
+				if oidcConf.Session.BackChannelLogoutPath != "" {
+					backChannelLogout, err = ac.NewBackChannelLogout(&ac.BackChannelLogoutOptions{
+						Backend:     oidcConfig.Backend,
+						ClientID:    oidcConf.ClientID,
+						ConfContext: conf.Context.Value(request.ContextType).(context.Context),
+						Issuer:      oidcConfig.GetIssuer,
+						JwksURI:     oidcConfig.GetJwksURI,
+					})
+					if err != nil {
+						return nil, confErr.With(err)
+					}
+				}
+
+				session, err = ac.NewSession(&ac.SessionOptions{
+					BackChannelLogout:  backChannelLogout,
+					ClientID:           oidcConf.ClientID,
+					Config:             oidcConf.Session,
+					EndSessionEndpoint: oidcConfig.GetEndSessionEndpoint,
+					MemStore:           memStore,
+					Name:               oidcConf.Name,
+				})
+				if err != nil {
+					return nil, confErr.With(err)
+				}
+			}
+
+			oa, err := ac.NewOAuth2Callback(oidcClient, session)
 			if err != nil {
 				return nil, confErr.With(err)
 			}
@@ -581,22 +892,165 @@ func configureAccessControls(conf *config.Couper, confCtx *hcl.EvalContext, log
 				return nil, confErr.With(err)
 			}
 		}
+
+		if err := configureAccessControlGroups(conf.Definitions, accessControls); err != nil {
+			return nil, err
+		}
 	}
 
 	return accessControls, nil
 }
 
-func configureJWKS(jwtConf *config.JWT, conf *config.Couper, confContext *hcl.EvalContext, log *logrus.Entry, ignoreProxyEnv bool, memStore *cache.MemoryStore) (*ac.JWKS, error) {
-	var backend http.RoundTripper
+// newLDAPOptions creates the options of an ldap block with their defaults.
+func newLDAPOptions(conf *config.BasicAuthLDAP, memStore *cache.MemoryStore) (*ac.LDAPOptions, error) {
+	opts := &ac.LDAPOptions{
+		BindDN:         conf.BindDN,
+		BindDNTemplate: conf.BindDNTemplate,
+		BindPassword:   conf.BindPassword,
+		GroupAttribute: conf.GroupAttribute,
+		MemStore:       memStore,
+		RequiredGroups: conf.RequiredGroups,
+		RoleMap:        conf.RoleMap,
+		SearchBaseDN:   conf.SearchBaseDN,
+		SearchFilter:   conf.SearchFilter,
+		StartTLS:       conf.StartTLS,
+		URL:            conf.URL,
+	}
+
+	if opts.GroupAttribute == "" {
+		opts.GroupAttribute = "memberOf"
+	}
+	if opts.SearchFilter == "" {
+		opts.SearchFilter = "(uid={username})"
+	}
 
-	if jwtConf.JWKSBackendBody != nil {
-		b, err := newBackend(confContext, jwtConf.JWKSBackendBody, log, ignoreProxyEnv, memStore)
+	cacheTTL, timeout := conf.CacheTTL, conf.Timeout
+	if cacheTTL == "" {
+		cacheTTL = "1m"
+	}
+	if timeout == "" {
+		timeout = "5s"
+	}
+	if err := parseDuration(cacheTTL, &opts.CacheTTL); err != nil {
+		return nil, fmt.Errorf("ldap cache_ttl: %w", err)
+	}
+	if err := parseDuration(timeout, &opts.Timeout); err != nil {
+		return nil, fmt.Errorf("ldap timeout: %w", err)
+	}
+
+	if conf.CACertificateFile != "" {
+		caCert, err := reader.ReadFromFile("ldap ca_certificate_file", conf.CACertificateFile)
 		if err != nil {
 			return nil, err
 		}
-		backend = b
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("ldap ca_certificate_file: no PEM certificate found")
+		}
+		opts.TLSConfig = &tls.Config{RootCAs: pool}
 	}
 
+	return opts, nil
+}
+
+// configureAccessControlGroups creates the all_of and any_of access controls after
+// all other ones are known, since groups may reference each other.
+func configureAccessControlGroups(definitions *config.Definitions, accessControls ACDefinitions) error {
+	type group struct {
+		allOf *config.AllOf
+		anyOf *config.AnyOf
+	}
+
+	groups := make(map[string]*group)
+	for _, allOf := range definitions.AllOf {
+		if _, exist := groups[allOf.Name]; exist {
+			return errors.Configuration.Label(allOf.Name).Message("accessControl already defined")
+		}
+		groups[allOf.Name] = &group{allOf: allOf}
+	}
+	for _, anyOf := range definitions.AnyOf {
+		if _, exist := groups[anyOf.Name]; exist {
+			return errors.Configuration.Label(anyOf.Name).Message("accessControl already defined")
+		}
+		groups[anyOf.Name] = &group{anyOf: anyOf}
+	}
+
+	resolved := make(map[string]struct{})
+	var resolve func(name string, path []string) error
+	resolve = func(name string, path []string) error {
+		if _, ok := resolved[name]; ok {
+			return nil
+		}
+
+		for i, p := range path {
+			if p == name {
+				return errors.Configuration.Label(path[0]).
+					Messagef("access control reference cycle: %s", strings.Join(append(path[i:], name), " -> "))
+			}
+		}
+
+		g, isGroup := groups[name]
+		if !isGroup {
+			if err := accessControls.MustExist(name); err != nil {
+				return errors.Configuration.Label(path[len(path)-1]).With(err)
+			}
+			return nil
+		}
+
+		var references []string
+		if g.allOf != nil {
+			references = g.allOf.AccessControl
+		} else {
+			references = g.anyOf.AccessControl
+		}
+
+		var list ac.List
+		for _, ref := range references {
+			if err := resolve(ref, append(path, name)); err != nil {
+				return err
+			}
+			list = append(list, ac.NewItem(ref, accessControls[ref].Control, nil))
+		}
+		resolved[name] = struct{}{}
+
+		confErr := errors.Configuration.Label(name)
+		if g.allOf != nil {
+			allOf, err := ac.NewAllOf(list)
+			if err != nil {
+				return confErr.With(err)
+			}
+			if err = accessControls.Add(name, allOf, nil); err != nil {
+				return confErr.With(err)
+			}
+			accessControls[name].Members = references
+			return nil
+		}
+
+		anyOf, err := ac.NewAnyOf(name, list)
+		if err != nil {
+			return confErr.With(err)
+		}
+		if err = accessControls.Add(name, anyOf, g.anyOf.ErrorHandler); err != nil {
+			return confErr.With(err)
+		}
+		return nil
+	}
+
+	for _, allOf := range definitions.AllOf {
+		if err := resolve(allOf.Name, nil); err != nil {
+			return err
+		}
+	}
+	for _, anyOf := range definitions.AnyOf {
+		if err := resolve(anyOf.Name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func configureJWKS(jwtConf *config.JWT, conf *config.Couper, backend http.RoundTripper) (*ac.JWKS, error) {
 	evalContext := conf.Context.Value(request.ContextType).(context.Context)
 	jwks, err := ac.NewJWKS(jwtConf.JWKsURL, jwtConf.JWKsTTL, backend, evalContext)
 	if err != nil {
@@ -607,17 +1061,18 @@ func configureJWKS(jwtConf *config.JWT, conf *config.Couper, confContext *hcl.Ev
 }
 
 type protectedOptions struct {
-	epOpts       *handler.EndpointOptions
-	handler      http.Handler
-	proxyFromEnv bool
-	memStore     *cache.MemoryStore
-	srvOpts      *server.Options
+	authorization *config.Authorization
+	epOpts        *handler.EndpointOptions
+	handler       http.Handler
+	proxyFromEnv  bool
+	memStore      *cache.MemoryStore
+	srvOpts       *server.Options
 }
 
 func configureProtectedHandler(m ACDefinitions, ctx *hcl.EvalContext, parentAC, handlerAC config.AccessControl,
 	opts *protectedOptions, scopeControl *ac.ScopeControl, log *logrus.Entry) (http.Handler, error) {
 	var list ac.List
-	for _, acName := range parentAC.Merge(handlerAC).List() {
+	for _, acName := range m.Expand(parentAC.Merge(handlerAC).List()) {
 		if e := m.MustExist(acName); e != nil {
 			return nil, e
 		}
@@ -635,12 +1090,38 @@ func configureProtectedHandler(m ACDefinitions, ctx *hcl.EvalContext, parentAC,
 		list = append(list, ac.NewItem("scope", scopeControl, handler.NewErrorHandler(nil, opts.epOpts.Error)))
 	}
 
+	if opts.authorization != nil {
+		const authorizationLabel = "authorization"
+		authorization, err := ac.NewAuthorization(opts.authorization.Rules)
+		if err != nil {
+			return nil, errors.Configuration.Label(authorizationLabel).With(err)
+		}
+
+		defs := ACDefinitions{authorizationLabel: {ErrorHandler: opts.authorization.ErrorHandler}}
+		eh, err := newErrorHandler(ctx, opts, log, defs, authorizationLabel)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, ac.NewItem(authorizationLabel, authorization, eh))
+	}
+
 	if len(list) > 0 {
 		return handler.NewAccessControl(opts.handler, list), nil
 	}
 	return opts.handler, nil
 }
 
+// newCSRFErrorHandler creates the error handler for the error_handler blocks of the given csrf configuration.
+func newCSRFErrorHandler(ctx *hcl.EvalContext, csrf *config.CSRF, opts *protectedOptions, log *logrus.Entry) (http.Handler, error) {
+	if csrf == nil {
+		return handler.NewErrorHandler(nil, opts.epOpts.Error), nil
+	}
+
+	const csrfLabel = "csrf"
+	defs := ACDefinitions{csrfLabel: {ErrorHandler: csrf.ErrorHandler}}
+	return newErrorHandler(ctx, opts, log, defs, csrfLabel)
+}
+
 func newErrorHandler(ctx *hcl.EvalContext, opts *protectedOptions, log *logrus.Entry,
 	defs ACDefinitions, references ...string) (http.Handler, error) {
 	kindsHandler := map[string]http.Handler{}
@@ -13,6 +13,7 @@ type Server struct {
 	APIs                 APIs      `hcl:"api,block"`
 	BasePath             string    `hcl:"base_path,optional"`
 	CORS                 *CORS     `hcl:"cors,block"`
+	CSRF                 *CSRF     `hcl:"csrf,block"`
 	DisableAccessControl []string  `hcl:"disable_access_control,optional"`
 	Endpoints            Endpoints `hcl:"endpoint,block"`
 	ErrorFile            string    `hcl:"error_file,optional"`
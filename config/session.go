@@ -0,0 +1,13 @@
+package config
+
+// Session represents the session block in a beta_oauth2 or beta_oidc block.
+type Session struct {
+	BackChannelLogoutPath string `hcl:"backchannel_logout_path,optional"`
+	CookieName            string `hcl:"cookie_name,optional"`
+	EndSessionEndpoint    string `hcl:"end_session_endpoint,optional"`
+	LogoutPath            string `hcl:"logout_path,optional"`
+	PostLogoutRedirectURI string `hcl:"post_logout_redirect_uri,optional"`
+	Secret                string `hcl:"secret,optional"`
+	Store                 string `hcl:"store,optional"`
+	TTL                   string `hcl:"ttl,optional"`
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
 )
 
@@ -75,6 +77,7 @@ var DefaultSettings = Settings{
 // Settings represents the <Settings> object.
 type Settings struct {
 	AcceptForwarded *AcceptForwarded
+	trustedProxies  []*net.IPNet
 
 	AcceptForwardedURL        []string `hcl:"accept_forwarded_url,optional"`
 	DefaultPort               int      `hcl:"default_port,optional"`
@@ -95,6 +98,7 @@ type Settings struct {
 	TelemetryMetricsExporter  string   `hcl:"beta_metrics_exporter,optional"`
 	TelemetryTraces           bool     `hcl:"beta_traces,optional"`
 	TelemetryTracesEndpoint   string   `hcl:"beta_traces_endpoint,optional"`
+	TrustedProxies            List     `hcl:"trusted_proxies,optional"`
 	XForwardedHost            bool     `hcl:"xfh,optional"`
 }
 
@@ -129,3 +133,75 @@ func (s *Settings) AcceptsForwardedProtocol() bool {
 func (s *Settings) AcceptsForwardedHost() bool {
 	return s.AcceptForwarded.host
 }
+
+// SetTrustedProxies parses the configured trusted proxy IP addresses and CIDR ranges.
+func (s *Settings) SetTrustedProxies() error {
+	s.trustedProxies = nil
+	for _, proxy := range s.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("invalid trusted_proxies entry: %q", proxy)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			s.trustedProxies = append(s.trustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid trusted_proxies entry: %q", proxy)
+		}
+		s.trustedProxies = append(s.trustedProxies, ipNet)
+	}
+	return nil
+}
+
+// IsTrustedProxy reports whether the given IP address belongs to a trusted proxy.
+func (s *Settings) IsTrustedProxy(ip net.IP) bool {
+	for _, ipNet := range s.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the IP address of the client. If the request was sent by a trusted
+// proxy, the X-Forwarded-For entries are read from right to left and the first
+// address which does not belong to a trusted proxy is returned.
+func (s *Settings) ClientIP(req *http.Request) string {
+	clientIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		clientIP = req.RemoteAddr
+	}
+
+	if ip := net.ParseIP(clientIP); ip == nil || !s.IsTrustedProxy(ip) {
+		return clientIP
+	}
+
+	var forwarded []string
+	for _, xff := range req.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(xff, ",")...)
+	}
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		clientIP = ip.String()
+		if !s.IsTrustedProxy(ip) {
+			break
+		}
+	}
+	return clientIP
+}
@@ -7,15 +7,27 @@ import (
 
 var _ Inline = &Spa{}
 
+// SpaBootstrapDataSchema is used to decode the optional bootstrap_data expression
+// which must not be evaluated if unset.
+var SpaBootstrapDataSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "bootstrap_data"},
+	},
+}
+
 // Spa represents the <Spa> object.
 type Spa struct {
 	AccessControl        []string `hcl:"access_control,optional"`
 	BasePath             string   `hcl:"base_path,optional"`
 	BootstrapFile        string   `hcl:"bootstrap_file"`
 	CORS                 *CORS    `hcl:"cors,block"`
+	CSRF                 *CSRF    `hcl:"csrf,block"`
 	DisableAccessControl []string `hcl:"disable_access_control,optional"`
 	Paths                []string `hcl:"paths"`
 	Remain               hcl.Body `hcl:",remain"`
+
+	// BootstrapData is decoded from the Remain body, nil if unset.
+	BootstrapData hcl.Expression
 }
 
 // HCLBody implements the <Inline> interface.
@@ -0,0 +1,9 @@
+package config
+
+// Store represents the <Store> object.
+type Store struct {
+	DefaultTTL   string `hcl:"default_ttl,optional"`
+	MaxEntries   int    `hcl:"max_entries,optional"`
+	MaxValueSize string `hcl:"max_value_size,optional"`
+	Name         string `hcl:"name,label"`
+}
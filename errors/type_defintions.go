@@ -5,6 +5,12 @@ import "net/http"
 // Definitions holds all implemented ones. The name must match the structs
 // snake-name for fallback purposes. See TypeToSnake usage and reference.
 var Definitions = []*Error{
+	AccessControl.Kind("any_of"),
+
+	AccessControl.Kind("authorization"),
+
+	AccessControl.Kind("csrf"),
+
 	AccessControl.Kind("basic_auth").Status(http.StatusUnauthorized),
 	AccessControl.Kind("basic_auth").Kind("basic_auth_credentials_missing").Status(http.StatusUnauthorized),
 
@@ -12,11 +18,16 @@ var Definitions = []*Error{
 	AccessControl.Kind("jwt").Kind("jwt_token_expired"),
 	AccessControl.Kind("jwt").Kind("jwt_token_invalid"),
 	AccessControl.Kind("jwt").Kind("jwt_token_missing").Status(http.StatusUnauthorized),
+	AccessControl.Kind("jwt").Kind("jwt_token_revoked"),
+	AccessControl.Kind("jwt").Kind("jwt_dpop_invalid").Status(http.StatusUnauthorized),
 
 	AccessControl.Kind("oauth2"),
 
 	AccessControl.Kind("saml2"),
 
+	AccessControl.Kind("signature"),
+	AccessControl.Kind("signature").Kind("signature_invalid"),
+
 	AccessControl.Kind("scope").Kind("beta_operation_denied"),
 	AccessControl.Kind("scope").Kind("beta_insufficient_scope"),
 }
@@ -3,16 +3,23 @@
 package errors
 
 var (
-	BasicAuth                   = Definitions[0]
-	BasicAuthCredentialsMissing = Definitions[1]
-	Jwt                         = Definitions[2]
-	JwtTokenExpired             = Definitions[3]
-	JwtTokenInvalid             = Definitions[4]
-	JwtTokenMissing             = Definitions[5]
-	Oauth2                      = Definitions[6]
-	Saml2                       = Definitions[7]
-	BetaOperationDenied         = Definitions[8]
-	BetaInsufficientScope       = Definitions[9]
+	AnyOf                       = Definitions[0]
+	Authorization               = Definitions[1]
+	Csrf                        = Definitions[2]
+	BasicAuth                   = Definitions[3]
+	BasicAuthCredentialsMissing = Definitions[4]
+	Jwt                         = Definitions[5]
+	JwtTokenExpired             = Definitions[6]
+	JwtTokenInvalid             = Definitions[7]
+	JwtTokenMissing             = Definitions[8]
+	JwtTokenRevoked             = Definitions[9]
+	JwtDpopInvalid              = Definitions[10]
+	Oauth2                      = Definitions[11]
+	Saml2                       = Definitions[12]
+	Signature                   = Definitions[13]
+	SignatureInvalid            = Definitions[14]
+	BetaOperationDenied         = Definitions[15]
+	BetaInsufficientScope       = Definitions[16]
 )
 
 // typeDefinitions holds all related error definitions which are
@@ -22,14 +29,21 @@ type typeDefinitions map[string]*Error
 // types holds all implemented ones. The name must match the structs
 // snake-name for fallback purposes. See TypeToSnake usage and reference.
 var types = typeDefinitions{
+	"any_of":                         AnyOf,
+	"authorization":                  Authorization,
+	"csrf":                           Csrf,
 	"basic_auth":                     BasicAuth,
 	"basic_auth_credentials_missing": BasicAuthCredentialsMissing,
 	"jwt":                            Jwt,
 	"jwt_token_expired":              JwtTokenExpired,
 	"jwt_token_invalid":              JwtTokenInvalid,
 	"jwt_token_missing":              JwtTokenMissing,
+	"jwt_token_revoked":              JwtTokenRevoked,
+	"jwt_dpop_invalid":               JwtDpopInvalid,
 	"oauth2":                         Oauth2,
 	"saml2":                          Saml2,
+	"signature":                      Signature,
+	"signature_invalid":              SignatureInvalid,
 	"beta_operation_denied":          BetaOperationDenied,
 	"beta_insufficient_scope":        BetaInsufficientScope,
 }
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
 )
 
 type BufferOption uint8
@@ -30,7 +32,8 @@ func (i BufferOption) GoString() string {
 	return strings.Join(result, "|")
 }
 
-// MustBuffer determines if any of the hcl.bodies makes use of 'form_body' or 'json_body'.
+// MustBuffer determines if any of the hcl.bodies makes use of 'form_body', 'json_body',
+// 'xml_body' or 'multipart'.
 func MustBuffer(body hcl.Body) BufferOption {
 	result := BufferNone
 
@@ -56,11 +59,11 @@ func MustBuffer(body hcl.Body) BufferOption {
 			nameField := reflect.ValueOf(traversal[1]).FieldByName("Name")
 			name := nameField.String()
 			switch name {
-			case FormBody:
+			case FormBody, Multipart:
 				if rootName == ClientRequest {
 					result |= BufferRequest
 				}
-			case JsonBody:
+			case JsonBody, XmlBody:
 				switch rootName {
 				case ClientRequest:
 					result |= BufferRequest
@@ -72,3 +75,63 @@ func MustBuffer(body hcl.Body) BufferOption {
 	}
 	return result
 }
+
+// MustReadMultipartContent determines if any expression of the given body references the
+// content of 'request.multipart' files, e.g. 'request.multipart.files.doc[0].content'.
+// Bodies other than native syntax ones, e.g. JSON configurations, are expected to do so.
+func MustReadMultipartContent(body hcl.Body) bool {
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		return body != nil
+	}
+
+	var result bool
+	_ = hclsyntax.VisitAll(syntaxBody, func(node hclsyntax.Node) hcl.Diagnostics {
+		attr, isAttr := node.(*hclsyntax.Attribute)
+		if !isAttr || result {
+			return nil
+		}
+		for _, traversal := range attr.Expr.Variables() {
+			if referencesMultipartContent(traversal) {
+				result = true
+				break
+			}
+		}
+		return nil
+	})
+	return result
+}
+
+// referencesMultipartContent checks the file attribute of the given traversal. References of
+// the whole 'multipart' object, a files list or file object also include the content.
+func referencesMultipartContent(traversal hcl.Traversal) bool {
+	if len(traversal) < 2 || traversal.RootName() != ClientRequest || traversalStepName(traversal[1]) != Multipart {
+		return false
+	}
+	if len(traversal) < 3 {
+		return true
+	}
+	if traversalStepName(traversal[2]) != "files" {
+		return false
+	}
+
+	// files.<name>[<index>].<attribute>
+	for i := 4; i < len(traversal); i++ {
+		if name := traversalStepName(traversal[i]); name != "" {
+			return name == "content"
+		}
+	}
+	return true
+}
+
+func traversalStepName(step hcl.Traverser) string {
+	switch t := step.(type) {
+	case hcl.TraverseAttr:
+		return t.Name
+	case hcl.TraverseIndex:
+		if t.Key.Type() == cty.String && t.Key.IsKnown() && !t.Key.IsNull() {
+			return t.Key.AsString()
+		}
+	}
+	return ""
+}
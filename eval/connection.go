@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/config/request"
+)
+
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+// newConnectionVariables creates the client connection related request variables.
+// The remote_port is null if the remote_ip was read from a trusted proxy X-Forwarded-For header.
+func newConnectionVariables(ctx context.Context, req *http.Request) ContextMap {
+	host, port, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	remoteIP := host
+	if clientIP, ok := ctx.Value(request.ClientIP).(string); ok && clientIP != "" {
+		remoteIP = clientIP
+	}
+
+	remotePort := cty.NullVal(cty.Number)
+	if p, perr := strconv.ParseInt(port, 10, 64); perr == nil && remoteIP == host {
+		remotePort = cty.NumberIntVal(p)
+	}
+
+	receivedAt := cty.NullVal(cty.Number)
+	if startTime, ok := ctx.Value(request.StartTime).(time.Time); ok {
+		receivedAt = cty.NumberFloatVal(float64(startTime.UnixNano()/int64(time.Millisecond)) / 1000)
+	}
+
+	return ContextMap{
+		ProtocolVersion: cty.StringVal(req.Proto),
+		ReceivedAt:      receivedAt,
+		RemoteIP:        cty.StringVal(remoteIP),
+		RemotePort:      remotePort,
+		TLS:             newTLSVariable(req.TLS),
+	}
+}
+
+func newTLSVariable(state *tls.ConnectionState) cty.Value {
+	if state == nil {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+
+	clientCert := cty.NullVal(cty.DynamicPseudoType)
+	if len(state.PeerCertificates) > 0 {
+		clientCert = newCertificateVariable(state.PeerCertificates[0])
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"cipher":             cty.StringVal(tls.CipherSuiteName(state.CipherSuite)),
+		"client_certificate": clientCert,
+		"sni":                cty.StringVal(state.ServerName),
+		"version":            cty.StringVal(tlsVersions[state.Version]),
+	})
+}
+
+func newCertificateVariable(cert *x509.Certificate) cty.Value {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return cty.ObjectVal(map[string]cty.Value{
+		"dns_names":          stringListValue(cert.DNSNames),
+		"email_addresses":    stringListValue(cert.EmailAddresses),
+		"fingerprint_sha256": cty.StringVal(hex.EncodeToString(fingerprint[:])),
+		"issuer":             cty.StringVal(cert.Issuer.String()),
+		"not_after":          cty.NumberIntVal(cert.NotAfter.Unix()),
+		"not_before":         cty.NumberIntVal(cert.NotBefore.Unix()),
+		"serial_number":      cty.StringVal(cert.SerialNumber.String()),
+		"subject":            cty.StringVal(cert.Subject.String()),
+	})
+}
+
+// newTimingsVariable creates the backend response timings in milliseconds.
+func newTimingsVariable(bereq *http.Request) cty.Value {
+	holder, _ := bereq.Context().Value(request.BackendTimings).(*request.Timings)
+	values := holder.Values()
+	if values == nil {
+		return cty.NullVal(cty.DynamicPseudoType)
+	}
+
+	timings := make(map[string]cty.Value, len(values))
+	for name, ms := range values {
+		timings[name] = cty.NumberFloatVal(ms)
+	}
+	return cty.ObjectVal(timings)
+}
+
+func stringListValue(list []string) cty.Value {
+	if len(list) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	values := make([]cty.Value, len(list))
+	for i, s := range list {
+		values[i] = cty.StringVal(s)
+	}
+	return cty.ListVal(values)
+}
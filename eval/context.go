@@ -44,9 +44,12 @@ type Context struct {
 	eval              *hcl.EvalContext
 	inner             context.Context
 	memorize          map[string]interface{}
+	multipartContent  bool
 	oauth2            []config.OAuth2Authorization
 	jwtSigningConfigs map[string]*lib.JWTSigningConfig
 	saml              []*config.SAML
+	stores            map[string]*lib.KeyValueStore
+	userFunctions     []*lib.UserFunction
 }
 
 func NewContext(src []byte, defaults *config.Defaults) *Context {
@@ -107,9 +110,12 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		eval:              c.cloneEvalContext(),
 		inner:             c.inner,
 		memorize:          make(map[string]interface{}),
+		multipartContent:  c.multipartContent,
 		oauth2:            c.oauth2[:],
 		jwtSigningConfigs: c.jwtSigningConfigs,
 		saml:              c.saml[:],
+		stores:            c.stores,
+		userFunctions:     c.userFunctions,
 	}
 
 	if rc := req.Context(); rc != nil {
@@ -126,6 +132,11 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		id = uid
 	}
 
+	var csrfToken string
+	if token, ok := ctx.inner.Value(request.CSRFToken).(string); ok {
+		csrfToken = token
+	}
+
 	var pathParams request.PathParameter
 	if params, ok := ctx.inner.Value(request.PathParams).(request.PathParameter); ok {
 		pathParams = params
@@ -140,10 +151,11 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		}
 	}
 	port, _ := strconv.ParseInt(p, 10, 64)
-	body, jsonBody := parseReqBody(req)
+	body, jsonBody, xmlBody := parseReqBody(req)
 
 	origin := NewRawOrigin(req.URL)
 	ctx.eval.Variables[ClientRequest] = cty.ObjectVal(ctxMap.Merge(ContextMap{
+		CSRFToken: cty.StringVal(csrfToken),
 		ID:        cty.StringVal(id),
 		Method:    cty.StringVal(req.Method),
 		PathParam: seetie.MapToValue(pathParams),
@@ -156,8 +168,11 @@ func (c *Context) WithClientRequest(req *http.Request) *Context {
 		Query:     seetie.ValuesMapToValue(req.URL.Query()),
 		Body:      body,
 		JsonBody:  jsonBody,
+		XmlBody:   xmlBody,
 		FormBody:  seetie.ValuesMapToValue(parseForm(req).PostForm),
-	}.Merge(newVariable(ctx.inner, req.Cookies(), req.Header))))
+		Multipart: newMultipartVariable(req, ctx.multipartContent),
+	}.Merge(newConnectionVariables(ctx.inner, req)).
+		Merge(newVariable(ctx.inner, req.Cookies(), req.Header))))
 
 	ctx.updateRequestRelatedFunctions(origin)
 	ctx.updateFunctions()
@@ -171,9 +186,12 @@ func (c *Context) WithBeresps(beresps ...*http.Response) *Context {
 		eval:              c.cloneEvalContext(),
 		inner:             c.inner,
 		memorize:          c.memorize,
+		multipartContent:  c.multipartContent,
 		oauth2:            c.oauth2[:],
 		jwtSigningConfigs: c.jwtSigningConfigs,
 		saml:              c.saml[:],
+		stores:            c.stores,
+		userFunctions:     c.userFunctions,
 	}
 	ctx.inner = context.WithValue(c.inner, request.ContextType, ctx)
 
@@ -200,7 +218,7 @@ func (c *Context) WithBeresps(beresps ...*http.Response) *Context {
 		}
 		port, _ := strconv.ParseInt(p, 10, 64)
 
-		body, jsonBody := parseReqBody(bereq)
+		body, jsonBody, xmlBody := parseReqBody(bereq)
 		bereqs[name] = cty.ObjectVal(ContextMap{
 			Method:   cty.StringVal(bereq.Method),
 			URL:      cty.StringVal(bereq.URL.String()),
@@ -212,19 +230,22 @@ func (c *Context) WithBeresps(beresps ...*http.Response) *Context {
 			Query:    seetie.ValuesMapToValue(bereq.URL.Query()),
 			Body:     body,
 			JsonBody: jsonBody,
+			XmlBody:  xmlBody,
 			FormBody: seetie.ValuesMapToValue(parseForm(bereq).PostForm),
 		}.Merge(newVariable(ctx.inner, bereq.Cookies(), bereq.Header)))
 
-		var respBody, respJsonBody cty.Value
+		var respBody, respJsonBody, respXmlBody cty.Value
 		if !IsUpgradeResponse(bereq, beresp) {
 			if (ctx.bufferOption & BufferResponse) == BufferResponse {
-				respBody, respJsonBody = parseRespBody(beresp)
+				respBody, respJsonBody, respXmlBody = parseRespBody(beresp)
 			}
 		}
 		resps[name] = cty.ObjectVal(ContextMap{
 			HttpStatus: cty.NumberIntVal(int64(beresp.StatusCode)),
 			JsonBody:   respJsonBody,
+			XmlBody:    respXmlBody,
 			Body:       respBody,
+			Timings:    newTimingsVariable(bereq),
 		}.Merge(newVariable(ctx.inner, beresp.Cookies(), beresp.Header)))
 	}
 
@@ -249,6 +270,57 @@ func (c *Context) WithJWTSigningConfigs(configs map[string]*lib.JWTSigningConfig
 	return c
 }
 
+// WithUserFunctions initially sets up the functions defined with function blocks.
+func (c *Context) WithUserFunctions(fns []*lib.UserFunction) *Context {
+	c.userFunctions = fns
+	c.updateFunctions()
+	return c
+}
+
+// WithMultipartContent enables the base64 encoded content of request.multipart files,
+// see MustReadMultipartContent.
+func (c *Context) WithMultipartContent(enabled bool) *Context {
+	c.multipartContent = enabled
+	return c
+}
+
+// WithStores sets up the store functions with the given key/value stores.
+func (c *Context) WithStores(stores map[string]*lib.KeyValueStore) *Context {
+	c.stores = stores
+	c.updateFunctions()
+	return c
+}
+
+// WithEnvironmentRefs adds the environment variables referenced in the given source which are
+// not part of the configuration, e.g. for expressions evaluated on the command line.
+func (c *Context) WithEnvironmentRefs(src []byte, defaults *config.Defaults) *Context {
+	defaultEnvVariables := make(config.DefaultEnvVars)
+	if defaults != nil {
+		defaultEnvVariables = defaults.EnvironmentVariables
+	}
+
+	envVariables := make(map[string]cty.Value)
+	if current := c.eval.Variables[Environment]; current.LengthInt() > 0 {
+		envVariables = current.AsValueMap()
+	}
+
+	var missing []string
+	for _, key := range decodeEnvironmentRefs(src) {
+		if _, exist := envVariables[key]; !exist {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return c
+	}
+
+	for key, val := range newCtyEnvMap(missing, defaultEnvVariables).AsValueMap() {
+		envVariables[key] = val
+	}
+	c.eval.Variables[Environment] = cty.MapVal(envVariables)
+	return c
+}
+
 // WithOAuth2AC adds the OAuth2AC config structs.
 func (c *Context) WithOAuth2AC(os []*config.OAuth2AC) *Context {
 	if c.oauth2 == nil {
@@ -305,6 +377,15 @@ func (c *Context) getCodeVerifier() (*pkce.CodeVerifier, error) {
 func (c *Context) updateFunctions() {
 	jwtfn := lib.NewJwtSignFunction(c.jwtSigningConfigs, c.eval)
 	c.eval.Functions[lib.FnJWTSign] = jwtfn
+	c.eval.Functions[lib.FnJWKS] = lib.NewJWKSFunction(c.jwtSigningConfigs)
+	c.eval.Functions[lib.FnTemplateFile] = lib.NewTemplateFileFunction(c.eval)
+	c.eval.Functions[lib.FnStoreGet] = lib.NewStoreGetFunction(c.stores)
+	c.eval.Functions[lib.FnStoreIncr] = lib.NewStoreIncrFunction(c.stores)
+	c.eval.Functions[lib.FnStoreSet] = lib.NewStoreSetFunction(c.stores)
+
+	for _, fn := range c.userFunctions {
+		c.eval.Functions[fn.Name] = fn.Function(c.eval)
+	}
 }
 
 // updateRequestRelatedFunctions re-creates the listed functions for the client request context.
@@ -319,6 +400,8 @@ func (c *Context) updateRequestRelatedFunctions(origin *url.URL) {
 	if c.saml != nil {
 		samlfn := lib.NewSamlSsoUrlFunction(c.saml, origin)
 		c.eval.Functions[lib.FnSamlSsoUrl] = samlfn
+		c.eval.Functions[lib.FnSamlSloUrl] = lib.NewSamlSloUrlFunction(c.saml, origin)
+		c.eval.Functions[lib.FnSamlSpMetadata] = lib.NewSamlSpMetadataFunction(c.saml, origin)
 	}
 }
 
@@ -362,42 +445,55 @@ func isJSONMediaType(contentType string) bool {
 	return len(mParts) == 2 && mParts[0] == "application" && (mParts[1] == "json" || strings.HasSuffix(mParts[1], "+json"))
 }
 
-func parseReqBody(req *http.Request) (cty.Value, cty.Value) {
-	jsonBody := cty.EmptyObjectVal
+func isXMLMediaType(contentType string) bool {
+	m, _, _ := mime.ParseMediaType(contentType)
+	mParts := strings.Split(m, "/")
+	return len(mParts) == 2 && (mParts[0] == "application" || mParts[0] == "text") &&
+		(mParts[1] == "xml" || strings.HasSuffix(mParts[1], "+xml"))
+}
+
+func parseReqBody(req *http.Request) (cty.Value, cty.Value, cty.Value) {
+	jsonBody, xmlBody := cty.EmptyObjectVal, cty.EmptyObjectVal
 	if req == nil || req.GetBody == nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
 	body, _ := req.GetBody()
 	b, err := io.ReadAll(body)
 	if err != nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
-	if isJSONMediaType(req.Header.Get("Content-Type")) {
+	contentType := req.Header.Get("Content-Type")
+	if isJSONMediaType(contentType) {
 		jsonBody = parseJSONBytes(b)
+	} else if isXMLMediaType(contentType) {
+		xmlBody = parseXMLBytes(b)
 	}
-	return cty.StringVal(string(b)), jsonBody
+	return cty.StringVal(string(b)), jsonBody, xmlBody
 }
 
-func parseRespBody(beresp *http.Response) (cty.Value, cty.Value) {
-	jsonBody := cty.EmptyObjectVal
+func parseRespBody(beresp *http.Response) (cty.Value, cty.Value, cty.Value) {
+	jsonBody, xmlBody := cty.EmptyObjectVal, cty.EmptyObjectVal
 
 	if beresp == nil || beresp.Body == nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
 	b, err := io.ReadAll(beresp.Body)
 	if err != nil {
-		return cty.NilVal, jsonBody
+		return cty.NilVal, jsonBody, xmlBody
 	}
 
 	beresp.Body = io.NopCloser(bytes.NewBuffer(b)) // reset
 
-	if isJSONMediaType(beresp.Header.Get("Content-Type")) {
+	contentType := beresp.Header.Get("Content-Type")
+	if isJSONMediaType(contentType) {
 		jsonBody = parseJSONBytes(b)
+	} else if isXMLMediaType(contentType) {
+		xmlBody = parseXMLBytes(b)
 	}
-	return cty.StringVal(string(b)), jsonBody
+	return cty.StringVal(string(b)), jsonBody, xmlBody
 }
 
 func parseJSONBytes(b []byte) cty.Value {
@@ -413,6 +509,14 @@ func parseJSONBytes(b []byte) cty.Value {
 	return val
 }
 
+func parseXMLBytes(b []byte) cty.Value {
+	val, err := lib.DecodeXML(b)
+	if err != nil {
+		return cty.EmptyObjectVal
+	}
+	return val
+}
+
 func NewRawOrigin(u *url.URL) *url.URL {
 	rawOrigin := *u
 	rawOrigin.Path = ""
@@ -478,16 +582,40 @@ func newCtyCouperVariablesMap() cty.Value {
 // Functions
 func newFunctionsMap() map[string]function.Function {
 	return map[string]function.Function{
-		"base64_decode": lib.Base64DecodeFunc,
-		"base64_encode": lib.Base64EncodeFunc,
-		"coalesce":      stdlib.CoalesceFunc,
-		"json_decode":   stdlib.JSONDecodeFunc,
-		"json_encode":   stdlib.JSONEncodeFunc,
-		"merge":         lib.MergeFunc,
-		"to_lower":      stdlib.LowerFunc,
-		"to_upper":      stdlib.UpperFunc,
-		"unixtime":      lib.UnixtimeFunc,
-		"url_encode":    lib.UrlEncodeFunc,
+		"base64_decode":    lib.Base64DecodeFunc,
+		"base64_encode":    lib.Base64EncodeFunc,
+		"base64url_decode": lib.Base64URLDecodeFunc,
+		"base64url_encode": lib.Base64URLEncodeFunc,
+		"coalesce":         stdlib.CoalesceFunc,
+		"contains":         lib.ContainsFunc,
+		"format_time":      lib.FormatTimeFunc,
+		"hex_decode":       lib.HexDecodeFunc,
+		"hex_encode":       lib.HexEncodeFunc,
+		"hmac_sha256":      lib.HmacSha256Func,
+		"join":             stdlib.JoinFunc,
+		"json_decode":      stdlib.JSONDecodeFunc,
+		"json_encode":      stdlib.JSONEncodeFunc,
+		"jwt_decode":       lib.JwtDecodeFunc,
+		"merge":            lib.MergeFunc,
+		"parse_time":       lib.ParseTimeFunc,
+		"parse_url":        lib.ParseURLFunc,
+		"query_encode":     lib.QueryEncodeFunc,
+		"random_string":    lib.RandomStringFunc,
+		"regex_match":      lib.RegexMatchFunc,
+		"regex_replace":    lib.RegexReplaceFunc,
+		"sha256":           lib.Sha256Func,
+		"sha512":           lib.Sha512Func,
+		"split":            stdlib.SplitFunc,
+		"substr":           stdlib.SubstrFunc,
+		"to_lower":         stdlib.LowerFunc,
+		"to_upper":         stdlib.UpperFunc,
+		"trim":             lib.TrimFunc,
+		"unixtime":         lib.UnixtimeFunc,
+		"url_decode":       lib.UrlDecodeFunc,
+		"url_encode":       lib.UrlEncodeFunc,
+		"uuid":             lib.UUIDFunc,
+		"xml_decode":       lib.XMLDecodeFunc,
+		"xml_encode":       lib.XMLEncodeFunc,
 	}
 }
 
@@ -3,14 +3,20 @@ package eval_test
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsimple"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/avenga/couper/config/configload"
@@ -33,7 +39,7 @@ func TestNewHTTPContext(t *testing.T) {
 		}
 	}
 
-	baseCtx := eval.NewContext(nil, nil)
+	baseCtx := eval.NewContext(nil, nil).WithMultipartContent(true)
 
 	tests := []struct {
 		name      string
@@ -82,6 +88,27 @@ func TestNewHTTPContext(t *testing.T) {
 			method = request.method
 			title = request.json_body.json
 		`, http.Header{"method": {http.MethodGet}, "title": nil}},
+		{"Variables / POST /w xml body", http.MethodPost, http.Header{"Content-Type": {"application/soap+xml; charset=utf-8"}}, bytes.NewBufferString(`<?xml version="1.0"?>
+			<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope">
+				<soap:Body>
+					<order id="42"><item>a</item><item>b</item></order>
+				</soap:Body>
+			</soap:Envelope>`), "", baseCtx, `
+			id = request.xml_body.Envelope.Body.order["@id"]
+			items = request.xml_body.Envelope.Body.order.item
+		`, http.Header{"id": {"42"}, "items": {"a", "b"}}},
+		{"Variables / POST /w multipart body", http.MethodPost, http.Header{"Content-Type": {"multipart/form-data; boundary=couper"}}, bytes.NewBufferString("--couper\r\n" +
+			"Content-Disposition: form-data; name=\"user\"\r\n\r\nhans\r\n" +
+			"--couper\r\n" +
+			"Content-Disposition: form-data; name=\"doc\"; filename=\"a.txt\"\r\n" +
+			"Content-Type: text/plain\r\n\r\nfoo\r\n" +
+			"--couper--\r\n"), "", baseCtx, `
+			user = request.multipart.fields.user[0]
+			filename = request.multipart.files.doc[0].filename
+			content_type = request.multipart.files.doc[0].content_type
+			size = request.multipart.files.doc[0].size
+			content = request.multipart.files.doc[0].content
+		`, http.Header{"user": {"hans"}, "filename": {"a.txt"}, "content_type": {"text/plain"}, "size": {"3"}, "content": {"Zm9v"}}},
 	}
 
 	for _, tt := range tests {
@@ -248,3 +275,108 @@ func TestCouperVariables(t *testing.T) {
 		})
 	}
 }
+
+func TestConnectionVariables(t *testing.T) {
+	cert := &x509.Certificate{
+		DNSNames:     []string{"client.example.com"},
+		Issuer:       pkix.Name{CommonName: "Test CA"},
+		NotAfter:     time.Unix(1700000000, 0),
+		NotBefore:    time.Unix(1600000000, 0),
+		Raw:          []byte("raw"),
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "client", Organization: []string{"Couper"}},
+	}
+
+	receivedAt := time.Unix(1634567890, 123456789)
+
+	tests := []struct {
+		name     string
+		clientIP string
+		tls      *tls.ConnectionState
+		want     map[string]cty.Value
+	}{
+		{"plain", "", nil, map[string]cty.Value{
+			"request.remote_ip":        cty.StringVal("192.0.2.1"),
+			"request.remote_port":      cty.NumberIntVal(1234),
+			"request.protocol_version": cty.StringVal("HTTP/1.1"),
+			"request.received_at":      cty.NumberFloatVal(1634567890.123),
+			"request.tls == null":      cty.True,
+		}},
+		{"trusted proxy", "198.51.100.7", nil, map[string]cty.Value{
+			"request.remote_ip":           cty.StringVal("198.51.100.7"),
+			"request.remote_port == null": cty.True,
+		}},
+		{"tls", "", &tls.ConnectionState{
+			CipherSuite:      tls.TLS_AES_128_GCM_SHA256,
+			PeerCertificates: []*x509.Certificate{cert},
+			ServerName:       "couper.io",
+			Version:          tls.VersionTLS13,
+		}, map[string]cty.Value{
+			"request.tls.version":                               cty.StringVal("TLS 1.3"),
+			"request.tls.cipher":                                cty.StringVal("TLS_AES_128_GCM_SHA256"),
+			"request.tls.sni":                                   cty.StringVal("couper.io"),
+			"request.tls.client_certificate.subject":            cty.StringVal("CN=client,O=Couper"),
+			"request.tls.client_certificate.issuer":             cty.StringVal("CN=Test CA"),
+			"request.tls.client_certificate.serial_number":      cty.StringVal("42"),
+			"request.tls.client_certificate.not_after":          cty.NumberIntVal(1700000000),
+			"request.tls.client_certificate.dns_names[0]":       cty.StringVal("client.example.com"),
+			"request.tls.client_certificate.fingerprint_sha256": cty.StringVal("d7439bee24773bcbfa2d0a97947ee36227b10d1022b1a55847e928965bb6bfde"),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://couper.io/", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			req.TLS = tt.tls
+
+			ctx := context.WithValue(req.Context(), request.StartTime, receivedAt)
+			if tt.clientIP != "" {
+				ctx = context.WithValue(ctx, request.ClientIP, tt.clientIP)
+			}
+			*req = *req.WithContext(ctx)
+
+			hclCtx := eval.NewContext(nil, nil).WithClientRequest(req).HCLContext()
+
+			for src, want := range tt.want {
+				expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+				if diags.HasErrors() {
+					subT.Fatal(diags)
+				}
+
+				got, diags := expr.Value(hclCtx)
+				if diags.HasErrors() {
+					subT.Errorf("%s: %v", src, diags)
+					continue
+				}
+
+				if !got.RawEquals(want) {
+					subT.Errorf("%s: want: %#v, got: %#v", src, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMustReadMultipartContent(t *testing.T) {
+	for src, want := range map[string]bool{
+		`a = request.multipart.files.doc[0].content`:       true,
+		`a = request.multipart.files["doc"][0].content`:    true,
+		`a = request.multipart.files.doc[0]`:               true,
+		`a = request.multipart.files`:                      true,
+		`a = json_encode(request.multipart)`:               true,
+		`a = request.multipart.files.doc[0].filename`:      false,
+		`a = request.multipart.files.content[0].size`:      false,
+		`a = request.multipart.fields.content[0]`:          false,
+		`a = backend_responses.default.multipart.files`:    false,
+		`b { a = request.multipart.files.doc[0].content }`: true,
+	} {
+		body, diags := hclsyntax.ParseConfig([]byte(src), "test.hcl", hcl.InitialPos)
+		if diags.HasErrors() {
+			t.Fatal(diags)
+		}
+		if got := eval.MustReadMultipartContent(body.Body); got != want {
+			t.Errorf("%s: want %t, got %t", src, want, got)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
+)
+
+const (
+	randomStringAlphabet  = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	randomStringMaxLength = 1024
+)
+
+var (
+	HmacSha256Func   = newHmacFunction(sha256.New)
+	RandomStringFunc = newRandomStringFunction()
+	Sha256Func       = newHashFunction(sha256.New)
+	Sha512Func       = newHashFunction(sha512.New)
+	UUIDFunc         = newUUIDFunction()
+)
+
+func newHashFunction(newHash func() hash.Hash) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "s",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			h := newHash()
+			h.Write([]byte(args[0].AsString()))
+			return cty.StringVal(hex.EncodeToString(h.Sum(nil))), nil
+		},
+	})
+}
+
+func newHmacFunction(newHash func() hash.Hash) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "key",
+				Type: cty.String,
+			},
+			{
+				Name: "message",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			mac := hmac.New(newHash, []byte(args[0].AsString()))
+			mac.Write([]byte(args[1].AsString()))
+			return cty.StringVal(hex.EncodeToString(mac.Sum(nil))), nil
+		},
+	})
+}
+
+func newUUIDFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			b := make([]byte, 16)
+			if _, err = rand.Read(b); err != nil {
+				return cty.StringVal(""), err
+			}
+			b[6] = (b[6] & 0x0f) | 0x40 // version 4
+			b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+
+			return cty.StringVal(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])), nil
+		},
+	})
+}
+
+func newRandomStringFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "length",
+			Type: cty.Number,
+		}},
+		VarParam: &function.Parameter{
+			Name: "alphabet",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			var length int
+			if err = gocty.FromCtyValue(args[0], &length); err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+			if length < 1 || length > randomStringMaxLength {
+				return cty.StringVal(""), function.NewArgErrorf(0, "length must be between 1 and %d", randomStringMaxLength)
+			}
+
+			alphabet := randomStringAlphabet
+			if len(args) > 2 {
+				return cty.StringVal(""), function.NewArgErrorf(2, "too many arguments")
+			} else if len(args) == 2 {
+				alphabet = args[1].AsString()
+				if len(alphabet) < 2 {
+					return cty.StringVal(""), function.NewArgErrorf(1, "alphabet must contain at least two characters")
+				}
+			}
+
+			chars := []rune(alphabet)
+			max := big.NewInt(int64(len(chars)))
+			result := make([]rune, length)
+			for i := range result {
+				n, rerr := rand.Int(rand.Reader, max)
+				if rerr != nil {
+					return cty.StringVal(""), rerr
+				}
+				result[i] = chars[n.Int64()]
+			}
+			return cty.StringVal(string(result)), nil
+		},
+	})
+}
@@ -0,0 +1,118 @@
+package lib_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/config/configload"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/internal/test"
+)
+
+// evaluateExpressions evaluates the given expressions with the functions of the default context.
+func evaluateExpressions(t *testing.T, expressions map[string]cty.Value) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	for src, want := range expressions {
+		t.Run(src, func(subT *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			got, diags := expr.Value(hclContext)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			if !got.RawEquals(want) {
+				subT.Errorf("want: %#v, got: %#v", want, got)
+			}
+		})
+	}
+}
+
+func TestCryptoFunctions(t *testing.T) {
+	evaluateExpressions(t, map[string]cty.Value{
+		`sha256("")`:    cty.StringVal("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"),
+		`sha256("abc")`: cty.StringVal("ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"),
+		`sha512("abc")`: cty.StringVal("ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"),
+		`hmac_sha256("key", "The quick brown fox jumps over the lazy dog")`: cty.StringVal("f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"),
+		`regex_match(random_string(32), "^[A-Za-z0-9]{32}$")`:               cty.True,
+		`random_string(8, "aa")`:                                            cty.StringVal("aaaaaaaa"),
+		`regex_match(uuid(), "^[0-9a-f-]{36}$")`:                            cty.True,
+		`uuid() != uuid()`:                                                  cty.True,
+		`random_string(16) != random_string(16)`:                            cty.True,
+	})
+}
+
+func TestUUID(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	uuid, err := hclContext.Functions["uuid"].Call([]cty.Value{})
+	helper.Must(err)
+
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid.AsString()) {
+		t.Errorf("expected a version 4 uuid, got: %q", uuid.AsString())
+	}
+}
+
+func TestFunctionErrorRanges(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`server "test" {}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	tests := []struct {
+		src       string
+		expDetail string
+		expStart  int
+		expEnd    int
+	}{
+		{`random_string(0)`, "length must be between 1 and 1024", 15, 16},
+		{`random_string(8, "a")`, "alphabet must contain at least two characters", 19, 20},
+		{`regex_match("abc", "(")`, "invalid regular expression", 21, 22},
+		{`hex_decode("xyz")`, "encoding/hex: invalid byte", 13, 16},
+		{`format_time(0, "rfc3339", "Mars/Olympus")`, `invalid time zone: "Mars/Olympus"`, 28, 40},
+		{`jwt_decode("a.b")`, "token contains an invalid number of segments", 13, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.src, func(subT *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(tt.src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			_, diags = expr.Value(hclContext)
+			if !diags.HasErrors() {
+				subT.Fatal("expected an error")
+			}
+
+			diag := diags[0]
+			if !regexp.MustCompile(regexp.QuoteMeta(tt.expDetail)).MatchString(diag.Detail) {
+				subT.Errorf("expected detail %q, got: %q", tt.expDetail, diag.Detail)
+			}
+			if diag.Subject == nil || diag.Subject.Start.Column != tt.expStart || diag.Subject.End.Column != tt.expEnd {
+				subT.Errorf("expected subject columns %d-%d, got: %v", tt.expStart, tt.expEnd, diag.Subject)
+			}
+		})
+	}
+}
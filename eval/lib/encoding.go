@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+var (
+	Base64URLDecodeFunc = newBase64URLDecodeFunction()
+	Base64URLEncodeFunc = newBase64URLEncodeFunction()
+	HexDecodeFunc       = newHexDecodeFunction()
+	HexEncodeFunc       = newHexEncodeFunction()
+	JwtDecodeFunc       = newJwtDecodeFunction()
+)
+
+func newBase64URLDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "encoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			// padding is optional
+			encoded := strings.TrimRight(args[0].AsString(), "=")
+			result, err := base64.RawURLEncoding.DecodeString(encoded)
+			if err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+			return cty.StringVal(string(result)), nil
+		},
+	})
+}
+
+func newBase64URLEncodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "decoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			return cty.StringVal(base64.RawURLEncoding.EncodeToString([]byte(args[0].AsString()))), nil
+		},
+	})
+}
+
+func newHexDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "encoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			result, err := hex.DecodeString(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+			return cty.StringVal(string(result)), nil
+		},
+	})
+}
+
+func newHexEncodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "decoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			return cty.StringVal(hex.EncodeToString([]byte(args[0].AsString()))), nil
+		},
+	})
+}
+
+// newJwtDecodeFunction returns the header and claims of a JWT without verifying its signature.
+func newJwtDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "token",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			parts := strings.Split(args[0].AsString(), ".")
+			if len(parts) != 3 {
+				return cty.NilVal, function.NewArgErrorf(0, "token contains an invalid number of segments")
+			}
+
+			header, err := decodeJwtSegment(parts[0])
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "invalid header: %v", err)
+			}
+
+			claims, err := decodeJwtSegment(parts[1])
+			if err != nil {
+				return cty.NilVal, function.NewArgErrorf(0, "invalid claims: %v", err)
+			}
+
+			return cty.ObjectVal(map[string]cty.Value{
+				"header": header,
+				"claims": claims,
+			}), nil
+		},
+	})
+}
+
+func decodeJwtSegment(segment string) (cty.Value, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(segment, "="))
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	var obj map[string]json.RawMessage
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return cty.NilVal, err
+	}
+
+	impliedType, err := ctyjson.ImpliedType(b)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(b, impliedType)
+}
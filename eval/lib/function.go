@@ -0,0 +1,240 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/avenga/couper/config"
+)
+
+// dynamicFunctions are registered later on with their configuration or per client request.
+var dynamicFunctions = []string{
+	FnJWKS,
+	FnJWTSign,
+	FnOAuthAuthorizationUrl,
+	FnOAuthVerifier,
+	FnSamlSloUrl,
+	FnSamlSpMetadata,
+	FnSamlSsoUrl,
+	FnStoreGet,
+	FnStoreIncr,
+	FnStoreSet,
+	FnTemplateFile,
+	InternalFnOAuthHashedVerifier,
+}
+
+// UserFunction is a function defined with a function block. Its result expression
+// gets evaluated with the arguments as variables.
+type UserFunction struct {
+	Name   string
+	Params []string
+	Result hcl.Expression
+}
+
+// NewUserFunctions validates the given function definitions against the builtin functions.
+// Unknown references, recursive calls and invalid expressions are reported on load.
+func NewUserFunctions(confs []*config.Function, builtins map[string]function.Function) ([]*UserFunction, error) {
+	var diags hcl.Diagnostics
+
+	reserved := make(map[string]bool)
+	for name := range builtins {
+		reserved[name] = true
+	}
+	for _, name := range dynamicFunctions {
+		reserved[name] = true
+	}
+
+	userFunctions := make([]*UserFunction, 0, len(confs))
+	defRanges := make([]hcl.Range, 0, len(confs))
+	byName := make(map[string]*UserFunction, len(confs))
+	for _, conf := range confs {
+		subject := conf.DefRange.Ptr()
+
+		if !hclsyntax.ValidIdentifier(conf.Name) {
+			diags = diags.Append(newFunctionDiag(subject, "invalid function name: %q", conf.Name))
+			continue
+		}
+		if reserved[conf.Name] {
+			diags = diags.Append(newFunctionDiag(subject, "function %q: name is reserved by a builtin function", conf.Name))
+			continue
+		}
+		if _, exist := byName[conf.Name]; exist {
+			diags = diags.Append(newFunctionDiag(subject, "function %q: already defined", conf.Name))
+			continue
+		}
+
+		seen := make(map[string]bool, len(conf.Params))
+		for _, param := range conf.Params {
+			if !hclsyntax.ValidIdentifier(param) {
+				diags = diags.Append(newFunctionDiag(subject, "function %q: invalid param name: %q", conf.Name, param))
+			} else if seen[param] {
+				diags = diags.Append(newFunctionDiag(subject, "function %q: duplicate param: %q", conf.Name, param))
+			}
+			seen[param] = true
+		}
+
+		for _, traversal := range conf.Result.Variables() {
+			if root := traversal.RootName(); !seen[root] {
+				diags = diags.Append(newFunctionDiag(traversal.SourceRange().Ptr(),
+					"function %q: unknown variable %q, only params are available", conf.Name, root))
+			}
+		}
+
+		fn := &UserFunction{Name: conf.Name, Params: conf.Params, Result: conf.Result}
+		userFunctions = append(userFunctions, fn)
+		defRanges = append(defRanges, conf.DefRange)
+		byName[conf.Name] = fn
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	// references to other user functions
+	calls := make(map[string][]string, len(userFunctions))
+	for _, fn := range userFunctions {
+		expr, ok := fn.Result.(hclsyntax.Expression)
+		if !ok {
+			continue
+		}
+		_ = hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			if call, isCall := node.(*hclsyntax.FunctionCallExpr); isCall {
+				if _, isUserFn := byName[call.Name]; isUserFn {
+					calls[fn.Name] = append(calls[fn.Name], call.Name)
+				}
+			}
+			return nil
+		})
+	}
+
+	for i, fn := range userFunctions {
+		if cycle := findCallCycle(fn.Name, calls, nil); cycle != nil {
+			return nil, hcl.Diagnostics{newFunctionDiag(defRanges[i].Ptr(),
+				"function %q: recursive call: %s", fn.Name, strings.Join(cycle, " -> "))}
+		}
+	}
+
+	// Type check with unknown arguments. Dynamic functions are replaced by stubs
+	// since their configuration or request context is not available yet.
+	// Params are cty.DynamicVal, so only operations which fail for any argument
+	// type are reported, e.g. wrong argument counts or "a" * 2. Type errors
+	// depending on the actual arguments, e.g. a + 1 called with a string, occur
+	// on evaluation only.
+	checkCtx := &hcl.EvalContext{Functions: make(map[string]function.Function)}
+	for name, fn := range builtins {
+		checkCtx.Functions[name] = fn
+	}
+	for _, name := range dynamicFunctions {
+		checkCtx.Functions[name] = newStubFunction(nil, true)
+	}
+	for _, fn := range userFunctions {
+		checkCtx.Functions[fn.Name] = newStubFunction(fn.Params, false)
+	}
+
+	for _, fn := range userFunctions {
+		variables := make(map[string]cty.Value, len(fn.Params))
+		for _, param := range fn.Params {
+			variables[param] = cty.DynamicVal
+		}
+		fnCtx := checkCtx.NewChild()
+		fnCtx.Variables = variables
+		_, valueDiags := fn.Result.Value(fnCtx)
+		diags = diags.Extend(valueDiags)
+	}
+
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	return userFunctions, nil
+}
+
+// Function creates the callable function. Other functions are looked up in the given context.
+func (u *UserFunction) Function(ctx *hcl.EvalContext) function.Function {
+	return function.New(&function.Spec{
+		Params: functionParams(u.Params),
+		Type:   function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			variables := make(map[string]cty.Value, len(args))
+			for i, param := range u.Params {
+				variables[param] = args[i]
+			}
+
+			fnCtx := &hcl.EvalContext{
+				Functions: ctx.Functions,
+				Variables: variables,
+			}
+
+			result, diags := u.Result.Value(fnCtx)
+			if diags.HasErrors() {
+				return cty.DynamicVal, diags
+			}
+			return result, nil
+		},
+	})
+}
+
+func functionParams(names []string) []function.Parameter {
+	params := make([]function.Parameter, len(names))
+	for i, name := range names {
+		params[i] = function.Parameter{
+			Name:      name,
+			Type:      cty.DynamicPseudoType,
+			AllowNull: true,
+		}
+	}
+	return params
+}
+
+// newStubFunction creates a function with the given params returning an unknown value.
+func newStubFunction(params []string, variadic bool) function.Function {
+	spec := &function.Spec{
+		Params: functionParams(params),
+		Type:   function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			return cty.DynamicVal, nil
+		},
+	}
+	if variadic {
+		spec.VarParam = &function.Parameter{
+			Name:      "args",
+			Type:      cty.DynamicPseudoType,
+			AllowNull: true,
+		}
+	}
+	return function.New(spec)
+}
+
+// findCallCycle returns the call path of a recursion starting at the given function, if any.
+func findCallCycle(name string, calls map[string][]string, path []string) []string {
+	for i, visited := range path {
+		if visited == name {
+			cycle := append([]string{}, path[i:]...)
+			return append(cycle, name)
+		}
+	}
+
+	path = append(path, name)
+	callees := calls[name]
+	sort.Strings(callees)
+	for _, callee := range callees {
+		if cycle := findCallCycle(callee, calls, path); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+func newFunctionDiag(subject *hcl.Range, format string, args ...interface{}) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagError,
+		Summary:  fmt.Sprintf(format, args...),
+		Subject:  subject,
+	}
+}
@@ -0,0 +1,204 @@
+package lib_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/config/configload"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/internal/test"
+)
+
+func TestUserFunctions(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`
+server "test" {}
+definitions {
+  function "tenant_id" {
+    params = ["name"]
+    result = to_lower(trim(name))
+  }
+  function "redirect_url" {
+    params = ["base", "tenant", "path"]
+    result = "${base}/${tenant_id(tenant)}${path}"
+  }
+  function "answer" {
+    params = []
+    result = 42
+  }
+  function "first" {
+    params = ["a", "b"]
+    result = coalesce(a, b)
+  }
+}`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	tests := map[string]cty.Value{
+		`tenant_id("  ACME ")`:                                 cty.StringVal("acme"),
+		`redirect_url("https://couper.io", " ACME", "/login")`: cty.StringVal("https://couper.io/acme/login"),
+		`answer() + 1`:                                         cty.NumberIntVal(43),
+		`first(null, "b")`:                                     cty.StringVal("b"),
+	}
+
+	for src, want := range tests {
+		t.Run(src, func(subT *testing.T) {
+			expr, diags := hclsyntax.ParseExpression([]byte(src), "test.hcl", hcl.InitialPos)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			got, diags := expr.Value(hclContext)
+			if diags.HasErrors() {
+				subT.Fatal(diags)
+			}
+
+			if !got.RawEquals(want) {
+				subT.Errorf("want: %#v, got: %#v", want, got)
+			}
+		})
+	}
+
+	_, err = hclContext.Functions["tenant_id"].Call([]cty.Value{cty.StringVal("a"), cty.StringVal("b")})
+	if err == nil {
+		t.Error("expected an error for too many arguments")
+	}
+}
+
+func TestUserFunctions_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		functions string
+		expErr    string
+	}{
+		{"unknown variable", `function "f" {
+  params = ["a"]
+  result = a + b
+}`, `function "f": unknown variable "b", only params are available`},
+		{"request variable", `function "f" {
+  params = []
+  result = request.path
+}`, `function "f": unknown variable "request", only params are available`},
+		{"recursion", `function "f" {
+  params = ["a"]
+  result = a > 0 ? f(a - 1) : 0
+}`, `function "f": recursive call: f -> f`},
+		{"indirect recursion", `function "a" {
+  params = []
+  result = b()
+}
+function "b" {
+  params = []
+  result = c()
+}
+function "c" {
+  params = []
+  result = a()
+}`, `function "a": recursive call: a -> b -> c -> a`},
+		{"builtin name", `function "merge" {
+  params = []
+  result = 1
+}`, `function "merge": name is reserved by a builtin function`},
+		{"dynamic builtin name", `function "jwt_sign" {
+  params = []
+  result = 1
+}`, `function "jwt_sign": name is reserved by a builtin function`},
+		{"duplicate", `function "f" {
+  params = []
+  result = 1
+}
+function "f" {
+  params = []
+  result = 2
+}`, `function "f": already defined`},
+		{"duplicate param", `function "f" {
+  params = ["a", "a"]
+  result = a
+}`, `function "f": duplicate param: "a"`},
+		{"invalid param", `function "f" {
+  params = ["1a"]
+  result = 1
+}`, `function "f": invalid param name: "1a"`},
+		{"unknown function", `function "f" {
+  params = ["a"]
+  result = unknown(a)
+}`, `Call to unknown function`},
+		{"wrong argument count", `function "f" {
+  params = ["a"]
+  result = g(a, a)
+}
+function "g" {
+  params = ["a"]
+  result = a
+}`, `Too many function arguments`},
+		{"type error", `function "f" {
+  params = ["a"]
+  result = [a] + 1
+}`, `Invalid operand`},
+		{"literal type error", `function "f" {
+  params = []
+  result = "a" * 2
+}`, `Invalid operand`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(`server "test" {}
+definitions {
+`+tt.functions+`
+}`), "couper.hcl")
+			if err == nil {
+				subT.Fatal("expected an error")
+			}
+
+			if !strings.Contains(err.Error(), tt.expErr) {
+				subT.Errorf("expected error containing %q, got: %v", tt.expErr, err)
+			}
+		})
+	}
+}
+
+func TestUserFunctions_ErrorRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		functions string
+		expRange  string
+	}{
+		{"duplicate param", `function "f" {
+  params = ["a", "a"]
+  result = a
+}`, "couper.hcl:3,1-13"},
+		{"recursion", `function "f" {
+  params = []
+  result = 1
+}
+function "g" {
+  params = []
+  result = g()
+}`, "couper.hcl:7,1-13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := configload.LoadBytes([]byte(`server "test" {}
+definitions {
+`+tt.functions+`
+}`), "couper.hcl")
+			diags, ok := err.(hcl.Diagnostics)
+			if !ok || len(diags) != 1 {
+				subT.Fatalf("expected one diagnostic, got: %v", err)
+			}
+
+			if diags[0].Subject == nil || diags[0].Subject.String() != tt.expRange {
+				subT.Errorf("expected range %q, got: %v", tt.expRange, diags[0].Subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/avenga/couper/internal/seetie"
+)
+
+const FnJWKS = "jwks"
+
+// NewJWKSFunction creates a function returning a JWKS object with the public keys of the
+// given jwt_signing_profile or jwt labels. Without labels, all public signing keys with a key id
+// are listed, keys without one are skipped.
+// Multiple keys may be published at once to allow a key rotation.
+func NewJWKSFunction(jwtSigningConfigs map[string]*JWTSigningConfig) function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{
+			Name: "jwt_signing_profile_labels",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			var labels []string
+			for _, arg := range args {
+				labels = append(labels, arg.AsString())
+			}
+
+			if len(labels) == 0 {
+				for label, signingConfig := range jwtSigningConfigs {
+					if _, ok := signingConfig.Key.(*rsa.PrivateKey); ok && signingConfig.KeyID != "" {
+						labels = append(labels, label)
+					}
+				}
+				sort.Strings(labels)
+			}
+
+			if len(labels) == 0 {
+				return cty.NilVal, fmt.Errorf("missing jwt_signing_profile or jwt definitions with public keys and key ids")
+			}
+
+			var keys []interface{}
+			for _, label := range labels {
+				signingConfig := jwtSigningConfigs[label]
+				if signingConfig == nil {
+					return cty.NilVal, fmt.Errorf("missing jwt_signing_profile or jwt for given label: %s", label)
+				}
+
+				jwk, err := newPublicJWK(signingConfig)
+				if err != nil {
+					return cty.NilVal, fmt.Errorf("%s: %w", label, err)
+				}
+				keys = append(keys, jwk)
+			}
+
+			return seetie.MapToValue(map[string]interface{}{"keys": keys}), nil
+		},
+	})
+}
+
+func newPublicJWK(signingConfig *JWTSigningConfig) (map[string]interface{}, error) {
+	privateKey, ok := signingConfig.Key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("no public key for algorithm %s", signingConfig.SignatureAlgorithm)
+	}
+
+	if signingConfig.KeyID == "" {
+		return nil, fmt.Errorf("missing key id")
+	}
+
+	return map[string]interface{}{
+		"alg": signingConfig.SignatureAlgorithm,
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		"kid": signingConfig.KeyID,
+		"kty": "RSA",
+		"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		"use": "sig",
+	}, nil
+}
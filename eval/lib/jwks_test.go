@@ -0,0 +1,148 @@
+package lib_test
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+
+	"github.com/avenga/couper/config/configload"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/eval/lib"
+	"github.com/avenga/couper/internal/test"
+)
+
+func TestJWKS(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`
+	server "test" {
+	}
+	definitions {
+		jwt_signing_profile "current" {
+			signature_algorithm = "RS256"
+			key_file = "testdata/rsa_priv.pem"
+			key_id = "key-2"
+			ttl = "1h"
+		}
+		jwt "previous" {
+			signature_algorithm = "RS384"
+			key_file = "testdata/sp.crt"
+			signing_key_file = "testdata/sp.key"
+			signing_key_id = "key-1"
+			signing_ttl = "1h"
+		}
+		jwt_signing_profile "no-kid" {
+			signature_algorithm = "RS256"
+			key_file = "testdata/rsa_priv.pem"
+			ttl = "1h"
+		}
+		jwt_signing_profile "hmac" {
+			signature_algorithm = "HS256"
+			key = "$3cRe4"
+			ttl = "1h"
+		}
+	}
+	`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	publicKeys := func(args ...cty.Value) map[string]*rsa.PublicKey {
+		val, ferr := hclContext.Functions[lib.FnJWKS].Call(args)
+		helper.Must(ferr)
+
+		// the same encoding as json_body
+		b, ferr := stdlib.JSONEncode(val)
+		helper.Must(ferr)
+
+		var jwks struct {
+			Keys []map[string]interface{}
+		}
+		helper.Must(json.Unmarshal([]byte(b.AsString()), &jwks))
+
+		result := make(map[string]*rsa.PublicKey)
+		for _, jwk := range jwks.Keys {
+			if jwk["kty"] != "RSA" || jwk["use"] != "sig" {
+				t.Errorf("unexpected JWK: %#v", jwk)
+			}
+			n, _ := base64.RawURLEncoding.DecodeString(jwk["n"].(string))
+			e, _ := base64.RawURLEncoding.DecodeString(jwk["e"].(string))
+			result[jwk["kid"].(string)+"/"+jwk["alg"].(string)] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		}
+		return result
+	}
+
+	all := publicKeys()
+	if len(all) != 2 || all["key-1/RS384"] == nil || all["key-2/RS256"] == nil {
+		t.Fatalf("expected both public keys, got: %#v", all)
+	}
+
+	if selected := publicKeys(cty.StringVal("current")); len(selected) != 1 || selected["key-2/RS256"] == nil {
+		t.Errorf("expected the current public key only, got: %#v", selected)
+	}
+
+	for _, label := range []string{"current", "previous"} {
+		token, ferr := hclContext.Functions[lib.FnJWTSign].Call([]cty.Value{
+			cty.StringVal(label),
+			cty.ObjectVal(map[string]cty.Value{"sub": cty.StringVal("12345")}),
+		})
+		helper.Must(ferr)
+
+		_, ferr = jwt.Parse(token.AsString(), func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return all[kid+"/"+token.Method.Alg()], nil
+		})
+		if ferr != nil {
+			t.Errorf("%s: expected a token verifiable with the published key, got: %v", label, ferr)
+		}
+	}
+
+	_, err = hclContext.Functions[lib.FnJWKS].Call([]cty.Value{cty.StringVal("hmac")})
+	if err == nil || err.Error() != "hmac: no public key for algorithm HS256" {
+		t.Errorf("expected public key error, got: %v", err)
+	}
+
+	_, err = hclContext.Functions[lib.FnJWKS].Call([]cty.Value{cty.StringVal("unknown")})
+	if err == nil || err.Error() != "missing jwt_signing_profile or jwt for given label: unknown" {
+		t.Errorf("expected label error, got: %v", err)
+	}
+}
+
+func TestJWKS_MissingKeyID(t *testing.T) {
+	helper := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`
+	server "test" {
+	}
+	definitions {
+		jwt_signing_profile "MyToken" {
+			signature_algorithm = "RS256"
+			key_file = "testdata/rsa_priv.pem"
+			ttl = "0"
+		}
+	}
+	`), "couper.hcl")
+	helper.Must(err)
+
+	hclContext := cf.Context.Value(request.ContextType).(*eval.Context).HCLContext()
+
+	_, err = hclContext.Functions[lib.FnJWKS].Call(nil)
+	if err == nil || err.Error() != "missing jwt_signing_profile or jwt definitions with public keys and key ids" {
+		t.Errorf("expected missing keys error, got: %v", err)
+	}
+
+	_, err = hclContext.Functions[lib.FnJWKS].Call([]cty.Value{cty.StringVal("MyToken")})
+	if err == nil || err.Error() != "MyToken: missing key id" {
+		t.Errorf("expected key id error, got: %v", err)
+	}
+}
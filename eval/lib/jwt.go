@@ -26,6 +26,7 @@ var rsaParseError = &rsa.PrivateKey{}
 type JWTSigningConfig struct {
 	Claims             config.Claims
 	Key                interface{}
+	KeyID              string
 	Name               string
 	SignatureAlgorithm string
 	TTL                time.Duration
@@ -81,6 +82,7 @@ func NewJWTSigningConfigFromJWTSigningProfile(j *config.JWTSigningProfile) (*JWT
 	c := &JWTSigningConfig{
 		Claims:             j.Claims,
 		Key:                key,
+		KeyID:              j.KeyID,
 		Name:               j.Name,
 		SignatureAlgorithm: j.SignatureAlgorithm,
 		TTL:                ttl,
@@ -120,6 +122,7 @@ func NewJWTSigningConfigFromJWT(j *config.JWT) (*JWTSigningConfig, error) {
 	c := &JWTSigningConfig{
 		Claims:             j.Claims,
 		Key:                key,
+		KeyID:              j.SigningKeyID,
 		Name:               j.Name,
 		SignatureAlgorithm: j.SignatureAlgorithm,
 		TTL:                ttl,
@@ -185,7 +188,12 @@ func NewJwtSignFunction(jwtSigningConfigs map[string]*JWTSigningConfig, confCtx
 				mapClaims[k] = v
 			}
 
-			tokenString, err := CreateJWT(signingConfig.SignatureAlgorithm, signingConfig.Key, mapClaims)
+			var headers map[string]interface{}
+			if signingConfig.KeyID != "" {
+				headers = map[string]interface{}{"kid": signingConfig.KeyID}
+			}
+
+			tokenString, err := CreateJWTWithHeaders(signingConfig.SignatureAlgorithm, signingConfig.Key, mapClaims, headers)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
@@ -196,6 +204,11 @@ func NewJwtSignFunction(jwtSigningConfigs map[string]*JWTSigningConfig, confCtx
 }
 
 func CreateJWT(signatureAlgorithm string, key interface{}, mapClaims jwt.MapClaims) (string, error) {
+	return CreateJWTWithHeaders(signatureAlgorithm, key, mapClaims, nil)
+}
+
+// CreateJWTWithHeaders creates a signed JWT with the given additional JOSE header fields, e.g. "kid".
+func CreateJWTWithHeaders(signatureAlgorithm string, key interface{}, mapClaims jwt.MapClaims, headers map[string]interface{}) (string, error) {
 	signingMethod := jwt.GetSigningMethod(signatureAlgorithm)
 	if signingMethod == nil {
 		return "", fmt.Errorf("no signing method for given algorithm: %s", signatureAlgorithm)
@@ -203,6 +216,9 @@ func CreateJWT(signatureAlgorithm string, key interface{}, mapClaims jwt.MapClai
 
 	// create token
 	token := jwt.NewWithClaims(signingMethod, mapClaims)
+	for k, v := range headers {
+		token.Header[k] = v
+	}
 
 	// sign token
 	return token.SignedString(key)
@@ -4,9 +4,11 @@ import (
 	"encoding/xml"
 	"fmt"
 	"net/url"
+	"time"
 
 	saml2 "github.com/russellhaering/gosaml2"
 	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
 
@@ -14,27 +16,34 @@ import (
 )
 
 const (
+	FnSamlSloUrl            = "saml_slo_url"
+	FnSamlSpMetadata        = "saml_sp_metadata"
 	FnSamlSsoUrl            = "saml_sso_url"
 	NameIdFormatUnspecified = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
 )
 
-func NewSamlSsoUrlFunction(configs []*config.SAML, origin *url.URL) function.Function {
-	type entity struct {
-		config     *config.SAML
-		descriptor *types.EntityDescriptor
-		err        error
-	}
+type samlEntity struct {
+	config     *config.SAML
+	descriptor *types.EntityDescriptor
+	err        error
+}
 
-	samlEntities := make(map[string]*entity)
+func newSamlEntities(configs []*config.SAML) map[string]*samlEntity {
+	samlEntities := make(map[string]*samlEntity)
 	for _, conf := range configs {
 		metadata := &types.EntityDescriptor{}
 		err := xml.Unmarshal(conf.MetadataBytes, metadata)
-		samlEntities[conf.Name] = &entity{
+		samlEntities[conf.Name] = &samlEntity{
 			config:     conf,
 			descriptor: metadata,
 			err:        err,
 		}
 	}
+	return samlEntities
+}
+
+func NewSamlSsoUrlFunction(configs []*config.SAML, origin *url.URL) function.Function {
+	samlEntities := newSamlEntities(configs)
 
 	return function.New(&function.Spec{
 		Params: []function.Parameter{
@@ -51,42 +60,219 @@ func NewSamlSsoUrlFunction(configs []*config.SAML, origin *url.URL) function.Fun
 				return cty.StringVal(""), fmt.Errorf("undefined reference: %s", label)
 			}
 
-			metadata := ent.descriptor
-			var ssoUrl string
-			for _, ssoService := range metadata.IDPSSODescriptor.SingleSignOnServices {
-				if ssoService.Binding == "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" {
-					ssoUrl = ssoService.Location
-					continue
+			sp, err := NewSamlServiceProvider(ent.config, ent.descriptor, origin)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			var samlSsoUrl string
+			if sp.SignAuthnRequests {
+				// the redirect binding signs the query string instead of the request document
+				doc, derr := sp.BuildAuthRequestDocumentNoSig()
+				if derr != nil {
+					return cty.StringVal(""), derr
 				}
+				samlSsoUrl, err = sp.BuildAuthURLRedirect("", doc)
+			} else {
+				samlSsoUrl, err = sp.BuildAuthURL("")
+			}
+			if err != nil {
+				return cty.StringVal(""), err
 			}
 
-			nameIDFormat := getNameIDFormat(metadata.IDPSSODescriptor.NameIDFormats)
+			return cty.StringVal(samlSsoUrl), nil
+		},
+	})
+}
+
+func NewSamlSloUrlFunction(configs []*config.SAML, origin *url.URL) function.Function {
+	samlEntities := newSamlEntities(configs)
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "saml_label",
+				Type: cty.String,
+			},
+			{
+				Name: "name_id",
+				Type: cty.String,
+			},
+			{
+				Name: "session_index",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			ent, exist := samlEntities[label]
+			if !exist {
+				return cty.StringVal(""), fmt.Errorf("undefined reference: %s", label)
+			}
 
-			absAcsUrl, err := AbsoluteURL(ent.config.SpAcsUrl, origin)
+			sp, err := NewSamlServiceProvider(ent.config, ent.descriptor, origin)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
 
-			sp := &saml2.SAMLServiceProvider{
-				AssertionConsumerServiceURL: absAcsUrl,
-				IdentityProviderSSOURL:      ssoUrl,
-				ServiceProviderIssuer:       ent.config.SpEntityId,
-				SignAuthnRequests:           false,
+			sp.IdentityProviderSLOURL = getSloURL(ent.descriptor, saml2.BindingHttpRedirect)
+			if sp.IdentityProviderSLOURL == "" {
+				return cty.StringVal(""), fmt.Errorf("missing SingleLogoutService with %s binding in metadata", saml2.BindingHttpRedirect)
 			}
-			if nameIDFormat != "" {
-				sp.NameIdFormat = nameIDFormat
+
+			doc, err := sp.BuildLogoutRequestDocumentNoSig(args[1].AsString(), args[2].AsString())
+			if err != nil {
+				return cty.StringVal(""), err
 			}
 
-			samlSsoUrl, err := sp.BuildAuthURL("")
+			samlSloUrl, err := sp.BuildLogoutURLRedirect("", doc)
 			if err != nil {
 				return cty.StringVal(""), err
 			}
 
-			return cty.StringVal(samlSsoUrl), nil
+			return cty.StringVal(samlSloUrl), nil
+		},
+	})
+}
+
+func NewSamlSpMetadataFunction(configs []*config.SAML, origin *url.URL) function.Function {
+	samlEntities := newSamlEntities(configs)
+
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "saml_label",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			label := args[0].AsString()
+			ent, exist := samlEntities[label]
+			if !exist {
+				return cty.StringVal(""), fmt.Errorf("undefined reference: %s", label)
+			}
+
+			sp, err := NewSamlServiceProvider(ent.config, ent.descriptor, origin)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			var metadata *types.EntityDescriptor
+			if sp.SPKeyStore == nil {
+				metadata = newSpMetadataWithoutKeys(sp)
+			} else if sp.ServiceProviderSLOURL != "" {
+				metadata, err = sp.MetadataWithSLO(0)
+			} else {
+				metadata, err = sp.Metadata()
+			}
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			if sp.NameIdFormat != "" {
+				metadata.SPSSODescriptor.NameIDFormats = []string{sp.NameIdFormat}
+			}
+
+			b, err := xml.MarshalIndent(metadata, "", "  ")
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			return cty.StringVal(xml.Header + string(b)), nil
 		},
 	})
 }
 
+// newSpMetadataWithoutKeys creates the metadata like the service provider does,
+// but without the KeyDescriptors which require the SP key pair.
+func newSpMetadataWithoutKeys(sp *saml2.SAMLServiceProvider) *types.EntityDescriptor {
+	metadata := &types.EntityDescriptor{
+		ValidUntil: time.Now().UTC().Add(time.Hour * 24 * 7), // 7 days
+		EntityID:   sp.ServiceProviderIssuer,
+		SPSSODescriptor: &types.SPSSODescriptor{
+			AuthnRequestsSigned:        sp.SignAuthnRequests,
+			WantAssertionsSigned:       !sp.SkipSignatureValidation,
+			ProtocolSupportEnumeration: saml2.SAMLProtocolNamespace,
+			AssertionConsumerServices: []types.IndexedEndpoint{{
+				Binding:  saml2.BindingHttpPost,
+				Location: sp.AssertionConsumerServiceURL,
+				Index:    1,
+			}},
+		},
+	}
+
+	if sp.ServiceProviderSLOURL != "" {
+		metadata.SPSSODescriptor.SingleLogoutServices = []types.Endpoint{{
+			Binding:  saml2.BindingHttpPost,
+			Location: sp.ServiceProviderSLOURL,
+		}}
+	}
+
+	return metadata
+}
+
+// NewSamlServiceProvider creates the service provider of the given SAML configuration with
+// URLs relative to the given origin. A configured SP key pair signs the requests.
+func NewSamlServiceProvider(conf *config.SAML, metadata *types.EntityDescriptor, origin *url.URL) (*saml2.SAMLServiceProvider, error) {
+	absAcsUrl, err := AbsoluteURL(conf.SpAcsUrl, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	var absSloUrl string
+	if conf.SpSloUrl != "" {
+		if absSloUrl, err = AbsoluteURL(conf.SpSloUrl, origin); err != nil {
+			return nil, err
+		}
+	}
+
+	var ssoUrl string
+	if metadata.IDPSSODescriptor != nil {
+		for _, ssoService := range metadata.IDPSSODescriptor.SingleSignOnServices {
+			if ssoService.Binding == saml2.BindingHttpRedirect {
+				ssoUrl = ssoService.Location
+			}
+		}
+	}
+
+	sp := &saml2.SAMLServiceProvider{
+		AssertionConsumerServiceURL: absAcsUrl,
+		IdentityProviderIssuer:      metadata.EntityID,
+		IdentityProviderSSOURL:      ssoUrl,
+		ServiceProviderIssuer:       conf.SpEntityId,
+		ServiceProviderSLOURL:       absSloUrl,
+		SignAuthnRequests:           false,
+	}
+
+	if metadata.IDPSSODescriptor != nil {
+		if nameIDFormat := getNameIDFormat(metadata.IDPSSODescriptor.NameIDFormats); nameIDFormat != "" {
+			sp.NameIdFormat = nameIDFormat
+		}
+	}
+
+	if conf.SpKeyPair != nil {
+		sp.SPKeyStore = dsig.TLSCertKeyStore(*conf.SpKeyPair)
+		sp.SignAuthnRequests = true
+		sp.SignAuthnRequestsAlgorithm = dsig.RSASHA256SignatureMethod
+	}
+
+	return sp, nil
+}
+
+func getSloURL(metadata *types.EntityDescriptor, binding string) string {
+	if metadata.IDPSSODescriptor == nil {
+		return ""
+	}
+	for _, sloService := range metadata.IDPSSODescriptor.SingleLogoutServices {
+		if sloService.Binding == binding {
+			return sloService.Location
+		}
+	}
+	return ""
+}
+
 func getNameIDFormat(supportedNameIDFormats []types.NameIDFormat) string {
 	nameIDFormat := ""
 	if isSupportedNameIDFormat(supportedNameIDFormats, NameIdFormatUnspecified) {
@@ -3,14 +3,21 @@ package lib_test
 import (
 	"bytes"
 	"compress/flate"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"encoding/xml"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/russellhaering/gosaml2/types"
 	"github.com/zclconf/go-cty/cty"
 
 	"github.com/avenga/couper/config/configload"
@@ -138,3 +145,151 @@ func Test_SamlSsoUrl(t *testing.T) {
 	}
 
 }
+
+func Test_SamlSignedRequests(t *testing.T) {
+	h := test.New(t)
+	cf, err := configload.LoadBytes([]byte(`
+		server "test" {
+		}
+		definitions {
+			saml "MySAML" {
+				idp_metadata_file = "testdata/idp-metadata.xml"
+				sp_entity_id = "the-sp"
+				sp_acs_url = "/saml/acs"
+				sp_slo_url = "/saml/slo"
+				sp_certificate_file = "testdata/sp.crt"
+				sp_private_key_file = "testdata/sp.key"
+			}
+		}
+		`), "couper.hcl")
+	h.Must(err)
+
+	certFile, err := os.ReadFile("testdata/sp.crt")
+	h.Must(err)
+	pemBlock, _ := pem.Decode(certFile)
+	certificate, err := x509.ParseCertificate(pemBlock.Bytes)
+	h.Must(err)
+
+	evalContext := cf.Context.Value(request.ContextType).(*eval.Context)
+	req, err := http.NewRequest(http.MethodGet, "https://www.example.com/foo", nil)
+	h.Must(err)
+	evalContext = evalContext.WithClientRequest(req)
+	functions := evalContext.HCLContext().Functions
+
+	verify := func(subT *testing.T, redirectURL string) string {
+		u, err := url.Parse(redirectURL)
+		h.Must(err)
+
+		q := u.Query()
+		if sigAlg := q.Get("SigAlg"); sigAlg != "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256" {
+			subT.Errorf("unexpected SigAlg: %q", sigAlg)
+		}
+
+		signature, err := base64.StdEncoding.DecodeString(q.Get("Signature"))
+		h.Must(err)
+		signed := "SAMLRequest=" + url.QueryEscape(q.Get("SAMLRequest")) + "&SigAlg=" + url.QueryEscape(q.Get("SigAlg"))
+		digest := sha256.Sum256([]byte(signed))
+		if err = rsa.VerifyPKCS1v15(certificate.PublicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+			subT.Errorf("invalid signature: %v", err)
+		}
+
+		b64Decoded, err := base64.StdEncoding.DecodeString(q.Get("SAMLRequest"))
+		h.Must(err)
+		deflated, err := io.ReadAll(flate.NewReader(bytes.NewReader(b64Decoded)))
+		h.Must(err)
+		return string(deflated)
+	}
+
+	t.Run(lib.FnSamlSsoUrl, func(subT *testing.T) {
+		ssoUrl, err := functions[lib.FnSamlSsoUrl].Call([]cty.Value{cty.StringVal("MySAML")})
+		h.Must(err)
+
+		if !strings.HasPrefix(ssoUrl.AsString(), "https://idp.example.org/saml/SSOService?") {
+			subT.Errorf("unexpected URL: %q", ssoUrl.AsString())
+		}
+		authnRequest := verify(subT, ssoUrl.AsString())
+		if !strings.Contains(authnRequest, `AssertionConsumerServiceURL="https://www.example.com/saml/acs"`) {
+			subT.Errorf("unexpected AuthnRequest: %s", authnRequest)
+		}
+	})
+
+	t.Run(lib.FnSamlSloUrl, func(subT *testing.T) {
+		sloUrl, err := functions[lib.FnSamlSloUrl].Call([]cty.Value{cty.StringVal("MySAML"), cty.StringVal("me"), cty.StringVal("s1")})
+		h.Must(err)
+
+		if !strings.HasPrefix(sloUrl.AsString(), "https://idp.example.org/saml/SLOService?") {
+			subT.Errorf("unexpected URL: %q", sloUrl.AsString())
+		}
+		logoutRequest := verify(subT, sloUrl.AsString())
+		if !strings.Contains(logoutRequest, ">me</saml:NameID>") || !strings.Contains(logoutRequest, ">s1</samlp:SessionIndex>") {
+			subT.Errorf("unexpected LogoutRequest: %s", logoutRequest)
+		}
+	})
+
+	t.Run(lib.FnSamlSpMetadata, func(subT *testing.T) {
+		metadata, err := functions[lib.FnSamlSpMetadata].Call([]cty.Value{cty.StringVal("MySAML")})
+		h.Must(err)
+
+		entity := &types.EntityDescriptor{}
+		h.Must(xml.Unmarshal([]byte(metadata.AsString()), entity))
+
+		if entity.EntityID != "the-sp" {
+			subT.Errorf("unexpected entityID: %q", entity.EntityID)
+		}
+		sp := entity.SPSSODescriptor
+		if sp == nil || !sp.AuthnRequestsSigned {
+			subT.Fatalf("expected SPSSODescriptor with AuthnRequestsSigned, got: %#v", sp)
+		}
+		if len(sp.AssertionConsumerServices) != 1 || sp.AssertionConsumerServices[0].Location != "https://www.example.com/saml/acs" {
+			subT.Errorf("unexpected AssertionConsumerService: %#v", sp.AssertionConsumerServices)
+		}
+		if len(sp.SingleLogoutServices) != 1 || sp.SingleLogoutServices[0].Location != "https://www.example.com/saml/slo" {
+			subT.Errorf("unexpected SingleLogoutService: %#v", sp.SingleLogoutServices)
+		}
+		if len(sp.KeyDescriptors) != 2 || sp.KeyDescriptors[0].KeyInfo.X509Data.X509Certificates[0].Data != base64.StdEncoding.EncodeToString(certificate.Raw) {
+			subT.Errorf("unexpected KeyDescriptors: %#v", sp.KeyDescriptors)
+		}
+	})
+}
+
+func Test_SamlSpMetadata_WithoutKeyPair(t *testing.T) {
+	h := test.New(t)
+
+	cf, err := configload.LoadBytes([]byte(`
+	server "test" {
+	}
+	definitions {
+		saml "MySAML" {
+			idp_metadata_file = "testdata/idp-metadata.xml"
+			sp_entity_id = "the-sp"
+			sp_acs_url = "/saml/acs"
+		}
+	}
+	`), "couper.hcl")
+	h.Must(err)
+
+	evalContext := cf.Context.Value(request.ContextType).(*eval.Context)
+	req, err := http.NewRequest(http.MethodGet, "https://www.example.com/foo", nil)
+	h.Must(err)
+	evalContext = evalContext.WithClientRequest(req)
+
+	metadata, err := evalContext.HCLContext().Functions[lib.FnSamlSpMetadata].Call([]cty.Value{cty.StringVal("MySAML")})
+	h.Must(err)
+
+	entity := &types.EntityDescriptor{}
+	h.Must(xml.Unmarshal([]byte(metadata.AsString()), entity))
+
+	sp := entity.SPSSODescriptor
+	if entity.EntityID != "the-sp" || sp == nil || sp.AuthnRequestsSigned {
+		t.Fatalf("expected SPSSODescriptor without AuthnRequestsSigned, got: %#v", sp)
+	}
+	if len(sp.AssertionConsumerServices) != 1 || sp.AssertionConsumerServices[0].Location != "https://www.example.com/saml/acs" {
+		t.Errorf("unexpected AssertionConsumerService: %#v", sp.AssertionConsumerServices)
+	}
+	if len(sp.SingleLogoutServices) != 0 {
+		t.Errorf("expected no SingleLogoutService, got: %#v", sp.SingleLogoutServices)
+	}
+	if len(sp.KeyDescriptors) != 0 {
+		t.Errorf("expected no KeyDescriptors, got: %#v", sp.KeyDescriptors)
+	}
+}
@@ -0,0 +1,296 @@
+package lib
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/avenga/couper/cache"
+)
+
+const (
+	FnStoreGet  = "store_get"
+	FnStoreIncr = "store_incr"
+	FnStoreSet  = "store_set"
+
+	// storeMaxTTL is the maximum lifetime of a memory store entry.
+	storeMaxTTL = 24 * time.Hour
+)
+
+// KeyValueStore holds the typed values of a store block in the memory store.
+type KeyValueStore struct {
+	defaultTTL   time.Duration
+	expires      map[string]time.Time
+	maxEntries   int
+	maxValueSize int64
+	memStore     *cache.MemoryStore
+	mu           sync.Mutex
+	name         string
+}
+
+// NewKeyValueStore creates a new KeyValueStore object. The number of entries and the size
+// of their JSON representation are limited.
+func NewKeyValueStore(name string, maxEntries int, maxValueSize int64, defaultTTL time.Duration,
+	memStore *cache.MemoryStore) (*KeyValueStore, error) {
+	if maxEntries < 1 {
+		return nil, fmt.Errorf("max_entries must be positive")
+	}
+	if maxValueSize < 1 {
+		return nil, fmt.Errorf("max_value_size must be positive")
+	}
+	if err := checkStoreTTL(defaultTTL); err != nil {
+		return nil, fmt.Errorf("default_ttl %w", err)
+	}
+	if memStore == nil {
+		return nil, fmt.Errorf("store requires a memory store")
+	}
+
+	return &KeyValueStore{
+		defaultTTL:   defaultTTL,
+		expires:      make(map[string]time.Time),
+		maxEntries:   maxEntries,
+		maxValueSize: maxValueSize,
+		memStore:     memStore,
+		name:         name,
+	}, nil
+}
+
+// Get returns the value of the given key or null.
+func (s *KeyValueStore) Get(key string) cty.Value {
+	if v, ok := s.memStore.Get(s.storeKey(key)).(cty.Value); ok {
+		return v
+	}
+	return cty.NullVal(cty.DynamicPseudoType)
+}
+
+// Set stores the value for the given duration. A null value removes the key.
+func (s *KeyValueStore) Set(key string, val cty.Value, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.set(key, val, ttl)
+}
+
+// Incr adds delta to the number value of the given key and returns the result. An existing
+// key keeps its lifetime, a missing one is created with the given duration.
+func (s *KeyValueStore) Incr(key string, delta cty.Value, ttl time.Duration) (cty.Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.Get(key)
+	if current.IsNull() {
+		current = cty.Zero
+	} else if current.Type() != cty.Number {
+		return cty.NilVal, fmt.Errorf("value of key %q is not a number", key)
+	} else if expAt, exist := s.expires[key]; exist {
+		ttl = time.Until(expAt)
+		if ttl < time.Second {
+			ttl = time.Second
+		}
+	}
+
+	result := current.Add(delta)
+	if err := s.set(key, result, ttl); err != nil {
+		return cty.NilVal, err
+	}
+	return result, nil
+}
+
+func (s *KeyValueStore) set(key string, val cty.Value, ttl time.Duration) error {
+	k := s.storeKey(key)
+
+	if val.IsNull() {
+		s.memStore.Del(k)
+		delete(s.expires, key)
+		return nil
+	}
+
+	if !val.IsWhollyKnown() {
+		return fmt.Errorf("value must be known")
+	}
+
+	if err := checkStoreTTL(ttl); err != nil {
+		return fmt.Errorf("ttl %w", err)
+	}
+
+	b, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return err
+	}
+	if int64(len(b)) > s.maxValueSize {
+		return fmt.Errorf("store %q: value exceeds max_value_size of %d bytes", s.name, s.maxValueSize)
+	}
+
+	now := time.Now()
+	if _, exist := s.expires[key]; !exist && len(s.expires) >= s.maxEntries {
+		for name, expAt := range s.expires {
+			if !now.Before(expAt) {
+				delete(s.expires, name)
+			}
+		}
+		if len(s.expires) >= s.maxEntries {
+			return fmt.Errorf("store %q: max_entries of %d exceeded", s.name, s.maxEntries)
+		}
+	}
+
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	s.memStore.Set(k, val, seconds)
+	s.expires[key] = now.Add(time.Duration(seconds) * time.Second)
+	return nil
+}
+
+func (s *KeyValueStore) storeKey(key string) string {
+	return "store|" + s.name + "|" + key
+}
+
+func checkStoreTTL(ttl time.Duration) error {
+	if ttl < time.Second || ttl > storeMaxTTL {
+		return fmt.Errorf("must be between 1s and %s", storeMaxTTL)
+	}
+	return nil
+}
+
+func NewStoreGetFunction(stores map[string]*KeyValueStore) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "store_label",
+				Type: cty.String,
+			},
+			{
+				Name: "key",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			store, err := lookupStore(stores, args[0])
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return store.Get(args[1].AsString()), nil
+		},
+	})
+}
+
+func NewStoreSetFunction(stores map[string]*KeyValueStore) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "store_label",
+				Type: cty.String,
+			},
+			{
+				Name: "key",
+				Type: cty.String,
+			},
+			{
+				Name:      "value",
+				Type:      cty.DynamicPseudoType,
+				AllowNull: true,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "ttl",
+			Type: cty.String,
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			return args[2].Type(), nil
+		},
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			store, err := lookupStore(stores, args[0])
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			ttl, err := storeTTL(store, args, 3)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			if err = store.Set(args[1].AsString(), args[2], ttl); err != nil {
+				return cty.NilVal, function.NewArgError(2, err)
+			}
+			return args[2], nil
+		},
+	})
+}
+
+func NewStoreIncrFunction(stores map[string]*KeyValueStore) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "store_label",
+				Type: cty.String,
+			},
+			{
+				Name: "key",
+				Type: cty.String,
+			},
+			{
+				Name: "delta",
+				Type: cty.Number,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "ttl",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			store, err := lookupStore(stores, args[0])
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			ttl, err := storeTTL(store, args, 3)
+			if err != nil {
+				return cty.NilVal, err
+			}
+
+			return store.Incr(args[1].AsString(), args[2], ttl)
+		},
+	})
+}
+
+func lookupStore(stores map[string]*KeyValueStore, label cty.Value) (*KeyValueStore, error) {
+	if len(stores) == 0 {
+		return nil, fmt.Errorf("missing store definitions")
+	}
+
+	var name string
+	if err := gocty.FromCtyValue(label, &name); err != nil {
+		return nil, function.NewArgError(0, err)
+	}
+
+	store, exist := stores[name]
+	if !exist {
+		return nil, function.NewArgErrorf(0, "missing store for given label: %s", name)
+	}
+	return store, nil
+}
+
+// storeTTL parses the optional ttl argument at the given position.
+func storeTTL(store *KeyValueStore, args []cty.Value, pos int) (time.Duration, error) {
+	if len(args) <= pos {
+		return store.defaultTTL, nil
+	}
+	if len(args) > pos+1 {
+		return 0, function.NewArgErrorf(pos+1, "too many arguments")
+	}
+
+	ttl, err := time.ParseDuration(args[pos].AsString())
+	if err != nil {
+		return 0, function.NewArgError(pos, err)
+	}
+	if err = checkStoreTTL(ttl); err != nil {
+		return 0, function.NewArgErrorf(pos, "ttl %v", err)
+	}
+	return ttl, nil
+}
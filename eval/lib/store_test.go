@@ -0,0 +1,151 @@
+package lib_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/eval/lib"
+	"github.com/avenga/couper/internal/test"
+)
+
+func newTestStores(t *testing.T, maxEntries int, maxValueSize int64) map[string]*lib.KeyValueStore {
+	log, _ := test.NewLogger()
+	quitCh := make(chan struct{})
+	t.Cleanup(func() { close(quitCh) })
+	memStore := cache.New(log.WithContext(context.Background()), quitCh)
+
+	store, err := lib.NewKeyValueStore("test", maxEntries, maxValueSize, time.Hour, memStore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]*lib.KeyValueStore{"test": store}
+}
+
+func TestStoreFunctions(t *testing.T) {
+	helper := test.New(t)
+
+	functions := eval.NewContext(nil, nil).WithStores(newTestStores(t, 10, 1024)).HCLContext().Functions
+	get, set, incr := functions[lib.FnStoreGet], functions[lib.FnStoreSet], functions[lib.FnStoreIncr]
+
+	store, key := cty.StringVal("test"), cty.StringVal("key")
+
+	val, err := get.Call([]cty.Value{store, key})
+	helper.Must(err)
+	if !val.IsNull() {
+		t.Errorf("expected null for a missing key, got: %#v", val)
+	}
+
+	obj := cty.ObjectVal(map[string]cty.Value{
+		"list":   cty.ListVal([]cty.Value{cty.StringVal("a")}),
+		"number": cty.NumberIntVal(1),
+	})
+	_, err = set.Call([]cty.Value{store, key, obj})
+	helper.Must(err)
+
+	val, err = get.Call([]cty.Value{store, key})
+	helper.Must(err)
+	if !val.RawEquals(obj) {
+		t.Errorf("expected typed value %#v, got: %#v", obj, val)
+	}
+
+	_, err = set.Call([]cty.Value{store, key, cty.NullVal(cty.DynamicPseudoType)})
+	helper.Must(err)
+	val, err = get.Call([]cty.Value{store, key})
+	helper.Must(err)
+	if !val.IsNull() {
+		t.Errorf("expected removed key, got: %#v", val)
+	}
+
+	counter := cty.StringVal("counter")
+	for i := 1; i <= 3; i++ {
+		val, err = incr.Call([]cty.Value{store, counter, cty.NumberIntVal(2), cty.StringVal("1m")})
+		helper.Must(err)
+		if !val.RawEquals(cty.NumberIntVal(int64(i * 2))) {
+			t.Errorf("expected counter %d, got: %#v", i*2, val)
+		}
+	}
+
+	_, err = set.Call([]cty.Value{store, key, cty.StringVal("string")})
+	helper.Must(err)
+	if _, err = incr.Call([]cty.Value{store, key, cty.NumberIntVal(1)}); err == nil {
+		t.Error("expected an error for a non-number value")
+	}
+}
+
+func TestStoreFunctions_Expiry(t *testing.T) {
+	helper := test.New(t)
+
+	functions := eval.NewContext(nil, nil).WithStores(newTestStores(t, 10, 1024)).HCLContext().Functions
+	store, key := cty.StringVal("test"), cty.StringVal("key")
+
+	_, err := functions[lib.FnStoreSet].Call([]cty.Value{store, key, cty.True, cty.StringVal("1s")})
+	helper.Must(err)
+
+	// the counter keeps the initial lifetime
+	_, err = functions[lib.FnStoreIncr].Call([]cty.Value{store, cty.StringVal("counter"), cty.NumberIntVal(1), cty.StringVal("1s")})
+	helper.Must(err)
+	_, err = functions[lib.FnStoreIncr].Call([]cty.Value{store, cty.StringVal("counter"), cty.NumberIntVal(1), cty.StringVal("1h")})
+	helper.Must(err)
+
+	time.Sleep(time.Second + 100*time.Millisecond)
+
+	for _, k := range []string{"key", "counter"} {
+		val, err := functions[lib.FnStoreGet].Call([]cty.Value{store, cty.StringVal(k)})
+		helper.Must(err)
+		if !val.IsNull() {
+			t.Errorf("expected expired key %q, got: %#v", k, val)
+		}
+	}
+}
+
+func TestStoreFunctions_Errors(t *testing.T) {
+	functions := eval.NewContext(nil, nil).WithStores(newTestStores(t, 2, 16)).HCLContext().Functions
+	set := functions[lib.FnStoreSet]
+
+	tests := []struct {
+		name   string
+		args   []cty.Value
+		expErr string
+	}{
+		{"missing store", []cty.Value{cty.StringVal("missing"), cty.StringVal("k"), cty.True}, "missing store for given label: missing"},
+		{"value size", []cty.Value{cty.StringVal("test"), cty.StringVal("k"), cty.StringVal(strings.Repeat("a", 16))}, `store "test": value exceeds max_value_size of 16 bytes`},
+		{"invalid ttl", []cty.Value{cty.StringVal("test"), cty.StringVal("k"), cty.True, cty.StringVal("1x")}, "unknown unit"},
+		{"ttl range", []cty.Value{cty.StringVal("test"), cty.StringVal("k"), cty.True, cty.StringVal("25h")}, "ttl must be between 1s and 24h0m0s"},
+		{"too many arguments", []cty.Value{cty.StringVal("test"), cty.StringVal("k"), cty.True, cty.StringVal("1s"), cty.StringVal("1s")}, "too many arguments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			_, err := set.Call(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.expErr) {
+				subT.Errorf("expected error containing %q, got: %v", tt.expErr, err)
+			}
+		})
+	}
+
+	helper := test.New(t)
+	for _, k := range []string{"a", "b"} {
+		_, err := set.Call([]cty.Value{cty.StringVal("test"), cty.StringVal(k), cty.True})
+		helper.Must(err)
+	}
+
+	// overwriting an existing key is allowed
+	_, err := set.Call([]cty.Value{cty.StringVal("test"), cty.StringVal("a"), cty.False})
+	helper.Must(err)
+
+	_, err = set.Call([]cty.Value{cty.StringVal("test"), cty.StringVal("c"), cty.True})
+	if err == nil || !strings.Contains(err.Error(), `store "test": max_entries of 2 exceeded`) {
+		t.Errorf("expected max_entries error, got: %v", err)
+	}
+
+	_, err = eval.NewContext(nil, nil).WithStores(nil).HCLContext().Functions[lib.FnStoreGet].Call([]cty.Value{cty.StringVal("test"), cty.StringVal("a")})
+	if err == nil || err.Error() != "missing store definitions" {
+		t.Errorf("expected missing store definitions error, got: %v", err)
+	}
+}
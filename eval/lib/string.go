@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var (
+	ContainsFunc     = newContainsFunction()
+	RegexMatchFunc   = newRegexMatchFunction()
+	RegexReplaceFunc = newRegexReplaceFunction()
+	TrimFunc         = newTrimFunction()
+)
+
+// newContainsFunction checks whether a string contains a substring or whether
+// a list, tuple or set contains an element.
+func newContainsFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "haystack",
+				Type: cty.DynamicPseudoType,
+			},
+			{
+				Name: "needle",
+				Type: cty.DynamicPseudoType,
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			haystack, needle := args[0], args[1]
+			if haystack.IsNull() || !haystack.IsKnown() {
+				return cty.False, function.NewArgErrorf(0, "must not be null")
+			}
+
+			ty := haystack.Type()
+			switch {
+			case ty == cty.String:
+				if needle.IsNull() || needle.Type() != cty.String {
+					return cty.False, function.NewArgErrorf(1, "must be a string")
+				}
+				return cty.BoolVal(strings.Contains(haystack.AsString(), needle.AsString())), nil
+			case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+				for it := haystack.ElementIterator(); it.Next(); {
+					_, v := it.Element()
+					if v.Type().Equals(needle.Type()) && v.Equals(needle).True() {
+						return cty.True, nil
+					}
+				}
+				return cty.False, nil
+			default:
+				return cty.False, function.NewArgErrorf(0, "must be a string, list, tuple or set, got %s", ty.FriendlyName())
+			}
+		},
+	})
+}
+
+func newRegexMatchFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "str",
+				Type: cty.String,
+			},
+			{
+				Name: "pattern",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			re, err := regexp.Compile(args[1].AsString())
+			if err != nil {
+				return cty.False, function.NewArgErrorf(1, "invalid regular expression: %v", err)
+			}
+			return cty.BoolVal(re.MatchString(args[0].AsString())), nil
+		},
+	})
+}
+
+func newRegexReplaceFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "str",
+				Type: cty.String,
+			},
+			{
+				Name: "pattern",
+				Type: cty.String,
+			},
+			{
+				Name: "replacement",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			re, err := regexp.Compile(args[1].AsString())
+			if err != nil {
+				return cty.StringVal(""), function.NewArgErrorf(1, "invalid regular expression: %v", err)
+			}
+			return cty.StringVal(re.ReplaceAllString(args[0].AsString(), args[2].AsString())), nil
+		},
+	})
+}
+
+// newTrimFunction removes leading and trailing whitespace or, if given, the characters of the cutset.
+func newTrimFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "str",
+			Type: cty.String,
+		}},
+		VarParam: &function.Parameter{
+			Name: "cutset",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			switch len(args) {
+			case 1:
+				return cty.StringVal(strings.TrimSpace(args[0].AsString())), nil
+			case 2:
+				return cty.StringVal(strings.Trim(args[0].AsString(), args[1].AsString())), nil
+			default:
+				return cty.StringVal(""), function.NewArgErrorf(2, "too many arguments")
+			}
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package lib_test
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestStringFunctions(t *testing.T) {
+	evaluateExpressions(t, map[string]cty.Value{
+		`contains("couper", "oup")`:                              cty.True,
+		`contains("couper", "x")`:                                cty.False,
+		`contains(["a", "b"], "b")`:                              cty.True,
+		`contains(["a", 1], 1)`:                                  cty.True,
+		`contains(["a", "b"], "c")`:                              cty.False,
+		`regex_match("2021-10-01", "^\\d{4}-\\d{2}-\\d{2}$")`:    cty.True,
+		`regex_match("2021-10", "^\\d{4}-\\d{2}-\\d{2}$")`:       cty.False,
+		`regex_replace("a1b22c333", "[0-9]+", "-")`:              cty.StringVal("a-b-c-"),
+		`regex_replace("john.doe", "(\\w+)\\.(\\w+)", "$2, $1")`: cty.StringVal("doe, john"),
+		`split(",", "a,b,c")`:                                    cty.ListVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b"), cty.StringVal("c")}),
+		`join("-", ["a", "b", "c"])`:                             cty.StringVal("a-b-c"),
+		`trim("  couper \n")`:                                    cty.StringVal("couper"),
+		`trim("--couper--", "-")`:                                cty.StringVal("couper"),
+		`substr("couper", 1, 3)`:                                 cty.StringVal("oup"),
+		`substr("couper", -3, -1)`:                               cty.StringVal("per"),
+	})
+}
@@ -0,0 +1,234 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const (
+	FnTemplateFile = "template_file"
+
+	templateFnEscapeJSON = "escape_json"
+	templateFnJSON       = "json"
+)
+
+var templateCache = &fileTemplateCache{templates: make(map[string]*fileTemplate)}
+
+type templateExecutor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+type fileTemplate struct {
+	modTime time.Time
+	tpl     templateExecutor
+}
+
+// fileTemplateCache holds the parsed templates by their absolute file path.
+// A template gets parsed again if the modification time of its file has changed.
+type fileTemplateCache struct {
+	mu        sync.RWMutex
+	templates map[string]*fileTemplate
+}
+
+func (c *fileTemplateCache) get(path string) (templateExecutor, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	cached, exist := c.templates[absPath]
+	c.mu.RUnlock()
+	if exist && cached.modTime.Equal(info.ModTime()) {
+		return cached.tpl, nil
+	}
+
+	src, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, err := parseTemplate(filepath.Base(absPath), src)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.templates[absPath] = &fileTemplate{modTime: info.ModTime(), tpl: tpl}
+	c.mu.Unlock()
+
+	return tpl, nil
+}
+
+// NewTemplateFileFunction creates a function rendering a Go template file with the variables
+// of the given evaluation context. Output values are escaped according to the file extension:
+// *.html and *.htm files are escaped contextually, *.json files escape values for JSON strings.
+func NewTemplateFileFunction(ctx *hcl.EvalContext) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "path",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			tpl, err := templateCache.get(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+
+			data := make(map[string]interface{}, len(ctx.Variables))
+			for name, val := range ctx.Variables {
+				data[name] = valueToInterface(val)
+			}
+
+			result := &bytes.Buffer{}
+			if err = tpl.Execute(result, data); err != nil {
+				return cty.StringVal(""), err
+			}
+			return cty.StringVal(result.String()), nil
+		},
+	})
+}
+
+func parseTemplate(name string, src []byte) (templateExecutor, error) {
+	funcs := map[string]interface{}{
+		templateFnEscapeJSON: escapeJSON,
+		templateFnJSON:       encodeJSON,
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".html", ".htm":
+		return htmltemplate.New(name).Funcs(funcs).Parse(string(src))
+	case ".json":
+		tpl, err := template.New(name).Funcs(funcs).Parse(string(src))
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tpl.Templates() {
+			if t.Tree != nil {
+				appendEscapeJSON(t.Tree.Root)
+			}
+		}
+		return tpl, nil
+	default:
+		return template.New(name).Funcs(funcs).Parse(string(src))
+	}
+}
+
+// appendEscapeJSON appends the escape_json function to all output pipelines,
+// except the ones already encoded with the json function.
+func appendEscapeJSON(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			appendEscapeJSON(child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		cmds := n.Pipe.Cmds
+		if last := cmds[len(cmds)-1]; len(last.Args) > 0 {
+			if ident, ok := last.Args[0].(*parse.IdentifierNode); ok && ident.Ident == templateFnJSON {
+				return
+			}
+		}
+		n.Pipe.Cmds = append(cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(templateFnEscapeJSON).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		appendEscapeJSON(n.List)
+		appendEscapeJSON(n.ElseList)
+	case *parse.RangeNode:
+		appendEscapeJSON(n.List)
+		appendEscapeJSON(n.ElseList)
+	case *parse.WithNode:
+		appendEscapeJSON(n.List)
+		appendEscapeJSON(n.ElseList)
+	}
+}
+
+// escapeJSON returns the given value as escaped JSON string content.
+func escapeJSON(v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		s = fmt.Sprint(v)
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return string(b[1 : len(b)-1]), nil
+}
+
+func encodeJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// valueToInterface converts the given value to its Go representation
+// without losing nested objects and lists.
+func valueToInterface(val cty.Value) interface{} {
+	if val.Type() == cty.NilType || val.IsNull() || !val.IsWhollyKnown() {
+		return nil
+	}
+
+	ty := val.Type()
+	switch {
+	case ty == cty.String:
+		return val.AsString()
+	case ty == cty.Bool:
+		return val.True()
+	case ty == cty.Number:
+		bf := val.AsBigFloat()
+		if bf.IsInt() {
+			if i, acc := bf.Int64(); acc == 0 {
+				return i
+			}
+		}
+		f, _ := bf.Float64()
+		return f
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		result := make([]interface{}, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			result = append(result, valueToInterface(v))
+		}
+		return result
+	case ty.IsMapType() || ty.IsObjectType():
+		result := make(map[string]interface{}, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			k, v := it.Element()
+			result[k.AsString()] = valueToInterface(v)
+		}
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package lib_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/eval"
+	"github.com/avenga/couper/eval/lib"
+	"github.com/avenga/couper/internal/test"
+)
+
+func TestNewTemplateFileFunction(t *testing.T) {
+	helper := test.New(t)
+
+	helper.Must(os.Setenv("TEMPLATE_TEST", `"quoted"`))
+	defer os.Unsetenv("TEMPLATE_TEST")
+
+	req, err := http.NewRequest(http.MethodGet, "https://couper.io/?name=<b>Couper</b>", nil)
+	helper.Must(err)
+	*req = *req.Clone(context.Background())
+
+	beresp := &http.Response{
+		Body:       io.NopCloser(bytes.NewBufferString(`{"items": ["a&b", "c\"d"]}`)),
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Request:    req,
+		StatusCode: http.StatusOK,
+	}
+
+	ctx := eval.NewContext([]byte(`env.TEMPLATE_TEST`), &config.Defaults{}).
+		WithJWTSigningConfigs(nil).
+		WithClientRequest(req).
+		WithBeresps(beresp)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"testdata/templates/greeting.html", "<h1>Hello &lt;b&gt;Couper&lt;/b&gt;!</h1>\n<ul>\n  <li>a&amp;b</li>\n  <li>c&#34;d</li>\n</ul>\nok\n"},
+		{"testdata/templates/greeting.json", "{\n  \"greeting\": \"Hello \\u003cb\\u003eCouper\\u003c/b\\u003e!\",\n  \"items\": [\"a\\u0026b\",\"c\\\"d\"],\n  \"count\": 2,\n  \"env\": \"\\\"quoted\\\"\"\n}\n"},
+		{"testdata/templates/greeting.txt", "a&b, c\"d for <b>Couper</b>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(subT *testing.T) {
+			h := test.New(subT)
+
+			val, err := ctx.HCLContext().Functions[lib.FnTemplateFile].Call([]cty.Value{cty.StringVal(tt.file)})
+			h.Must(err)
+
+			if got := val.AsString(); got != tt.want {
+				subT.Errorf("want:\n%q\ngot:\n%q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNewTemplateFileFunction_Errors(t *testing.T) {
+	helper := test.New(t)
+
+	fn := eval.NewContext(nil, nil).WithJWTSigningConfigs(nil).HCLContext().Functions[lib.FnTemplateFile]
+
+	_, err := fn.Call([]cty.Value{cty.StringVal("testdata/templates/missing.html")})
+	if err == nil {
+		t.Error("expected an error for a missing file")
+	}
+
+	dir, err := os.MkdirTemp("", "templates")
+	helper.Must(err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "invalid.txt")
+	helper.Must(os.WriteFile(file, []byte("{{ if }}"), 0644))
+
+	_, err = fn.Call([]cty.Value{cty.StringVal(file)})
+	if err == nil {
+		t.Error("expected a parse error")
+	}
+
+	helper.Must(os.WriteFile(file, []byte("reloaded"), 0644))
+	helper.Must(os.Chtimes(file, time.Now(), time.Now().Add(time.Second)))
+
+	val, err := fn.Call([]cty.Value{cty.StringVal(file)})
+	helper.Must(err)
+	if val.AsString() != "reloaded" {
+		t.Errorf("expected reloaded template, got: %q", val.AsString())
+	}
+}
@@ -1,16 +1,41 @@
 package lib
 
 import (
+	"strings"
 	"time"
+	_ "time/tzdata" // time zones independent of the host system
 
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
 )
 
 var (
-	UnixtimeFunc = newUnixtimeFunction()
+	FormatTimeFunc = newFormatTimeFunction()
+	ParseTimeFunc  = newParseTimeFunction()
+	UnixtimeFunc   = newUnixtimeFunction()
 )
 
+// timeLayouts are the named layouts which can be used instead of a Go reference time layout.
+var timeLayouts = map[string]string{
+	"ansic":        time.ANSIC,
+	"date":         "2006-01-02",
+	"datetime":     "2006-01-02 15:04:05",
+	"http":         "Mon, 02 Jan 2006 15:04:05 GMT",
+	"iso8601":      "2006-01-02T15:04:05Z07:00",
+	"iso8601basic": "20060102T150405Z0700",
+	"rfc1123":      time.RFC1123,
+	"rfc1123z":     time.RFC1123Z,
+	"rfc3339":      time.RFC3339,
+	"rfc3339nano":  time.RFC3339Nano,
+	"rfc822":       time.RFC822,
+	"rfc822z":      time.RFC822Z,
+	"rfc850":       time.RFC850,
+	"rubydate":     time.RubyDate,
+	"time":         "15:04:05",
+	"unixdate":     time.UnixDate,
+}
+
 func newUnixtimeFunction() function.Function {
 	return function.New(&function.Spec{
 		Params: []function.Parameter{},
@@ -20,3 +45,98 @@ func newUnixtimeFunction() function.Function {
 		},
 	})
 }
+
+// newFormatTimeFunction formats a UNIX timestamp with the given layout in UTC or the given time zone.
+func newFormatTimeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "timestamp",
+				Type: cty.Number,
+			},
+			{
+				Name: "layout",
+				Type: cty.String,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "timezone",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			var timestamp int64
+			if err = gocty.FromCtyValue(args[0], &timestamp); err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+
+			location, err := timeLocation(args)
+			if err != nil {
+				return cty.StringVal(""), err
+			}
+
+			layout := timeLayout(args[1].AsString())
+			t := time.Unix(timestamp, 0).In(location)
+			if layout == timeLayouts["http"] {
+				t = t.UTC()
+			}
+			return cty.StringVal(t.Format(layout)), nil
+		},
+	})
+}
+
+// newParseTimeFunction parses a time string with the given layout and returns the UNIX timestamp.
+// Times without zone information are interpreted in UTC or the given time zone.
+func newParseTimeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name: "time",
+				Type: cty.String,
+			},
+			{
+				Name: "layout",
+				Type: cty.String,
+			},
+		},
+		VarParam: &function.Parameter{
+			Name: "timezone",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.Number),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			location, err := timeLocation(args)
+			if err != nil {
+				return cty.NumberIntVal(0), err
+			}
+
+			t, err := time.ParseInLocation(timeLayout(args[1].AsString()), args[0].AsString(), location)
+			if err != nil {
+				return cty.NumberIntVal(0), function.NewArgError(0, err)
+			}
+			return cty.NumberIntVal(t.Unix()), nil
+		},
+	})
+}
+
+func timeLayout(layout string) string {
+	if named, exist := timeLayouts[strings.ToLower(layout)]; exist {
+		return named
+	}
+	return layout
+}
+
+func timeLocation(args []cty.Value) (*time.Location, error) {
+	if len(args) > 3 {
+		return nil, function.NewArgErrorf(3, "too many arguments")
+	}
+	if len(args) < 3 {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(args[2].AsString())
+	if err != nil {
+		return nil, function.NewArgErrorf(2, "invalid time zone: %q", args[2].AsString())
+	}
+	return location, nil
+}
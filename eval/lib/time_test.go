@@ -39,3 +39,16 @@ func fuzzyEqual(a, b, fuzz int64) bool {
 	return b <= a+fuzz &&
 		b >= a-fuzz
 }
+
+func TestTimeFunctions(t *testing.T) {
+	evaluateExpressions(t, map[string]cty.Value{
+		`format_time(1634567890, "rfc3339")`:                                        cty.StringVal("2021-10-18T14:38:10Z"),
+		`format_time(1634567890, "RFC3339", "Europe/Berlin")`:                       cty.StringVal("2021-10-18T16:38:10+02:00"),
+		`format_time(1634567890, "http", "Europe/Berlin")`:                          cty.StringVal("Mon, 18 Oct 2021 14:38:10 GMT"),
+		`format_time(1634567890, "02.01.2006 15:04", "America/New_York")`:           cty.StringVal("18.10.2021 10:38"),
+		`parse_time("2021-10-18T16:38:10+02:00", "rfc3339")`:                        cty.NumberIntVal(1634567890),
+		`parse_time("18.10.2021 16:38:10", "02.01.2006 15:04:05", "Europe/Berlin")`: cty.NumberIntVal(1634567890),
+		`parse_time("2021-10-18", "date")`:                                          cty.NumberIntVal(1634515200),
+		`parse_time(format_time(1634567890, "rubydate"), "rubydate")`:               cty.NumberIntVal(1634567890),
+	})
+}
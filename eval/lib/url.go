@@ -2,14 +2,19 @@ package lib
 
 import (
 	"net/url"
+	"sort"
 	"strings"
 
 	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/function"
 )
 
 var (
-	UrlEncodeFunc = newUrlEncodeFunction()
+	ParseURLFunc    = newParseURLFunction()
+	QueryEncodeFunc = newQueryEncodeFunction()
+	UrlDecodeFunc   = newUrlDecodeFunction()
+	UrlEncodeFunc   = newUrlEncodeFunction()
 )
 
 func newUrlEncodeFunction() function.Function {
@@ -27,6 +32,145 @@ func newUrlEncodeFunction() function.Function {
 	})
 }
 
+func newUrlDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "s",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			result, err := url.QueryUnescape(args[0].AsString())
+			if err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+			return cty.StringVal(result), nil
+		},
+	})
+}
+
+// newParseURLFunction returns the components of the given URL as object.
+func newParseURLFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "url",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.Object(map[string]cty.Type{
+			"scheme":   cty.String,
+			"username": cty.String,
+			"password": cty.String,
+			"host":     cty.String,
+			"hostname": cty.String,
+			"port":     cty.String,
+			"origin":   cty.String,
+			"path":     cty.String,
+			"query":    cty.Map(cty.List(cty.String)),
+			"fragment": cty.String,
+		})),
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			u, err := url.Parse(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(retType), function.NewArgError(0, err)
+			}
+
+			query := make(map[string]cty.Value)
+			for name, values := range u.Query() {
+				list := make([]cty.Value, len(values))
+				for i, v := range values {
+					list[i] = cty.StringVal(v)
+				}
+				query[name] = cty.ListVal(list)
+			}
+			queryVal := cty.MapValEmpty(cty.List(cty.String))
+			if len(query) > 0 {
+				queryVal = cty.MapVal(query)
+			}
+
+			password, _ := u.User.Password()
+			var origin string
+			if u.Scheme != "" && u.Host != "" {
+				origin = u.Scheme + "://" + u.Host
+			}
+
+			return cty.ObjectVal(map[string]cty.Value{
+				"scheme":   cty.StringVal(u.Scheme),
+				"username": cty.StringVal(u.User.Username()),
+				"password": cty.StringVal(password),
+				"host":     cty.StringVal(u.Host),
+				"hostname": cty.StringVal(u.Hostname()),
+				"port":     cty.StringVal(u.Port()),
+				"origin":   cty.StringVal(origin),
+				"path":     cty.StringVal(u.Path),
+				"query":    queryVal,
+				"fragment": cty.StringVal(u.Fragment),
+			}), nil
+		},
+	})
+}
+
+// newQueryEncodeFunction encodes an object into a query string sorted by key. Attribute
+// values may be primitive values or lists of them; null values are omitted.
+func newQueryEncodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "params",
+			Type: cty.DynamicPseudoType,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			params := args[0]
+			if params.IsNull() {
+				return cty.StringVal(""), nil
+			}
+
+			ty := params.Type()
+			if !ty.IsObjectType() && !ty.IsMapType() {
+				return cty.StringVal(""), function.NewArgErrorf(0, "must be an object, got %s", ty.FriendlyName())
+			}
+
+			query := url.Values{}
+			for it := params.ElementIterator(); it.Next(); {
+				k, v := it.Element()
+				name := k.AsString()
+				if v.IsNull() {
+					continue
+				}
+
+				values := []cty.Value{v}
+				if vt := v.Type(); vt.IsListType() || vt.IsTupleType() || vt.IsSetType() {
+					values = v.AsValueSlice()
+				}
+
+				for _, value := range values {
+					if value.IsNull() {
+						continue
+					}
+					s, cerr := convert.Convert(value, cty.String)
+					if cerr != nil {
+						return cty.StringVal(""), function.NewArgErrorf(0, "invalid value for %q: %v", name, cerr)
+					}
+					query.Add(name, s.AsString())
+				}
+			}
+
+			keys := make([]string, 0, len(query))
+			for k := range query {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			var parts []string
+			for _, k := range keys {
+				for _, v := range query[k] {
+					parts = append(parts, url.QueryEscape(k)+"="+strings.Replace(url.QueryEscape(v), "+", "%20", -1))
+				}
+			}
+			return cty.StringVal(strings.Join(parts, "&")), nil
+		},
+	})
+}
+
 func AbsoluteURL(urlRef string, origin *url.URL) (string, error) {
 	u, err := url.Parse(urlRef)
 	if err != nil {
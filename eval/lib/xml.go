@@ -0,0 +1,300 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+const (
+	// XMLAttributePrefix marks object attributes representing XML attributes.
+	XMLAttributePrefix = "@"
+	// XMLTextKey is the object attribute holding the text content of an element
+	// with attributes or child elements.
+	XMLTextKey = "#text"
+
+	// maxXMLDepth limits the element nesting like encoding/xml does for Unmarshal.
+	maxXMLDepth = 10000
+)
+
+var (
+	XMLDecodeFunc = newXMLDecodeFunction()
+	XMLEncodeFunc = newXMLEncodeFunction()
+)
+
+func newXMLDecodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "encoded",
+			Type: cty.String,
+		}},
+		Type: function.StaticReturnType(cty.DynamicPseudoType),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			result, err := DecodeXML([]byte(args[0].AsString()))
+			if err != nil {
+				return cty.NilVal, function.NewArgError(0, err)
+			}
+			return result, nil
+		},
+	})
+}
+
+func newXMLEncodeFunction() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{
+			Name: "val",
+			Type: cty.DynamicPseudoType,
+		}},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (ret cty.Value, err error) {
+			result, err := EncodeXML(args[0])
+			if err != nil {
+				return cty.StringVal(""), function.NewArgError(0, err)
+			}
+			return cty.StringVal(string(result)), nil
+		},
+	})
+}
+
+// DecodeXML converts the given XML document to an object with the root element name as single key.
+// Elements without attributes and child elements become strings, all others become objects
+// with "@"-prefixed attributes, child elements and their "#text" content. Repeated child
+// elements are collected in a tuple. Namespace prefixes and declarations are omitted.
+func DecodeXML(b []byte) (cty.Value, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(b))
+
+	var (
+		rootName string
+		root     cty.Value
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return cty.NilVal, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if rootName != "" {
+				return cty.NilVal, fmt.Errorf("multiple root elements")
+			}
+			rootName = t.Name.Local
+			if root, err = decodeXMLElement(decoder, t, 1); err != nil {
+				return cty.NilVal, err
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return cty.NilVal, fmt.Errorf("character data outside of root element")
+			}
+		}
+	}
+
+	if rootName == "" {
+		return cty.NilVal, fmt.Errorf("missing root element")
+	}
+	return cty.ObjectVal(map[string]cty.Value{rootName: root}), nil
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement, depth int) (cty.Value, error) {
+	if depth > maxXMLDepth {
+		return cty.NilVal, fmt.Errorf("exceeded max depth of %d", maxXMLDepth)
+	}
+
+	attributes := make(map[string]cty.Value)
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		attributes[XMLAttributePrefix+attr.Name.Local] = cty.StringVal(attr.Value)
+	}
+
+	children := make(map[string][]cty.Value)
+	text := &strings.Builder{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return cty.NilVal, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(decoder, t, depth+1)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			children[t.Name.Local] = append(children[t.Name.Local], child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(attributes) == 0 && len(children) == 0 {
+				return cty.StringVal(content), nil
+			}
+
+			for name, list := range children {
+				if len(list) == 1 {
+					attributes[name] = list[0]
+				} else {
+					attributes[name] = cty.TupleVal(list)
+				}
+			}
+			if content != "" {
+				attributes[XMLTextKey] = cty.StringVal(content)
+			}
+			return cty.ObjectVal(attributes), nil
+		}
+	}
+}
+
+// EncodeXML converts the given object with a single root element key to an XML document.
+// The value representation is the same as for DecodeXML. Child elements are written in
+// alphabetical order after the text content.
+func EncodeXML(val cty.Value) ([]byte, error) {
+	if val.IsNull() || !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("value must be known and not null")
+	}
+
+	ty := val.Type()
+	if !ty.IsObjectType() && !ty.IsMapType() || val.LengthInt() != 1 {
+		return nil, fmt.Errorf("value must be an object with a single root element")
+	}
+
+	result := &bytes.Buffer{}
+	encoder := xml.NewEncoder(result)
+	for it := val.ElementIterator(); it.Next(); {
+		name, v := it.Element()
+		if v.Type().IsListType() || v.Type().IsTupleType() || v.Type().IsSetType() {
+			return nil, fmt.Errorf("root element %q must not be a list", name.AsString())
+		}
+		if err := encodeXMLElement(encoder, name.AsString(), v); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
+
+func encodeXMLElement(encoder *xml.Encoder, name string, val cty.Value) error {
+	if !isValidXMLName(name) {
+		return fmt.Errorf("invalid element name: %q", name)
+	}
+
+	ty := val.Type()
+	if !val.IsNull() && (ty.IsListType() || ty.IsTupleType() || ty.IsSetType()) {
+		for it := val.ElementIterator(); it.Next(); {
+			_, v := it.Element()
+			if err := encodeXMLElement(encoder, name, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	var (
+		text     string
+		children []string
+		elements map[string]cty.Value
+	)
+
+	switch {
+	case val.IsNull():
+	case ty.IsObjectType() || ty.IsMapType():
+		elements = val.AsValueMap()
+		for key, v := range elements {
+			switch {
+			case key == XMLTextKey:
+				s, err := xmlPrimitiveString(v)
+				if err != nil {
+					return fmt.Errorf("element %q: %w", name, err)
+				}
+				text = s
+			case strings.HasPrefix(key, XMLAttributePrefix):
+				attrName := strings.TrimPrefix(key, XMLAttributePrefix)
+				if !isValidXMLName(attrName) {
+					return fmt.Errorf("element %q: invalid attribute name: %q", name, attrName)
+				}
+				s, err := xmlPrimitiveString(v)
+				if err != nil {
+					return fmt.Errorf("element %q: attribute %q: %w", name, attrName, err)
+				}
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attrName}, Value: s})
+			default:
+				children = append(children, key)
+			}
+		}
+		sort.Slice(start.Attr, func(i, j int) bool {
+			return start.Attr[i].Name.Local < start.Attr[j].Name.Local
+		})
+		sort.Strings(children)
+	default:
+		s, err := xmlPrimitiveString(val)
+		if err != nil {
+			return fmt.Errorf("element %q: %w", name, err)
+		}
+		text = s
+	}
+
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text != "" {
+		if err := encoder.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	for _, child := range children {
+		if err := encodeXMLElement(encoder, child, elements[child]); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+func xmlPrimitiveString(val cty.Value) (string, error) {
+	if val.IsNull() {
+		return "", nil
+	}
+
+	switch val.Type() {
+	case cty.String:
+		return val.AsString(), nil
+	case cty.Number:
+		return val.AsBigFloat().Text('f', -1), nil
+	case cty.Bool:
+		if val.True() {
+			return "true", nil
+		}
+		return "false", nil
+	}
+	return "", fmt.Errorf("unsupported value type: %s", val.Type().FriendlyName())
+}
+
+func isValidXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) {
+			continue
+		}
+		if i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
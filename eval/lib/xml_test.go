@@ -0,0 +1,62 @@
+package lib_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/eval/lib"
+)
+
+func TestXMLFunctions(t *testing.T) {
+	evaluateExpressions(t, map[string]cty.Value{
+		`xml_decode("<a>foo</a>")`:                    cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("foo")}),
+		`xml_decode("<a x=\"1\">foo</a>").a["@x"]`:    cty.StringVal("1"),
+		`xml_decode("<a x=\"1\">foo</a>").a["#text"]`: cty.StringVal("foo"),
+		`xml_decode("<a><b>1</b><b>2</b><c/></a>").a`: cty.ObjectVal(map[string]cty.Value{
+			"b": cty.TupleVal([]cty.Value{cty.StringVal("1"), cty.StringVal("2")}),
+			"c": cty.StringVal(""),
+		}),
+		`xml_encode({a = "foo"})`:                                 cty.StringVal("<a>foo</a>"),
+		`xml_encode({a = {"@x" = 1, "#text" = "foo", b = true}})`: cty.StringVal(`<a x="1">foo<b>true</b></a>`),
+		`xml_encode({a = {c = null, b = ["1", "2"]}})`:            cty.StringVal("<a><b>1</b><b>2</b><c></c></a>"),
+		`xml_encode({a = "<&>"})`:                                 cty.StringVal("<a>&lt;&amp;&gt;</a>"),
+		`xml_encode(xml_decode("<a x=\"1\"><b>2</b></a>"))`:       cty.StringVal(`<a x="1"><b>2</b></a>`),
+	})
+}
+
+func TestDecodeXMLErrors(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`<a>`,
+		`<a></a><b></b>`,
+		`<a></a>foo`,
+		`<a></b>`,
+	} {
+		if _, err := lib.DecodeXML([]byte(src)); err == nil {
+			t.Errorf("%q: expected an error", src)
+		}
+	}
+
+	deep := strings.Repeat("<a>", 10001) + strings.Repeat("</a>", 10001)
+	if _, err := lib.DecodeXML([]byte(deep)); err == nil || err.Error() != "exceeded max depth of 10000" {
+		t.Errorf("expected a depth error, got: %v", err)
+	}
+}
+
+func TestEncodeXMLErrors(t *testing.T) {
+	for name, val := range map[string]cty.Value{
+		"string":         cty.StringVal("foo"),
+		"multiple roots": cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("1"), "b": cty.StringVal("2")}),
+		"list root":      cty.ObjectVal(map[string]cty.Value{"a": cty.TupleVal([]cty.Value{cty.StringVal("1")})}),
+		"invalid name":   cty.ObjectVal(map[string]cty.Value{"1a": cty.StringVal("1")}),
+		"object attribute": cty.ObjectVal(map[string]cty.Value{"a": cty.ObjectVal(map[string]cty.Value{
+			"@x": cty.EmptyObjectVal,
+		})}),
+	} {
+		if _, err := lib.EncodeXML(val); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package eval
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/avenga/couper/internal/seetie"
+)
+
+// multipartMaxContentSize limits the size of files whose content is available
+// base64 encoded with the multipart variable.
+const multipartMaxContentSize = 1 << 20 // 1 MiB
+
+// newMultipartVariable creates the fields and files of a multipart/form-data request body.
+// The file content is only read if withContent is set, otherwise it is null.
+func newMultipartVariable(req *http.Request, withContent bool) cty.Value {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return cty.EmptyObjectVal
+	}
+
+	form := parseForm(req).MultipartForm
+	if form == nil {
+		return cty.EmptyObjectVal
+	}
+
+	files := make(map[string]cty.Value)
+	for name, headers := range form.File {
+		var list []cty.Value
+		for _, fh := range headers {
+			content := cty.NullVal(cty.String)
+			if withContent && fh.Size <= multipartMaxContentSize {
+				if f, err := fh.Open(); err == nil {
+					b, rerr := io.ReadAll(f)
+					_ = f.Close()
+					if rerr == nil {
+						content = cty.StringVal(base64.StdEncoding.EncodeToString(b))
+					}
+				}
+			}
+
+			list = append(list, cty.ObjectVal(map[string]cty.Value{
+				"content":      content,
+				"content_type": cty.StringVal(fh.Header.Get("Content-Type")),
+				"filename":     cty.StringVal(fh.Filename),
+				"size":         cty.NumberIntVal(fh.Size),
+			}))
+		}
+		files[name] = cty.TupleVal(list)
+	}
+
+	return cty.ObjectVal(map[string]cty.Value{
+		"fields": seetie.ValuesMapToValue(form.Value),
+		"files":  cty.ObjectVal(files),
+	})
+}
@@ -8,6 +8,7 @@ const (
 	ClientRequest    = "request"
 	CTX              = "context"
 	Cookies          = "cookies"
+	CSRFToken        = "csrf_token"
 	Endpoint         = "endpoint"
 	Environment      = "env"
 	FormBody         = "form_body"
@@ -16,13 +17,21 @@ const (
 	ID               = "id"
 	JsonBody         = "json_body"
 	Method           = "method"
+	Multipart        = "multipart"
 	Path             = "path"
 	PathParam        = "path_params"
 	Query            = "query"
 	URL              = "url"
+	XmlBody          = "xml_body"
 	Origin           = "origin"
 	Protocol         = "protocol"
 	Host             = "host"
 	Port             = "port"
 	Couper           = "couper"
+	ProtocolVersion  = "protocol_version"
+	ReceivedAt       = "received_at"
+	RemoteIP         = "remote_ip"
+	RemotePort       = "remote_port"
+	Timings          = "timings"
+	TLS              = "tls"
 )
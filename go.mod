@@ -3,11 +3,14 @@ module github.com/avenga/couper
 go 1.16
 
 require (
+	github.com/beevik/etree v1.1.0
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/dgrijalva/jwt-go/v4 v4.0.0-preview1
 	github.com/docker/go-units v0.4.0
 	github.com/fatih/color v1.10.0
 	github.com/getkin/kin-openapi v0.49.0
+	github.com/go-asn1-ber/asn1-ber v1.5.1
+	github.com/go-ldap/ldap/v3 v3.4.1
 	github.com/hashicorp/hcl/v2 v2.10.0
 	github.com/jimlambrt/go-oauth-pkce-code-verifier v0.0.0-20201220003123-6363600dffda
 	github.com/prometheus/client_golang v1.11.0
@@ -0,0 +1,271 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/request"
+	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/eval"
+)
+
+const (
+	CSRFModeDoubleSubmitCookie = "double_submit_cookie"
+	CSRFModeSynchronizerToken  = "synchronizer_token"
+
+	defaultCSRFCookieName = "_couper_csrf"
+	defaultCSRFHeaderName = "X-CSRF-Token"
+	defaultCSRFTTL        = "1h"
+)
+
+var _ http.Handler = &CSRF{}
+
+type CSRF struct {
+	errorHandler http.Handler
+	options      *CSRFOptions
+	nextHandler  http.Handler
+}
+
+type CSRFOptions struct {
+	AllowedOrigins []string
+	CookieName     string
+	HeaderName     string
+	MemStore       *cache.MemoryStore
+	Mode           string
+	SessionCookie  string
+	TTL            int64
+}
+
+func NewCSRFOptions(csrf *config.CSRF, memStore *cache.MemoryStore) (*CSRFOptions, error) {
+	if csrf == nil {
+		return nil, nil
+	}
+
+	opts := &CSRFOptions{
+		CookieName:    csrf.CookieName,
+		HeaderName:    csrf.HeaderName,
+		MemStore:      memStore,
+		Mode:          csrf.Mode,
+		SessionCookie: csrf.SessionCookie,
+	}
+
+	if opts.CookieName == "" {
+		opts.CookieName = defaultCSRFCookieName
+	}
+	if opts.HeaderName == "" {
+		opts.HeaderName = defaultCSRFHeaderName
+	}
+
+	switch opts.Mode {
+	case "":
+		opts.Mode = CSRFModeDoubleSubmitCookie
+	case CSRFModeDoubleSubmitCookie:
+	case CSRFModeSynchronizerToken:
+		if opts.SessionCookie == "" {
+			return nil, errors.Configuration.Message("csrf session_cookie required with mode " + CSRFModeSynchronizerToken)
+		}
+		if memStore == nil {
+			return nil, errors.Configuration.Message("csrf mode " + CSRFModeSynchronizerToken + " requires a memory store")
+		}
+	default:
+		return nil, errors.Configuration.Messagef("csrf mode: unsupported value %q", opts.Mode)
+	}
+
+	ttl := csrf.TTL
+	if ttl == "" {
+		ttl = defaultCSRFTTL
+	}
+	dur, err := time.ParseDuration(ttl)
+	if err != nil {
+		return nil, errors.Configuration.With(err).Message("csrf ttl")
+	}
+	opts.TTL = int64(dur.Seconds())
+
+	for _, origin := range csrf.AllowedOrigins {
+		u, perr := url.Parse(origin)
+		if perr != nil || u.Scheme == "" || u.Host == "" {
+			return nil, errors.Configuration.Messagef("csrf allowed_origins: invalid origin %q", origin)
+		}
+		opts.AllowedOrigins = append(opts.AllowedOrigins, normalizeOrigin(u))
+	}
+
+	return opts, nil
+}
+
+// NewCSRFHandler creates a CSRF handler. Validation errors are passed to the given error handler
+// with the request.Error context value, like access control errors.
+func NewCSRFHandler(opts *CSRFOptions, errorHandler http.Handler, nextHandler http.Handler) http.Handler {
+	if opts == nil {
+		return nextHandler
+	}
+	return &CSRF{
+		errorHandler: errorHandler,
+		options:      opts,
+		nextHandler:  nextHandler,
+	}
+}
+
+func (c *CSRF) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	token, isNew, err := c.token(req)
+	if err != nil {
+		c.serveError(rw, req, errors.Server.With(err))
+		return
+	}
+
+	if !isSafeMethod(req.Method) {
+		if err = c.validate(req, token, isNew); err != nil {
+			c.serveError(rw, req, errors.Csrf.Message(err.Error()))
+			return
+		}
+	}
+
+	if token != "" {
+		ctx := context.WithValue(req.Context(), request.CSRFToken, token)
+		*req = *req.WithContext(ctx)
+		*req = *req.WithContext(eval.ContextFromRequest(req).WithClientRequest(req))
+	}
+
+	if isNew && c.options.Mode == CSRFModeDoubleSubmitCookie {
+		// not HttpOnly: client scripts have to read the token to send it with the configured header
+		cookie := &http.Cookie{
+			Name:     c.options.CookieName,
+			Value:    token,
+			Path:     "/",
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		}
+		if w, ok := rw.(interface{ AddCookie(*http.Cookie) }); ok {
+			w.AddCookie(cookie)
+		} else {
+			http.SetCookie(rw, cookie)
+		}
+	}
+
+	c.nextHandler.ServeHTTP(rw, req)
+}
+
+func (c *CSRF) serveError(rw http.ResponseWriter, req *http.Request, err *errors.Error) {
+	*req = *req.WithContext(context.WithValue(req.Context(), request.Error, err))
+	c.errorHandler.ServeHTTP(rw, req)
+}
+
+// token returns the current token of the client and whether it has been created with this request.
+func (c *CSRF) token(req *http.Request) (string, bool, error) {
+	if c.options.Mode == CSRFModeDoubleSubmitCookie {
+		if cookie, err := req.Cookie(c.options.CookieName); err == nil && cookie.Value != "" {
+			return cookie.Value, false, nil
+		}
+		token, err := newCSRFToken()
+		return token, true, err
+	}
+
+	cookie, err := req.Cookie(c.options.SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return "", false, nil // no session, no token
+	}
+
+	sum := sha256.Sum256([]byte(cookie.Value))
+	key := "csrf|" + c.options.SessionCookie + "|" + hex.EncodeToString(sum[:])
+
+	isNew := false
+	token, _ := c.options.MemStore.Get(key).(string)
+	if token == "" {
+		if token, err = newCSRFToken(); err != nil {
+			return "", false, err
+		}
+		isNew = true
+	}
+	c.options.MemStore.Set(key, token, c.options.TTL) // sliding expiration
+	return token, isNew, nil
+}
+
+func (c *CSRF) validate(req *http.Request, token string, isNew bool) error {
+	if err := c.validateOrigin(req); err != nil {
+		return err
+	}
+
+	if token == "" || isNew {
+		return fmt.Errorf("missing token")
+	}
+
+	sent := req.Header.Get(c.options.HeaderName)
+	if sent == "" {
+		return fmt.Errorf("missing %s header", c.options.HeaderName)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+		return fmt.Errorf("token mismatch")
+	}
+	return nil
+}
+
+// validateOrigin compares the Origin or, as fallback, the Referer header with the
+// request origin and the allowed origins. Requests without both headers are only
+// accepted without configured allowed origins.
+func (c *CSRF) validateOrigin(req *http.Request) error {
+	source := req.Header.Get("Origin")
+	if source == "" {
+		source = req.Header.Get("Referer")
+	}
+	if source == "" {
+		if len(c.options.AllowedOrigins) > 0 {
+			return fmt.Errorf("missing Origin or Referer header")
+		}
+		return nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("origin mismatch: %q", source)
+	}
+	origin := normalizeOrigin(u)
+
+	if origin == normalizeOrigin(req.URL) {
+		return nil
+	}
+	for _, allowed := range c.options.AllowedOrigins {
+		if origin == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("origin mismatch: %q", origin)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+func newCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// normalizeOrigin returns the lower-cased scheme and host of the given URL without default ports.
+func normalizeOrigin(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if scheme == "http" {
+		host = strings.TrimSuffix(host, ":80")
+	} else if scheme == "https" {
+		host = strings.TrimSuffix(host, ":443")
+	}
+	return scheme + "://" + host
+}
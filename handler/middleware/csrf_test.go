@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/avenga/couper/cache"
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/errors"
+)
+
+func TestNewCSRFOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		conf   *config.CSRF
+		expErr string
+	}{
+		{"defaults", &config.CSRF{}, ""},
+		{"double submit cookie", &config.CSRF{Mode: CSRFModeDoubleSubmitCookie, CookieName: "c", HeaderName: "X-C"}, ""},
+		{"synchronizer token", &config.CSRF{Mode: CSRFModeSynchronizerToken, SessionCookie: "s"}, ""},
+		{"synchronizer token without session cookie", &config.CSRF{Mode: CSRFModeSynchronizerToken}, "configuration error: csrf session_cookie required with mode synchronizer_token"},
+		{"unsupported mode", &config.CSRF{Mode: "cookie"}, `configuration error: csrf mode: unsupported value "cookie"`},
+		{"invalid ttl", &config.CSRF{TTL: "1x"}, `configuration error: csrf ttl: time: unknown unit "x" in duration "1x"`},
+		{"invalid origin", &config.CSRF{AllowedOrigins: []string{"example.com"}}, `configuration error: csrf allowed_origins: invalid origin "example.com"`},
+	}
+
+	quitCh := make(chan struct{})
+	defer close(quitCh)
+	memStore := cache.New(logrus.NewEntry(logrus.New()), quitCh)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			opts, err := NewCSRFOptions(tt.conf, memStore)
+			if tt.expErr != "" {
+				if err == nil || err.(errors.GoError).LogError() != tt.expErr {
+					subT.Errorf("expected error %q, got: %v", tt.expErr, err)
+				}
+				return
+			}
+			if err != nil {
+				subT.Fatalf("unexpected error: %v", err)
+			}
+			if opts.CookieName == "" || opts.HeaderName == "" || opts.TTL != 3600 {
+				subT.Errorf("expected defaults, got: %#v", opts)
+			}
+		})
+	}
+}
+
+func TestCSRF_normalizeOrigin(t *testing.T) {
+	for raw, exp := range map[string]string{
+		"https://Example.com:443/path": "https://example.com",
+		"http://example.com:80":        "http://example.com",
+		"http://example.com:8080/":     "http://example.com:8080",
+		"https://example.com:80":       "https://example.com:80",
+	} {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := normalizeOrigin(u); got != exp {
+			t.Errorf("%s: expected %q, got: %q", raw, exp, got)
+		}
+	}
+}
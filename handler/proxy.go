@@ -89,6 +89,9 @@ func (p *Proxy) RoundTrip(req *http.Request) (*http.Response, error) {
 		return nil, err
 	}
 
+	// The reverse proxy clones the request, prepare a holder for the backend timings.
+	*req = *req.WithContext(context.WithValue(req.Context(), request.BackendTimings, &request.Timings{}))
+
 	p.reverseProxy.ServeHTTP(rec, req)
 	beresp, err := rec.Response(req)
 	if err != nil {
@@ -1,38 +1,51 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
 
+	"github.com/avenga/couper/config"
 	"github.com/avenga/couper/config/runtime/server"
 	"github.com/avenga/couper/errors"
 	"github.com/avenga/couper/eval"
 	"github.com/avenga/couper/server/writer"
 )
 
+// BootstrapDataPlaceholder gets replaced with the JSON encoded bootstrap_data within the bootstrap file.
+const BootstrapDataPlaceholder = "__BOOTSTRAP_DATA__"
+
 var (
 	_ http.Handler   = &Spa{}
 	_ server.Context = &Spa{}
 )
 
 type Spa struct {
-	file       string
-	modifier   []hcl.Body
-	srvOptions *server.Options
+	bootstrapData hcl.Expression
+	file          string
+	modifier      []hcl.Body
+	srvOptions    *server.Options
 }
 
-func NewSpa(bootstrapFile string, srvOpts *server.Options, modifier []hcl.Body) (*Spa, error) {
-	absPath, err := filepath.Abs(bootstrapFile)
+func NewSpa(conf *config.Spa, srvOpts *server.Options, modifier []hcl.Body) (*Spa, error) {
+	absPath, err := filepath.Abs(conf.BootstrapFile)
 	if err != nil {
 		return nil, err
 	}
+
 	return &Spa{
-		file:       absPath,
-		modifier:   modifier,
-		srvOptions: srvOpts,
+		bootstrapData: conf.BootstrapData,
+		file:          absPath,
+		modifier:      modifier,
+		srvOptions:    srvOpts,
 	}, nil
 }
 
@@ -60,12 +73,53 @@ func (s *Spa) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	evalContext := eval.ContextFromRequest(req)
+
+	var content io.ReadSeeker = file
+	modTime := fileInfo.ModTime()
+	if s.bootstrapData != nil {
+		content, err = s.replaceBootstrapData(evalContext, file)
+		if err != nil {
+			s.srvOptions.ServerErrTpl.ServeError(err).ServeHTTP(rw, req)
+			return
+		}
+		modTime = time.Time{} // the content may change per request
+	}
+
 	if r, ok := rw.(*writer.Response); ok {
-		evalContext := eval.ContextFromRequest(req)
 		r.AddModifier(evalContext, s.modifier)
 	}
 
-	http.ServeContent(rw, req, s.file, fileInfo.ModTime(), file)
+	http.ServeContent(rw, req, s.file, modTime, content)
+}
+
+// replaceBootstrapData evaluates the bootstrap_data expression and replaces the
+// placeholder of the given file with its JSON representation.
+func (s *Spa) replaceBootstrapData(evalContext *eval.Context, file io.Reader) (io.ReadSeeker, error) {
+	val, diags := s.bootstrapData.Value(evalContext.HCLContext())
+	if diags.HasErrors() {
+		return nil, errors.Evaluation.With(diags)
+	}
+
+	src, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, errors.Server.With(err)
+	}
+
+	if val.IsNull() {
+		return bytes.NewReader(src), nil
+	}
+
+	data, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, errors.Evaluation.With(err)
+	}
+
+	// prevent the termination of a surrounding script element
+	escaped := &bytes.Buffer{}
+	json.HTMLEscape(escaped, data)
+
+	return bytes.NewReader(bytes.ReplaceAll(src, []byte(BootstrapDataPlaceholder), escaped.Bytes())), nil
 }
 
 func (s *Spa) Options() *server.Options {
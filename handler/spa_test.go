@@ -7,6 +7,9 @@ import (
 	"path"
 	"testing"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
 	"github.com/avenga/couper/config"
 	"github.com/avenga/couper/config/runtime/server"
 
@@ -32,7 +35,7 @@ func TestSpa_ServeHTTP(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			opts, _ := server.NewServerOptions(&config.Server{}, nil)
-			s, err := handler.NewSpa(path.Join(wd, tt.filePath), opts, nil)
+			s, err := handler.NewSpa(&config.Spa{BootstrapFile: path.Join(wd, tt.filePath)}, opts, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -50,3 +53,36 @@ func TestSpa_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestSpa_BootstrapData(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expr, diags := hclsyntax.ParseExpression([]byte(`{ token = "abc", html = "</script>" }`), "couper.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatal(diags)
+	}
+
+	opts, _ := server.NewServerOptions(&config.Server{}, nil)
+	s, err := handler.NewSpa(&config.Spa{
+		BootstrapData: expr,
+		BootstrapFile: path.Join(wd, "testdata/spa/bootstrap.html"),
+	}, opts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := httptest.NewRecorder()
+	s.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	exp := `<script>var data = {"html":"\u003c/script\u003e","token":"abc"};</script>` + "\n"
+	if res.Body.String() != exp {
+		t.Errorf("Expected body %q, got: %q", exp, res.Body.String())
+	}
+
+	if res.Header().Get("Last-Modified") != "" {
+		t.Error("Expected no Last-Modified header for dynamic content")
+	}
+}
@@ -0,0 +1,380 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/eval"
+)
+
+const (
+	sigV4Algorithm     = "AWS4-HMAC-SHA256"
+	sigV4ChunkSize     = 64 * 1024
+	sigV4DateFormat    = "20060102"
+	sigV4TimeFormat    = "20060102T150405Z"
+	sigV4Streaming     = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	sigV4Unsigned      = "UNSIGNED-PAYLOAD"
+	sigV4EmptySHA256   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	sigV4ChunkSigLabel = ";chunk-signature="
+	// sigV4MaxPayload limits the buffered body of a signed payload,
+	// equals the default request_body_limit.
+	sigV4MaxPayload = 64 << 20
+)
+
+// Payload signing modes of the aws_sigv4 block.
+const (
+	PayloadSigned    = "signed"
+	PayloadUnsigned  = "unsigned"
+	PayloadStreaming = "streaming"
+)
+
+// AWSSigV4 signs backend requests with the AWS Signature Version 4.
+type AWSSigV4 struct {
+	config *config.AWSSigV4
+}
+
+// NewAWSSigV4 creates a new <*AWSSigV4> object.
+func NewAWSSigV4(conf *config.AWSSigV4) (*AWSSigV4, error) {
+	if conf.AccessKeyID == "" || conf.SecretAccessKey == "" {
+		return nil, fmt.Errorf("aws_sigv4: access_key_id and secret_access_key must not be empty")
+	}
+
+	if conf.Region == "" || conf.Service == "" {
+		return nil, fmt.Errorf("aws_sigv4: region and service must not be empty")
+	}
+
+	switch conf.PayloadSigning {
+	case "":
+		conf.PayloadSigning = PayloadSigned
+	case PayloadSigned, PayloadUnsigned, PayloadStreaming:
+	default:
+		return nil, fmt.Errorf("aws_sigv4: unsupported payload_signing: %q", conf.PayloadSigning)
+	}
+
+	return &AWSSigV4{config: conf}, nil
+}
+
+// Sign adds the authentication headers to the given request. Must be called
+// after all other modifications of the request have been applied.
+func (s *AWSSigV4) Sign(req *http.Request, now time.Time) error {
+	now = now.UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	scope := strings.Join([]string{now.Format(sigV4DateFormat), s.config.Region, s.config.Service, "aws4_request"}, "/")
+
+	payloadHash, streaming, err := s.payloadHash(req)
+	if err != nil {
+		return err
+	}
+
+	var decodedLength int64
+	if streaming {
+		decodedLength = req.ContentLength
+		if ce := req.Header.Get("Content-Encoding"); ce != "" {
+			req.Header.Set("Content-Encoding", "aws-chunked,"+ce)
+		} else {
+			req.Header.Set("Content-Encoding", "aws-chunked")
+		}
+		req.Header.Set("X-Amz-Decoded-Content-Length", strconv.FormatInt(decodedLength, 10))
+		req.ContentLength = chunkedLength(decodedLength)
+	}
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.config.SessionToken)
+	}
+	if s.config.Service == "s3" || payloadHash == sigV4Unsigned || streaming {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := s.canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := s.signingKey(now)
+	signature := hex.EncodeToString(hmacSHA256(key, []byte(stringToSign)))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+
+	if streaming {
+		body := req.Body
+		getBody := req.GetBody
+		req.Body = newChunkSigner(body, key, amzDate, scope, signature)
+		req.GetBody = nil
+		if getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				b, gerr := getBody()
+				if gerr != nil {
+					return nil, gerr
+				}
+				return newChunkSigner(b, key, amzDate, scope, signature), nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// payloadHash returns the hex encoded hash of the request body or the placeholder
+// of the configured payload signing mode. A signed payload gets buffered up to
+// the size of sigV4MaxPayload.
+func (s *AWSSigV4) payloadHash(req *http.Request) (string, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return sigV4EmptySHA256, false, nil
+	}
+
+	switch s.config.PayloadSigning {
+	case PayloadUnsigned:
+		return sigV4Unsigned, false, nil
+	case PayloadStreaming:
+		// The aws-chunked encoding requires the decoded length, others get buffered.
+		if req.ContentLength > 0 {
+			return sigV4Streaming, true, nil
+		}
+	}
+
+	var body io.ReadCloser
+	if req.GetBody != nil {
+		b, err := req.GetBody()
+		if err != nil {
+			return "", false, err
+		}
+		body = b
+	} else {
+		body = req.Body
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(body, sigV4MaxPayload+1))
+	_ = body.Close()
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(b) > sigV4MaxPayload {
+		return "", false, fmt.Errorf("aws_sigv4: payload exceeds the limit of %d bytes, "+
+			"use payload_signing \"unsigned\" or \"streaming\" with a known content length", sigV4MaxPayload)
+	}
+
+	if req.GetBody == nil { // buffer to be able to send the body
+		eval.SetBody(req, b)
+	}
+
+	return hashHex(b), false, nil
+}
+
+func (s *AWSSigV4) signingKey(now time.Time) []byte {
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), []byte(now.Format(sigV4DateFormat)))
+	key = hmacSHA256(key, []byte(s.config.Region))
+	key = hmacSHA256(key, []byte(s.config.Service))
+	return hmacSHA256(key, []byte("aws4_request"))
+}
+
+// canonicalURI encodes each path segment. Apart from S3 all services expect
+// the encoding of the already escaped path which results in a double encoding.
+func (s *AWSSigV4) canonicalURI(u *url.URL) string {
+	path := u.Path
+	if s.config.Service != "s3" {
+		path = u.EscapedPath()
+	}
+
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalHeaders returns the signed header names and the canonical header string.
+// The host, content-type, content-md5, content-encoding and all x-amz-* headers get signed.
+func (s *AWSSigV4) canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": strings.TrimSpace(host)}
+	for name, values := range req.Header {
+		lname := strings.ToLower(name)
+		switch {
+		case lname == "content-type", lname == "content-md5", lname == "content-encoding",
+			strings.HasPrefix(lname, "x-amz-"):
+		default:
+			continue
+		}
+
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[lname] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalQuery returns the encoded query parameters sorted by key and value.
+func canonicalQuery(u *url.URL) string {
+	values, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		values = u.Query()
+	}
+
+	type pair struct{ key, value string }
+	pairs := make([]pair, 0, len(values))
+	for key, vals := range values {
+		for _, v := range vals {
+			pairs = append(pairs, pair{uriEncode(key), uriEncode(v)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+
+	encoded := make([]string, len(pairs))
+	for i, p := range pairs {
+		encoded[i] = p.key + "=" + p.value
+	}
+	return strings.Join(encoded, "&")
+}
+
+// uriEncode encodes all characters except the unreserved ones of RFC 3986.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkedLength calculates the length of the aws-chunked encoded body.
+func chunkedLength(length int64) int64 {
+	chunkOverhead := func(size int64) int64 {
+		return int64(len(strconv.FormatInt(size, 16))+len(sigV4ChunkSigLabel)+64) + 4
+	}
+
+	full := length / sigV4ChunkSize
+	encoded := full * (sigV4ChunkSize + chunkOverhead(sigV4ChunkSize))
+	if rest := length % sigV4ChunkSize; rest > 0 {
+		encoded += rest + chunkOverhead(rest)
+	}
+	return encoded + chunkOverhead(0)
+}
+
+// chunkSigner encodes the body with the aws-chunked content encoding
+// where each chunk is signed with the signature of its predecessor.
+type chunkSigner struct {
+	body    io.ReadCloser
+	buf     bytes.Buffer
+	done    bool
+	key     []byte
+	prevSig string
+	prefix  string
+}
+
+func newChunkSigner(body io.ReadCloser, key []byte, amzDate, scope, seedSignature string) *chunkSigner {
+	return &chunkSigner{
+		body:    body,
+		key:     key,
+		prefix:  strings.Join([]string{sigV4Algorithm + "-PAYLOAD", amzDate, scope}, "\n") + "\n",
+		prevSig: seedSignature,
+	}
+}
+
+func (c *chunkSigner) Read(p []byte) (int, error) {
+	for c.buf.Len() == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+
+		chunk := make([]byte, sigV4ChunkSize)
+		n, err := io.ReadFull(c.body, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+
+		if n > 0 {
+			c.writeChunk(chunk[:n])
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			c.writeChunk(nil)
+			c.done = true
+		}
+	}
+
+	return c.buf.Read(p)
+}
+
+func (c *chunkSigner) writeChunk(data []byte) {
+	stringToSign := c.prefix + c.prevSig + "\n" + sigV4EmptySHA256 + "\n" + hashHex(data)
+	c.prevSig = hex.EncodeToString(hmacSHA256(c.key, []byte(stringToSign)))
+
+	c.buf.WriteString(strconv.FormatInt(int64(len(data)), 16) + sigV4ChunkSigLabel + c.prevSig + "\r\n")
+	c.buf.Write(data)
+	c.buf.WriteString("\r\n")
+}
+
+func (c *chunkSigner) Close() error {
+	return c.body.Close()
+}
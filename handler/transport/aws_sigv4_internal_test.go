@@ -0,0 +1,29 @@
+package transport
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestAWSSigV4_canonicalQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		exp   string
+	}{
+		{"", ""},
+		{"Param2=value2&Param1=value1", "Param1=value1&Param2=value2"},
+		{"Param1=value2&Param1=Value1", "Param1=Value1&Param1=value2"},
+		{"prefix-x=1&prefix=2", "prefix=2&prefix-x=1"},
+		{"prefix.x=1&prefix0=3&prefix=2", "prefix=2&prefix.x=1&prefix0=3"},
+		{"a=b&a-=a&a=", "a=&a=b&a-=a"},
+		{"key=a b&key=a+c", "key=a%20b&key=a%20c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(subT *testing.T) {
+			if got := canonicalQuery(&url.URL{RawQuery: tt.query}); got != tt.exp {
+				subT.Errorf("want: %q, got: %q", tt.exp, got)
+			}
+		})
+	}
+}
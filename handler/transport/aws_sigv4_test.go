@@ -0,0 +1,288 @@
+package transport_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/handler/transport"
+	"github.com/avenga/couper/internal/test"
+)
+
+func TestAWSSigV4_Sign(t *testing.T) {
+	helper := test.New(t)
+
+	// see https://docs.aws.amazon.com/general/latest/gr/signature-v4-test-suite.html
+	signer, err := transport.NewAWSSigV4(&config.AWSSigV4{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+	})
+	helper.Must(err)
+
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		url    string
+		expSig string
+	}{
+		{"get-vanilla", "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"get-vanilla-query-order-key-case", "https://example.amazonaws.com/?Param2=value2&Param1=value1", "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500"},
+		{"get-vanilla-query-order-value", "https://example.amazonaws.com/?Param1=value2&Param1=value1", "5772eed61e12b33fae39ee5e7012498b51d56abc0abb7c60486157bd471c4694"},
+		{"get-vanilla-empty-query-key", "https://example.amazonaws.com/?Param1=value1", "a67d582fa61cc504c4bae71f336f98b97f1ea3c7a6bfe1b6e45aec72011b9aeb"},
+		{"get-vanilla-query-unreserved", "https://example.amazonaws.com/?-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", "9c3e54bfcdf0b19771a7f523ee5669cdf59bc7cc0884027167c21bb143a40197"},
+		{"get-vanilla-utf8-query", "https://example.amazonaws.com/?ሴ=bar", "2cdec8eed098649ff3a119c94853b13c643bcf08f8b0a1d91e12c9027818dd04"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(subT *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			helper.Must(signer.Sign(req, now))
+
+			exp := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tt.expSig
+			if got := req.Header.Get("Authorization"); got != exp {
+				subT.Errorf("\nwant:\t%s\ngot:\t%s", exp, got)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				subT.Errorf("unexpected X-Amz-Date: %q", got)
+			}
+		})
+	}
+}
+
+func TestAWSSigV4_PayloadSigning(t *testing.T) {
+	helper := test.New(t)
+
+	payload := strings.Repeat("0123456789", 10000) // more than one chunk
+
+	for _, mode := range []string{transport.PayloadSigned, transport.PayloadUnsigned, transport.PayloadStreaming} {
+		t.Run(mode, func(subT *testing.T) {
+			signer, err := transport.NewAWSSigV4(&config.AWSSigV4{
+				AccessKeyID:     "AKIDEXAMPLE",
+				SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				Region:          "eu-central-1",
+				Service:         "s3",
+				SessionToken:    "s3ss10n",
+				PayloadSigning:  mode,
+			})
+			helper.Must(err)
+
+			// S3 stand-in which verifies the signatures and decodes the received body
+			var received []byte
+			var receivedHeader http.Header
+			origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				receivedHeader = r.Header.Clone()
+				b, rerr := ioutil.ReadAll(r.Body)
+				helper.Must(rerr)
+				received = verifyAWSSigV4(subT, r, b, "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY")
+				rw.WriteHeader(http.StatusOK)
+			}))
+			defer origin.Close()
+
+			req, err := http.NewRequest(http.MethodPut, origin.URL+"/bucket/my file.txt?prefix-x=1&prefix=2&acl", strings.NewReader(payload))
+			helper.Must(err)
+			helper.Must(signer.Sign(req, time.Now()))
+
+			res, err := http.DefaultClient.Do(req)
+			helper.Must(err)
+			res.Body.Close()
+
+			if string(received) != payload {
+				subT.Errorf("expected the origin to receive the payload, got %d bytes", len(received))
+			}
+
+			expHash := map[string]string{
+				transport.PayloadSigned:    hashHex([]byte(payload)),
+				transport.PayloadUnsigned:  "UNSIGNED-PAYLOAD",
+				transport.PayloadStreaming: "STREAMING-AWS4-HMAC-SHA256-PAYLOAD",
+			}[mode]
+			if got := receivedHeader.Get("X-Amz-Content-Sha256"); got != expHash {
+				subT.Errorf("expected X-Amz-Content-Sha256: %q, got: %q", expHash, got)
+			}
+
+			if got := receivedHeader.Get("X-Amz-Security-Token"); got != "s3ss10n" {
+				subT.Errorf("expected X-Amz-Security-Token, got: %q", got)
+			}
+
+			if mode == transport.PayloadStreaming {
+				if got := receivedHeader.Get("X-Amz-Decoded-Content-Length"); got != strconv.Itoa(len(payload)) {
+					subT.Errorf("expected X-Amz-Decoded-Content-Length: %d, got: %q", len(payload), got)
+				}
+			}
+
+			auth := receivedHeader.Get("Authorization")
+			if !strings.Contains(auth, "host;x-amz-content-sha256;x-amz-date;") {
+				subT.Errorf("unexpected signed headers: %q", auth)
+			}
+		})
+	}
+}
+
+// verifyAWSSigV4 recomputes the request signature and the chunk signatures of
+// an aws-chunked body like S3 does and returns the decoded payload.
+func verifyAWSSigV4(t *testing.T, r *http.Request, body []byte, secret string) []byte {
+	t.Helper()
+
+	auth := strings.TrimPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	fields := map[string]string{}
+	for _, field := range strings.Split(auth, ", ") {
+		kv := strings.SplitN(field, "=", 2)
+		if len(kv) != 2 {
+			t.Fatalf("invalid Authorization header: %q", auth)
+		}
+		fields[kv[0]] = kv[1]
+	}
+
+	credential := strings.SplitN(fields["Credential"], "/", 2)
+	if len(credential) != 2 {
+		t.Fatalf("invalid credential: %q", fields["Credential"])
+	}
+	scope := credential[1]
+	scopeParts := strings.Split(scope, "/")
+	amzDate := r.Header.Get("X-Amz-Date")
+
+	segments := strings.Split(r.URL.Path, "/")
+	for i, segment := range segments {
+		segments[i] = awsURIEncode(segment)
+	}
+
+	type pair struct{ key, value string }
+	var pairs []pair
+	for key, values := range r.URL.Query() {
+		for _, value := range values {
+			pairs = append(pairs, pair{awsURIEncode(key), awsURIEncode(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].key != pairs[j].key {
+			return pairs[i].key < pairs[j].key
+		}
+		return pairs[i].value < pairs[j].value
+	})
+	query := make([]string, len(pairs))
+	for i, p := range pairs {
+		query[i] = p.key + "=" + p.value
+	}
+
+	var headers strings.Builder
+	for _, name := range strings.Split(fields["SignedHeaders"], ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + value + "\n")
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	switch payloadHash {
+	case "UNSIGNED-PAYLOAD", "STREAMING-AWS4-HMAC-SHA256-PAYLOAD":
+	default:
+		if got := hashHex(body); got != payloadHash {
+			t.Errorf("payload hash mismatch: want: %q, got: %q", payloadHash, got)
+		}
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		strings.Join(segments, "/"),
+		strings.Join(query, "&"),
+		headers.String(),
+		fields["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secret), scopeParts[0])
+	for _, part := range scopeParts[1:] {
+		key = hmacSHA256(key, part)
+	}
+
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	if signature != fields["Signature"] {
+		t.Fatalf("signature mismatch:\nwant:\t%s\ngot:\t%s\ncanonical request:\n%s", signature, fields["Signature"], canonicalRequest)
+	}
+
+	if payloadHash != "STREAMING-AWS4-HMAC-SHA256-PAYLOAD" {
+		return body
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		t.Fatalf("expected aws-chunked content encoding, got: %q", r.Header.Get("Content-Encoding"))
+	}
+
+	const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	var decoded []byte
+	prevSig := signature
+	br := bufio.NewReader(bytes.NewReader(body))
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts := strings.SplitN(strings.TrimSpace(line), ";chunk-signature=", 2)
+		if len(parts) != 2 {
+			t.Fatalf("invalid chunk header: %q", line)
+		}
+		size, err := strconv.ParseInt(parts[0], 16, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk := make([]byte, size+2)
+		if _, err = io.ReadFull(br, chunk); err != nil {
+			t.Fatal(err)
+		}
+		chunk = chunk[:size]
+
+		chunkStringToSign := strings.Join([]string{
+			"AWS4-HMAC-SHA256-PAYLOAD", amzDate, scope, prevSig, emptyHash, hashHex(chunk),
+		}, "\n")
+		prevSig = hex.EncodeToString(hmacSHA256(key, chunkStringToSign))
+		if prevSig != parts[1] {
+			t.Fatalf("chunk signature mismatch:\nwant:\t%s\ngot:\t%s", prevSig, parts[1])
+		}
+
+		decoded = append(decoded, chunk...)
+		if size == 0 {
+			return decoded
+		}
+	}
+}
+
+func awsURIEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -123,6 +123,12 @@ func (b *Backend) RoundTrip(req *http.Request) (*http.Response, error) {
 		req.Header.Del("Upgrade")
 	}
 
+	if b.options != nil && b.options.AWSSigV4 != nil {
+		if err = b.options.AWSSigV4.Sign(req, time.Now()); err != nil {
+			return nil, errors.Backend.Label(b.name).Message("request signing error").With(err)
+		}
+	}
+
 	var beresp *http.Response
 	if b.openAPIValidator != nil {
 		beresp, err = b.openAPIValidate(req, tc, deadlineErr)
@@ -4,5 +4,6 @@ import "github.com/avenga/couper/handler/validation"
 
 // BackendOptions represents the transport <BackendOptions> object.
 type BackendOptions struct {
-	OpenAPI *validation.OpenAPIOptions
+	AWSSigV4 *AWSSigV4
+	OpenAPI  *validation.OpenAPIOptions
 }
@@ -2,14 +2,17 @@ package transport
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 
 	"github.com/avenga/couper/cache"
 	"github.com/avenga/couper/config"
 	"github.com/avenga/couper/config/request"
 	"github.com/avenga/couper/errors"
+	"github.com/avenga/couper/eval/content"
 	"github.com/avenga/couper/oauth2"
 )
 
@@ -20,10 +23,18 @@ type OAuth2ReqAuth struct {
 	oauth2Client *oauth2.CcClient
 	config       *config.OAuth2ReqAuth
 	memStore     *cache.MemoryStore
-	locks        sync.Map
+	locks        map[string]*tokenLock
+	locksMu      sync.Mutex
 	next         http.RoundTripper
 }
 
+// tokenLock serializes token requests per storage key. It is removed as soon as
+// no request holds or waits for it.
+type tokenLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
 // NewOAuth2ReqAuth creates a new <http.RoundTripper> object.
 func NewOAuth2ReqAuth(conf *config.OAuth2ReqAuth, memStore *cache.MemoryStore,
 	oauth2Client *oauth2.CcClient, next http.RoundTripper) (http.RoundTripper, error) {
@@ -31,7 +42,7 @@ func NewOAuth2ReqAuth(conf *config.OAuth2ReqAuth, memStore *cache.MemoryStore,
 		config:       conf,
 		oauth2Client: oauth2Client,
 		memStore:     memStore,
-		locks:        sync.Map{},
+		locks:        make(map[string]*tokenLock),
 		next:         next,
 	}, nil
 }
@@ -40,6 +51,16 @@ func NewOAuth2ReqAuth(conf *config.OAuth2ReqAuth, memStore *cache.MemoryStore,
 func (oa *OAuth2ReqAuth) RoundTrip(req *http.Request) (*http.Response, error) {
 	storageKey := fmt.Sprintf("%p|%s|%s", &oa.oauth2Client.Backend, oa.config.ClientID, oa.config.ClientSecret)
 
+	var subjectToken string
+	if oa.oauth2Client.RequiresSubjectToken() {
+		var err error
+		if subjectToken, err = oa.readSubjectToken(req); err != nil {
+			return nil, errors.Backend.Label(oa.config.BackendName).Message("subject token error").With(err)
+		}
+		// tokens are obtained on behalf of the subject, so cache them per subject
+		storageKey += fmt.Sprintf("|%x", sha256.Sum256([]byte(subjectToken)))
+	}
+
 	if token, terr := oa.readAccessToken(storageKey); terr != nil {
 		// TODO this error is not connected to the OAuth2 client's backend
 		// In fact this can only be a JSON parse error or a missing access_token,
@@ -52,30 +73,12 @@ func (oa *OAuth2ReqAuth) RoundTrip(req *http.Request) (*http.Response, error) {
 		return oa.next.RoundTrip(req)
 	}
 
-	value, _ := oa.locks.LoadOrStore(storageKey, &sync.Mutex{})
-	mutex := value.(*sync.Mutex)
-
-	mutex.Lock()
-	token, terr := oa.readAccessToken(storageKey)
-	if terr != nil {
-		mutex.Unlock()
-		return nil, errors.Backend.Label(oa.config.BackendName).Message("token read error").With(terr)
-	} else if token != "" {
-		mutex.Unlock()
-		req.Header.Set("Authorization", "Bearer "+token)
-		return oa.next.RoundTrip(req)
-	}
-
 	ctx := req.Context()
-	tokenResponse, tokenResponseData, token, err := oa.oauth2Client.GetTokenResponse(ctx)
+	token, err := oa.requestAccessToken(ctx, storageKey, subjectToken)
 	if err != nil {
-		mutex.Unlock()
-		return nil, errors.Backend.Label(oa.config.BackendName).Message("token request error").With(err)
+		return nil, err
 	}
 
-	oa.updateAccessToken(tokenResponse, tokenResponseData, storageKey)
-	mutex.Unlock()
-
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	res, err := oa.next.RoundTrip(req)
@@ -96,6 +99,72 @@ func (oa *OAuth2ReqAuth) RoundTrip(req *http.Request) (*http.Response, error) {
 	return res, err
 }
 
+// requestAccessToken returns a token which got stored while waiting for the lock of the given
+// storage key or requests a new one from the authorization server.
+func (oa *OAuth2ReqAuth) requestAccessToken(ctx context.Context, storageKey, subjectToken string) (string, error) {
+	unlock := oa.lock(storageKey)
+	defer unlock()
+
+	token, terr := oa.readAccessToken(storageKey)
+	if terr != nil {
+		return "", errors.Backend.Label(oa.config.BackendName).Message("token read error").With(terr)
+	} else if token != "" {
+		return token, nil
+	}
+
+	tokenResponse, tokenResponseData, token, err := oa.oauth2Client.GetTokenResponse(ctx, subjectToken)
+	if err != nil {
+		return "", errors.Backend.Label(oa.config.BackendName).Message("token request error").With(err)
+	}
+
+	oa.updateAccessToken(tokenResponse, tokenResponseData, storageKey)
+	return token, nil
+}
+
+// lock acquires the lock of the given storage key and returns its release function.
+func (oa *OAuth2ReqAuth) lock(storageKey string) func() {
+	oa.locksMu.Lock()
+	l, exist := oa.locks[storageKey]
+	if !exist {
+		l = &tokenLock{}
+		oa.locks[storageKey] = l
+	}
+	l.refs++
+	oa.locksMu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		oa.locksMu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(oa.locks, storageKey)
+		}
+		oa.locksMu.Unlock()
+	}
+}
+
+// readSubjectToken evaluates the subject_token expression with the client request context.
+// A leading "Bearer" authorization scheme gets removed.
+func (oa *OAuth2ReqAuth) readSubjectToken(req *http.Request) (string, error) {
+	subjectToken, err := content.GetContextAttribute(req.Context(), oa.config.HCLBody(), oauth2.SubjectToken)
+	if err != nil {
+		return "", err
+	}
+
+	subjectToken = strings.TrimSpace(subjectToken)
+	if len(subjectToken) > 7 && strings.EqualFold(subjectToken[:7], "bearer ") {
+		subjectToken = strings.TrimSpace(subjectToken[7:])
+	}
+
+	if subjectToken == "" {
+		return "", fmt.Errorf("%s must not be empty", oauth2.SubjectToken)
+	}
+	return subjectToken, nil
+}
+
 func (oa *OAuth2ReqAuth) readAccessToken(key string) (string, error) {
 	if data := oa.memStore.Get(key); data != nil {
 		_, token, err := oauth2.ParseTokenResponse(data.([]byte))
@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOAuth2ReqAuth_lock(t *testing.T) {
+	oa := &OAuth2ReqAuth{locks: make(map[string]*tokenLock)}
+
+	var (
+		active  int32
+		maxSeen int32
+		wg      sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := oa.lock("key")
+			defer unlock()
+
+			if n := atomic.AddInt32(&active, 1); n > atomic.LoadInt32(&maxSeen) {
+				atomic.StoreInt32(&maxSeen, n)
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&active, -1)
+		}()
+	}
+	wg.Wait()
+
+	if maxSeen != 1 {
+		t.Errorf("expected exclusive lock holders, got: %d", maxSeen)
+	}
+
+	// e.g. random subject tokens
+	for i := 0; i < 100; i++ {
+		oa.lock(fmt.Sprintf("subject-%d", i))()
+	}
+
+	if l := len(oa.locks); l != 0 {
+		t.Errorf("expected released locks, got: %d", l)
+	}
+}
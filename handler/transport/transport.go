@@ -24,6 +24,7 @@ var transports sync.Map
 // Config represents the transport <Config> object.
 type Config struct {
 	BackendName            string
+	ClientCertificate      *tls.Certificate
 	DisableCertValidation  bool
 	DisableConnectionReuse bool
 	HTTP2                  bool
@@ -50,6 +51,9 @@ func Get(conf *Config, log *logrus.Entry) *http.Transport {
 		tlsConf := &tls.Config{
 			InsecureSkipVerify: conf.DisableCertValidation,
 		}
+		if conf.ClientCertificate != nil {
+			tlsConf.Certificates = []tls.Certificate{*conf.ClientCertificate}
+		}
 		if conf.Origin != conf.Hostname {
 			tlsConf.ServerName = conf.Hostname
 		}
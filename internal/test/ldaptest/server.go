@@ -0,0 +1,384 @@
+// Package ldaptest provides an embedded LDAP server for tests which supports
+// simple binds, searches and the StartTLS operation.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// PasswordAttribute holds the plain text password of an entry.
+const PasswordAttribute = "userPassword"
+
+const startTLSOID = "1.3.6.1.4.1.1466.20037"
+
+// Entry represents a directory entry.
+type Entry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// Values returns the values of the given attribute; attribute names are case-insensitive.
+func (e *Entry) Values(name string) []string {
+	for k, v := range e.Attributes {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// Server is an in-memory LDAP server listening on a local port.
+type Server struct {
+	// URL is the ldap:// (or ldaps:// for TLS servers) URL of the server.
+	URL string
+
+	binds       int64
+	certificate *x509.Certificate
+	entries     []*Entry
+	listener    net.Listener
+	tlsConfig   *tls.Config
+	wg          sync.WaitGroup
+}
+
+// NewServer starts a server with the given entries.
+func NewServer(entries ...*Entry) *Server {
+	return newServer(false, entries)
+}
+
+// NewTLSServer starts a LDAPS server with the given entries.
+func NewTLSServer(entries ...*Entry) *Server {
+	return newServer(true, entries)
+}
+
+func newServer(useTLS bool, entries []*Entry) *Server {
+	s := &Server{entries: entries}
+	s.tlsConfig, s.certificate = newTLSConfig()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("ldaptest: failed to listen: " + err.Error())
+	}
+
+	s.URL = "ldap://" + ln.Addr().String()
+	if useTLS {
+		ln = tls.NewListener(ln, s.tlsConfig)
+		s.URL = "ldaps://" + ln.Addr().String()
+	}
+	s.listener = ln
+
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Certificate returns the self-signed certificate of the server for 127.0.0.1.
+func (s *Server) Certificate() *x509.Certificate {
+	return s.certificate
+}
+
+// BindCount returns the number of bind requests with credentials.
+func (s *Server) BindCount() int64 {
+	return atomic.LoadInt64(&s.binds)
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	_ = s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		packet, err := ber.ReadPacket(reader)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+
+		messageID := packet.Children[0].Value
+		op := packet.Children[1]
+		write := func(response *ber.Packet) {
+			msg := ber.NewSequence("LDAP Response")
+			msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+			msg.AppendChild(response)
+			_, _ = conn.Write(msg.Bytes())
+		}
+
+		if op.ClassType != ber.ClassApplication {
+			return
+		}
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			write(s.bind(op))
+		case ldap.ApplicationSearchRequest:
+			for _, response := range s.search(op) {
+				write(response)
+			}
+		case ldap.ApplicationExtendedRequest:
+			if len(op.Children) == 0 || stringValue(op.Children[0]) != startTLSOID {
+				write(result(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation"))
+				continue
+			}
+			write(result(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, ""))
+
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err = tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			reader = bufio.NewReader(conn)
+		default: // unbind
+			return
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) *ber.Packet {
+	if len(op.Children) < 3 {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "malformed bind request")
+	}
+
+	dn, password := stringValue(op.Children[1]), stringValue(op.Children[2])
+	if dn == "" && password == "" {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+	}
+
+	atomic.AddInt64(&s.binds, 1)
+
+	entry := s.entry(dn)
+	if entry == nil || password == "" {
+		return result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "")
+	}
+	for _, p := range entry.Values(PasswordAttribute) {
+		if p == password {
+			return result(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+		}
+	}
+	return result(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "")
+}
+
+func (s *Server) search(op *ber.Packet) []*ber.Packet {
+	if len(op.Children) < 8 {
+		return []*ber.Packet{result(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "malformed search request")}
+	}
+
+	baseDN := strings.ToLower(stringValue(op.Children[0]))
+	scope, _ := op.Children[1].Value.(int64)
+	filter := op.Children[6]
+
+	var requested []string
+	for _, a := range op.Children[7].Children {
+		requested = append(requested, stringValue(a))
+	}
+
+	var responses []*ber.Packet
+	for _, entry := range s.entries {
+		dn := strings.ToLower(entry.DN)
+		parent := ""
+		if i := strings.IndexByte(dn, ','); i >= 0 {
+			parent = dn[i+1:]
+		}
+
+		inScope := false
+		switch scope {
+		case ldap.ScopeBaseObject:
+			inScope = dn == baseDN
+		case ldap.ScopeSingleLevel:
+			inScope = parent == baseDN
+		default:
+			inScope = dn == baseDN || strings.HasSuffix(dn, ","+baseDN) || baseDN == ""
+		}
+
+		if !inScope || !match(filter, entry) {
+			continue
+		}
+
+		attributes := ber.NewSequence("Attributes")
+		for name, values := range entry.Attributes {
+			if strings.EqualFold(name, PasswordAttribute) || !isRequested(name, requested) {
+				continue
+			}
+			attribute := ber.NewSequence("Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, v := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+
+		response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "DN"))
+		response.AppendChild(attributes)
+		responses = append(responses, response)
+	}
+
+	return append(responses, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""))
+}
+
+func (s *Server) entry(dn string) *Entry {
+	for _, e := range s.entries {
+		if strings.EqualFold(e.DN, dn) {
+			return e
+		}
+	}
+	return nil
+}
+
+func match(filter *ber.Packet, entry *Entry) bool {
+	if filter == nil || filter.ClassType != ber.ClassContext {
+		return false
+	}
+
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, c := range filter.Children {
+			if !match(c, entry) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, c := range filter.Children {
+			if match(c, entry) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return len(filter.Children) == 1 && !match(filter.Children[0], entry)
+	case ldap.FilterPresent:
+		return strings.EqualFold(stringValue(filter), "objectClass") || len(entry.Values(stringValue(filter))) > 0
+	case ldap.FilterEqualityMatch, ldap.FilterApproxMatch:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range entry.Values(stringValue(filter.Children[0])) {
+			if strings.EqualFold(v, stringValue(filter.Children[1])) {
+				return true
+			}
+		}
+	case ldap.FilterSubstrings:
+		if len(filter.Children) != 2 {
+			return false
+		}
+		for _, v := range entry.Values(stringValue(filter.Children[0])) {
+			if matchSubstrings(strings.ToLower(v), filter.Children[1]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchSubstrings(value string, substrings *ber.Packet) bool {
+	for _, sub := range substrings.Children {
+		part := strings.ToLower(stringValue(sub))
+		switch sub.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, part) {
+				return false
+			}
+			value = value[len(part):]
+		case ldap.FilterSubstringsFinal:
+			return strings.HasSuffix(value, part)
+		default:
+			i := strings.Index(value, part)
+			if i < 0 {
+				return false
+			}
+			value = value[i+len(part):]
+		}
+	}
+	return true
+}
+
+func isRequested(name string, requested []string) bool {
+	if len(requested) == 0 {
+		return true
+	}
+	for _, r := range requested {
+		if r == "*" || strings.EqualFold(r, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns the content of a primitive packet of any class.
+func stringValue(p *ber.Packet) string {
+	if p.Data == nil {
+		return ""
+	}
+	return p.Data.String()
+}
+
+func result(tag ber.Tag, code int64, message string) *ber.Packet {
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "LDAP Result")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return response
+}
+
+func newTLSConfig() (*tls.Config, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic("ldaptest: " + err.Error())
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldaptest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic("ldaptest: " + err.Error())
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		panic("ldaptest: " + err.Error())
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, certificate
+}
@@ -37,3 +37,8 @@ func roundMS(d time.Duration) string {
 	}
 	return fmt.Sprintf("%.3f", math.Round(float64(d)*1000)/1000/float64(time.Millisecond))
 }
+
+// toMS returns the given duration in milliseconds with microsecond precision.
+func toMS(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
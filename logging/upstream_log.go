@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 	"net/http/httptrace"
@@ -73,8 +74,36 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 	*req = *req.WithContext(oCtx)
 
 	rtStart := time.Now()
+	var rtDone time.Time
+	timingsFn := func() map[string]float64 {
+		timingsMu.RLock()
+		defer timingsMu.RUnlock()
+
+		total := rtDone
+		if total.IsZero() {
+			total = time.Now()
+		}
+		return map[string]float64{
+			"connect": toMS(timings["tcp"]),
+			"dns":     toMS(timings["dns"]),
+			"tls":     toMS(timings["tls"]),
+			"total":   toMS(total.Sub(rtStart)),
+			"ttfb":    toMS(timings["ttfb"]),
+		}
+	}
+	// A holder prepared by the caller gets the timings of the first (outermost) round trip only.
+	if holder, ok := req.Context().Value(request.BackendTimings).(*request.Timings); ok {
+		holder.Set(timingsFn)
+	} else {
+		holder = &request.Timings{}
+		holder.Set(timingsFn)
+		*req = *req.WithContext(context.WithValue(req.Context(), request.BackendTimings, holder))
+	}
+
 	beresp, err := u.next.RoundTrip(req)
-	rtDone := time.Now()
+	timingsMu.Lock()
+	rtDone = time.Now()
+	timingsMu.Unlock()
 
 	if req.Host != "" {
 		requestFields["origin"] = req.Host
@@ -128,8 +157,8 @@ func (u *UpstreamLog) RoundTrip(req *http.Request) (*http.Response, error) {
 		"total": roundMS(rtDone.Sub(rtStart)),
 	}
 	timingsMu.RLock()
-	for f, v := range timings { // clone
-		timingResults[f] = v
+	for f, v := range timings {
+		timingResults[f] = roundMS(v)
 	}
 	timingsMu.RUnlock()
 	fields["timings"] = timingResults
@@ -156,8 +185,8 @@ func (u *UpstreamLog) LogEntry() *logrus.Entry {
 	return u.log
 }
 
-func (u *UpstreamLog) withTraceContext(req *http.Request) (Fields, *sync.RWMutex) {
-	timings := Fields{}
+func (u *UpstreamLog) withTraceContext(req *http.Request) (map[string]time.Duration, *sync.RWMutex) {
+	timings := make(map[string]time.Duration)
 	mapMu := &sync.RWMutex{}
 	var timeTTFB, timeGotConn, timeConnect, timeDNS, timeTLS time.Time
 	trace := &httptrace.ClientTrace{
@@ -170,7 +199,7 @@ func (u *UpstreamLog) withTraceContext(req *http.Request) (Fields, *sync.RWMutex
 		GotFirstResponseByte: func() {
 			timeTTFB = time.Now()
 			mapMu.Lock()
-			timings["ttfb"] = roundMS(timeTTFB.Sub(timeGotConn))
+			timings["ttfb"] = timeTTFB.Sub(timeGotConn)
 			mapMu.Unlock()
 		},
 		ConnectStart: func(_, _ string) {
@@ -194,19 +223,19 @@ func (u *UpstreamLog) withTraceContext(req *http.Request) (Fields, *sync.RWMutex
 		ConnectDone: func(network, addr string, err error) {
 			if err == nil {
 				mapMu.Lock()
-				timings["tcp"] = roundMS(time.Since(timeConnect))
+				timings["tcp"] = time.Since(timeConnect)
 				mapMu.Unlock()
 			}
 		},
 		DNSDone: func(_ httptrace.DNSDoneInfo) {
 			mapMu.Lock()
-			timings["dns"] = roundMS(time.Since(timeDNS))
+			timings["dns"] = time.Since(timeDNS)
 			mapMu.Unlock()
 		},
 		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
 			if err == nil {
 				mapMu.Lock()
-				timings["tls"] = roundMS(time.Since(timeTLS))
+				timings["tls"] = time.Since(timeTLS)
 				mapMu.Unlock()
 			}
 		},
@@ -43,7 +43,8 @@ func realmain(arguments []string) int {
 	ctx := context.Background()
 
 	type globalFlags struct {
-		FilePath            string        `env:"file"`
+		DirPath             []string      `env:"file_directory"`
+		FilePath            []string      `env:"file"`
 		FileWatch           bool          `env:"watch"`
 		FileWatchRetryDelay time.Duration `env:"watch_retry_delay"`
 		FileWatchRetries    int           `env:"watch_retries"`
@@ -54,7 +55,8 @@ func realmain(arguments []string) int {
 	var flags globalFlags
 
 	set := flag.NewFlagSet("global options", flag.ContinueOnError)
-	set.StringVar(&flags.FilePath, "f", config.DefaultFilename, "-f ./my-path/couper.hcl")
+	set.Var((*command.StringList)(&flags.FilePath), "f", "-f ./my-path/couper.hcl (repeatable, default: couper.hcl)")
+	set.Var((*command.StringList)(&flags.DirPath), "d", "-d ./my-path/conf.d (repeatable)")
 	set.BoolVar(&flags.FileWatch, "watch", false, "-watch")
 	set.DurationVar(&flags.FileWatchRetryDelay, "watch-retry-delay", time.Millisecond*500, "-watch-retry-delay 1s")
 	set.IntVar(&flags.FileWatchRetries, "watch-retries", 5, "-watch-retries 10")
@@ -74,8 +76,13 @@ func realmain(arguments []string) int {
 	if cmd != "run" { // global options are not required atm, fast exit.
 		err := command.NewCommand(ctx, cmd).Execute(args, nil, nil)
 		if err != nil {
-			set.Usage()
-			color.Red("\n%v", err)
+			switch cmd {
+			case "eval", "verify": // own options and stdout results
+				_, _ = color.New(color.FgRed).Fprintf(os.Stderr, "\n%v\n", err)
+			default:
+				set.Usage()
+				color.Red("\n%v", err)
+			}
 			return 1
 		}
 		return 0
@@ -88,7 +95,22 @@ func realmain(arguments []string) int {
 	}
 	env.Decode(&flags)
 
-	confFile, err := configload.LoadFile(flags.FilePath)
+	if len(flags.FilePath) == 0 && len(flags.DirPath) == 0 {
+		flags.FilePath = []string{config.DefaultFilename}
+	}
+
+	// The configuration load changes the working directory, keep absolute paths for reloads.
+	filePaths, err := command.StringList(flags.FilePath).AbsPaths()
+	if err == nil {
+		flags.FilePath = filePaths
+		flags.DirPath, err = command.StringList(flags.DirPath).AbsPaths()
+	}
+	if err != nil {
+		newLogger(flags.LogFormat, flags.LogLevel, flags.LogPretty).Error(err)
+		return 1
+	}
+
+	confFile, err := configload.LoadFiles(flags.FilePath, flags.DirPath)
 	if err != nil {
 		newLogger(flags.LogFormat, flags.LogLevel, flags.LogPretty).WithError(err).Error()
 		return 1
@@ -134,7 +156,7 @@ func realmain(arguments []string) int {
 		errCh <- execCmd.Execute(args, confFile, logger)
 	}()
 
-	reloadCh := watchConfigFile(confFile.Filename, logger, flags.FileWatchRetries, flags.FileWatchRetryDelay)
+	reloadCh := watchConfigFiles(flags.FilePath, flags.DirPath, logger, flags.FileWatchRetries, flags.FileWatchRetryDelay)
 	for {
 		select {
 		case err = <-errCh:
@@ -168,7 +190,7 @@ func realmain(arguments []string) int {
 			errRetries = 0 // reset
 			logger.Info("reloading couper configuration")
 
-			cf, reloadErr := configload.LoadFile(confFile.Filename) // we are at wd, just filename
+			cf, reloadErr := configload.LoadFiles(flags.FilePath, flags.DirPath)
 			if reloadErr != nil {
 				logger.WithError(reloadErr).Error("reload failed")
 				time.Sleep(flags.FileWatchRetryDelay)
@@ -236,7 +258,9 @@ func newLogger(format, level string, pretty bool) *logrus.Entry {
 	return logger.WithField("type", logConf.TypeFieldKey).WithFields(fields)
 }
 
-func watchConfigFile(name string, logger logrus.FieldLogger, maxRetries int, retryDelay time.Duration) <-chan struct{} {
+// watchConfigFiles watches the given configuration files and all *.hcl files of the given directories.
+// Added or removed directory files change the modification time of their directory.
+func watchConfigFiles(filePaths, dirPaths []string, logger logrus.FieldLogger, maxRetries int, retryDelay time.Duration) <-chan struct{} {
 	reloadCh := make(chan struct{})
 	go func() {
 		ticker := time.NewTicker(time.Second / 4)
@@ -245,7 +269,7 @@ func watchConfigFile(name string, logger logrus.FieldLogger, maxRetries int, ret
 		var errorsSeen int
 		for {
 			<-ticker.C
-			fileInfo, fileErr := os.Stat(name)
+			modTime, fileErr := latestModTime(filePaths, dirPaths)
 			if fileErr != nil {
 				errorsSeen++
 				if errorsSeen >= maxRetries {
@@ -261,20 +285,41 @@ func watchConfigFile(name string, logger logrus.FieldLogger, maxRetries int, ret
 			}
 
 			if lastChange.IsZero() { // first round
-				lastChange = fileInfo.ModTime()
+				lastChange = modTime
 				continue
 			}
 
-			if fileInfo.ModTime().After(lastChange) {
+			if modTime.After(lastChange) {
 				reloadCh <- struct{}{}
 			}
-			lastChange = fileInfo.ModTime()
+			lastChange = modTime
 			errorsSeen = 0
 		}
 	}()
 	return reloadCh
 }
 
+// latestModTime returns the latest modification time of all participating configuration files and directories.
+func latestModTime(filePaths, dirPaths []string) (time.Time, error) {
+	var latest time.Time
+
+	files, err := configload.ConfigFiles(filePaths, dirPaths)
+	if err != nil {
+		return latest, err
+	}
+
+	for _, name := range append(files, dirPaths...) {
+		fileInfo, err := os.Stat(name)
+		if err != nil {
+			return latest, err
+		}
+		if fileInfo.ModTime().After(latest) {
+			latest = fileInfo.ModTime()
+		}
+	}
+	return latest, nil
+}
+
 func newRestartableCommand(ctx context.Context, cmd string) (command.Cmd, chan<- struct{}) {
 	signal := make(chan struct{})
 	watchContext, cancelFn := context.WithCancel(ctx)
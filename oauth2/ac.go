@@ -21,7 +21,9 @@ import (
 type AcClient interface {
 	GetName() string
 	GetTokenResponse(ctx context.Context, callbackURL *url.URL) (map[string]interface{}, error)
+	RefreshTokenResponse(ctx context.Context, previous map[string]interface{}) (map[string]interface{}, error)
 	validateTokenResponseData(ctx context.Context, tokenResponseData map[string]interface{}, hashedVerifierValue, verifierValue, accessToken string) error
+	validateRefreshedTokenResponseData(ctx context.Context, tokenResponseData, previous map[string]interface{}, accessToken string) error
 }
 
 type AbstractAcClient struct {
@@ -97,6 +99,35 @@ func (a AbstractAcClient) GetTokenResponse(ctx context.Context, callbackURL *url
 	return tokenResponseData, nil
 }
 
+// RefreshTokenResponse retrieves a new token response from the token endpoint with the
+// refresh token of the given previous token response data.
+func (a AbstractAcClient) RefreshTokenResponse(ctx context.Context, previous map[string]interface{}) (map[string]interface{}, error) {
+	refreshToken, _ := previous["refresh_token"].(string)
+	if refreshToken == "" {
+		return nil, errors.Oauth2.Message("token refresh error: missing refresh_token")
+	}
+
+	requestParams := map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	}
+
+	_, tokenResponseData, accessToken, err := a.getTokenResponse(ctx, requestParams)
+	if err != nil {
+		return nil, errors.Oauth2.Message("token refresh error").With(err)
+	}
+
+	if accessToken == "" {
+		return nil, errors.Oauth2.Message("token refresh error: missing access_token")
+	}
+
+	if err = a.validateRefreshedTokenResponseData(ctx, tokenResponseData, previous, accessToken); err != nil {
+		return nil, errors.Oauth2.Message("token refresh validation error").With(err)
+	}
+
+	return tokenResponseData, nil
+}
+
 // OAuth2AcClient represents an OAuth2 client using the (plain) authorization code flow.
 type OAuth2AcClient struct {
 	*AbstractAcClient
@@ -108,10 +139,9 @@ func NewOAuth2AC(acClientConf config.OAuth2AcClient, oauth2AsConf config.OAuth2A
 		return nil, fmt.Errorf("grant_type %s not supported", grantType)
 	}
 
-	if teAuthMethod := acClientConf.GetTokenEndpointAuthMethod(); teAuthMethod != nil {
-		if *teAuthMethod != "client_secret_basic" && *teAuthMethod != "client_secret_post" {
-			return nil, fmt.Errorf("token_endpoint_auth_method %s not supported", *teAuthMethod)
-		}
+	client, err := newClient(acClientConf, oauth2AsConf, backend)
+	if err != nil {
+		return nil, err
 	}
 
 	switch acClientConf.(type) {
@@ -128,12 +158,7 @@ func NewOAuth2AC(acClientConf config.OAuth2AcClient, oauth2AsConf config.OAuth2A
 		// skip this for oidc configurations due to possible startup errors
 	}
 
-	client := Client{
-		Backend:      backend,
-		asConfig:     oauth2AsConf,
-		clientConfig: acClientConf,
-	}
-	o := &OAuth2AcClient{&AbstractAcClient{Client: client, name: acClientConf.GetName()}}
+	o := &OAuth2AcClient{&AbstractAcClient{Client: *client, name: acClientConf.GetName()}}
 	o.AcClient = o
 	return o, nil
 }
@@ -143,6 +168,11 @@ func (o *OAuth2AcClient) validateTokenResponseData(_ context.Context, _ map[stri
 	return nil
 }
 
+// validateRefreshedTokenResponseData validates the refreshed token response data (no-op)
+func (o *OAuth2AcClient) validateRefreshedTokenResponseData(_ context.Context, _, _ map[string]interface{}, _ string) error {
+	return nil
+}
+
 func Base64urlSha256(value string) string {
 	h := sha256.New()
 	h.Write([]byte(value))
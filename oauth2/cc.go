@@ -4,33 +4,88 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/avenga/couper/config"
 	"github.com/avenga/couper/errors"
 )
 
-// CcClient represents an OAuth2 client using the client credentials flow.
+// Grant types of the OAuth2 client for backend requests.
+const (
+	GrantTypeClientCredentials = "client_credentials"
+	GrantTypeJWTBearer         = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	GrantTypeTokenExchange     = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	// SubjectToken is the attribute name of the expression evaluating the subject token per request.
+	SubjectToken = "subject_token"
+
+	tokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// CcClient represents an OAuth2 client for backend requests using the client credentials flow,
+// the JWT bearer grant (RFC 7523) or the token exchange (RFC 8693).
 type CcClient struct {
 	*Client
+	conf *config.OAuth2ReqAuth
 }
 
 // NewOAuth2CC creates a new OAuth2 Client Credentials client.
 func NewOAuth2CC(conf *config.OAuth2ReqAuth, backend http.RoundTripper) (*CcClient, error) {
 	backendErr := errors.Backend.Label(conf.Reference())
-	if grantType := conf.GrantType; grantType != "client_credentials" {
+	switch grantType := conf.GrantType; grantType {
+	case GrantTypeClientCredentials:
+	case GrantTypeJWTBearer, GrantTypeTokenExchange:
+		content, _, _ := conf.Remain.PartialContent(&hcl.BodySchema{
+			Attributes: []hcl.AttributeSchema{{Name: SubjectToken}},
+		})
+		if content == nil || content.Attributes[SubjectToken] == nil {
+			return nil, backendErr.Messagef("%s required for grant_type %s", SubjectToken, grantType)
+		}
+	default:
 		return nil, backendErr.Messagef("grant_type %s not supported", grantType)
 	}
 
-	if teAuthMethod := conf.TokenEndpointAuthMethod; teAuthMethod != nil {
-		if *teAuthMethod != "client_secret_basic" && *teAuthMethod != "client_secret_post" {
-			return nil, backendErr.Messagef("token_endpoint_auth_method %s not supported", *teAuthMethod)
-		}
+	client, err := newClient(conf, conf, backend)
+	if err != nil {
+		return nil, backendErr.With(err)
 	}
-	return &CcClient{&Client{backend, conf, conf}}, nil
+	return &CcClient{Client: client, conf: conf}, nil
 }
 
-// GetTokenResponse retrieves the response from the token endpoint
-func (c *CcClient) GetTokenResponse(ctx context.Context) ([]byte, map[string]interface{}, string, error) {
-	tokenResponse, tokenResponseData, accessToken, err := c.getTokenResponse(ctx, nil)
+// RequiresSubjectToken returns whether the token request is made on behalf of a subject.
+func (c *CcClient) RequiresSubjectToken() bool {
+	return c.conf.GrantType != GrantTypeClientCredentials
+}
+
+// GetTokenResponse retrieves the response from the token endpoint. The subjectToken
+// is the assertion for the JWT bearer grant or the subject_token for the token exchange.
+func (c *CcClient) GetTokenResponse(ctx context.Context, subjectToken string) ([]byte, map[string]interface{}, string, error) {
+	var requestParams map[string]string
+	switch c.conf.GrantType {
+	case GrantTypeJWTBearer:
+		requestParams = map[string]string{"assertion": subjectToken}
+	case GrantTypeTokenExchange:
+		subjectTokenType := c.conf.SubjectTokenType
+		if subjectTokenType == "" {
+			subjectTokenType = tokenTypeAccessToken
+		}
+
+		requestParams = map[string]string{
+			"subject_token":      subjectToken,
+			"subject_token_type": subjectTokenType,
+		}
+		for key, value := range map[string]string{
+			"audience":             c.conf.Audience,
+			"requested_token_type": c.conf.RequestedTokenType,
+			"resource":             c.conf.Resource,
+		} {
+			if value != "" {
+				requestParams[key] = value
+			}
+		}
+	}
+
+	tokenResponse, tokenResponseData, accessToken, err := c.getTokenResponse(ctx, requestParams)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -18,6 +18,8 @@ import (
 type Client struct {
 	Backend      http.RoundTripper
 	asConfig     config.OAuth2AS
+	assertion    *clientAssertion
+	authMethod   string
 	clientConfig config.OAuth2Client
 }
 
@@ -54,10 +56,13 @@ func (c *Client) newTokenRequest(ctx context.Context, requestParams map[string]s
 			post.Set(key, value)
 		}
 	}
-	teAuthMethod := c.clientConfig.GetTokenEndpointAuthMethod()
-	if teAuthMethod != nil && *teAuthMethod == "client_secret_post" {
-		post.Set("client_id", c.clientConfig.GetClientID())
-		post.Set("client_secret", c.clientConfig.GetClientSecret())
+	tokenURL, err := c.asConfig.GetTokenEndpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = c.authenticate(post, tokenURL); err != nil {
+		return nil, err
 	}
 
 	// url will be configured via backend roundtrip
@@ -70,7 +75,7 @@ func (c *Client) newTokenRequest(ctx context.Context, requestParams map[string]s
 
 	outreq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	if teAuthMethod == nil || *teAuthMethod == "client_secret_basic" {
+	if c.authMethod == ClientSecretBasic {
 		auth := base64.StdEncoding.EncodeToString([]byte(c.clientConfig.GetClientID() + ":" + c.clientConfig.GetClientSecret()))
 
 		outreq.Header.Set("Authorization", "Basic "+auth)
@@ -78,11 +83,6 @@ func (c *Client) newTokenRequest(ctx context.Context, requestParams map[string]s
 
 	outCtx := context.WithValue(ctx, request.TokenRequest, "oauth2")
 
-	tokenURL, err := c.asConfig.GetTokenEndpoint()
-	if err != nil {
-		return nil, err
-	}
-
 	if tokenURL != "" {
 		outCtx = context.WithValue(outCtx, request.URLAttribute, tokenURL)
 	}
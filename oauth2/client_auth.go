@@ -0,0 +1,142 @@
+package oauth2
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go/v4"
+	"github.com/rs/xid"
+
+	"github.com/avenga/couper/config"
+	"github.com/avenga/couper/config/reader"
+	"github.com/avenga/couper/eval/lib"
+)
+
+// Token endpoint authentication methods.
+const (
+	ClientSecretBasic = "client_secret_basic"
+	ClientSecretPost  = "client_secret_post"
+	PrivateKeyJWT     = "private_key_jwt"
+	TLSClientAuth     = "tls_client_auth"
+
+	clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+// clientAssertion creates the signed JWTs (RFC 7523) for the private_key_jwt client authentication.
+type clientAssertion struct {
+	clientID      string
+	keyID         string
+	signingConfig *lib.JWTSigningConfig
+}
+
+// newClient creates a new <Client> object and validates the configured token endpoint
+// authentication method.
+func newClient(clientConf config.OAuth2Client, asConf config.OAuth2AS, backend http.RoundTripper) (*Client, error) {
+	c := &Client{
+		Backend:      backend,
+		asConfig:     asConf,
+		authMethod:   ClientSecretBasic,
+		clientConfig: clientConf,
+	}
+
+	if teAuthMethod := clientConf.GetTokenEndpointAuthMethod(); teAuthMethod != nil {
+		c.authMethod = *teAuthMethod
+	}
+
+	switch c.authMethod {
+	case ClientSecretBasic, ClientSecretPost:
+		if clientConf.GetClientSecret() == "" {
+			return nil, fmt.Errorf("client_secret required for token_endpoint_auth_method %s", c.authMethod)
+		}
+	case PrivateKeyJWT:
+		assertion, err := newClientAssertion(clientConf.GetClientID(), clientConf.GetClientAssertionProfile())
+		if err != nil {
+			return nil, err
+		}
+		c.assertion = assertion
+	case TLSClientAuth:
+	default:
+		return nil, fmt.Errorf("token_endpoint_auth_method %s not supported", c.authMethod)
+	}
+
+	return c, nil
+}
+
+func newClientAssertion(clientID string, profile *config.ClientAssertionProfile) (*clientAssertion, error) {
+	if profile == nil {
+		return nil, fmt.Errorf("jwt_signing_profile block required for token_endpoint_auth_method %s", PrivateKeyJWT)
+	}
+
+	keyBytes, err := reader.ReadFromAttrFile("jwt_signing_profile key", profile.Key, profile.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := profile.TTL
+	if ttl == "" {
+		ttl = "1m"
+	}
+
+	signingConfig, err := lib.NewJWTSigningConfigFromJWTSigningProfile(&config.JWTSigningProfile{
+		KeyBytes:           keyBytes,
+		SignatureAlgorithm: profile.SignatureAlgorithm,
+		TTL:                ttl,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("jwt_signing_profile: %w", err)
+	}
+
+	return &clientAssertion{
+		clientID:      clientID,
+		keyID:         profile.KeyID,
+		signingConfig: signingConfig,
+	}, nil
+}
+
+// create returns a signed client assertion for the given token endpoint.
+func (ca *clientAssertion) create(tokenURL string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": ca.clientID,
+		"sub": ca.clientID,
+		"aud": tokenURL,
+		"jti": xid.New().String(),
+		"iat": now.Unix(),
+		"exp": now.Add(ca.signingConfig.TTL).Unix(),
+	}
+
+	signingMethod := jwt.GetSigningMethod(ca.signingConfig.SignatureAlgorithm)
+	if signingMethod == nil {
+		return "", fmt.Errorf("no signing method for given algorithm: %s", ca.signingConfig.SignatureAlgorithm)
+	}
+
+	token := jwt.NewWithClaims(signingMethod, claims)
+	if ca.keyID != "" {
+		token.Header["kid"] = ca.keyID
+	}
+
+	return token.SignedString(ca.signingConfig.Key)
+}
+
+// authenticate adds the client authentication to the token request parameters.
+// The client_secret_basic method is applied to the request header separately.
+func (c *Client) authenticate(post url.Values, tokenURL string) error {
+	switch c.authMethod {
+	case ClientSecretPost:
+		post.Set("client_id", c.clientConfig.GetClientID())
+		post.Set("client_secret", c.clientConfig.GetClientSecret())
+	case PrivateKeyJWT:
+		assertion, err := c.assertion.create(tokenURL)
+		if err != nil {
+			return err
+		}
+		post.Set("client_id", c.clientConfig.GetClientID())
+		post.Set("client_assertion_type", clientAssertionType)
+		post.Set("client_assertion", assertion)
+	case TLSClientAuth: // the client certificate is configured at the backend
+		post.Set("client_id", c.clientConfig.GetClientID())
+	}
+	return nil
+}
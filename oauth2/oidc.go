@@ -86,6 +86,30 @@ func (o *OidcClient) getOidcAsConfig() config.OidcAS {
 
 // validateTokenResponseData validates the token response data
 func (o *OidcClient) validateTokenResponseData(ctx context.Context, tokenResponseData map[string]interface{}, hashedVerifierValue, verifierValue, accessToken string) error {
+	if _, ok := tokenResponseData["id_token"].(string); !ok {
+		return errors.Oauth2.Message("missing id_token in token response")
+	}
+
+	return o.validateIdToken(ctx, tokenResponseData, hashedVerifierValue, verifierValue, accessToken, nil)
+}
+
+// validateRefreshedTokenResponseData validates the ID token of a refresh token response, if any.
+func (o *OidcClient) validateRefreshedTokenResponseData(ctx context.Context, tokenResponseData, previous map[string]interface{}, accessToken string) error {
+	// 12.2.  Successful Refresh Response
+	// Upon successful validation of the Refresh Token, the response body is
+	// the Token Response of Section 3.1.3.3 except that it might not contain
+	// an id_token.
+	if _, ok := tokenResponseData["id_token"].(string); !ok {
+		return nil
+	}
+
+	return o.validateIdToken(ctx, tokenResponseData, "", "", accessToken, previous)
+}
+
+// validateIdToken validates the ID token of the given token response data and sets
+// the id_token_claims, userinfo and claims properties. The previous token response
+// data is only given for refresh token responses.
+func (o *OidcClient) validateIdToken(ctx context.Context, tokenResponseData map[string]interface{}, hashedVerifierValue, verifierValue, accessToken string, previous map[string]interface{}) error {
 	if err := o.refreshJWTParser(); err != nil {
 		return err
 	}
@@ -94,44 +118,44 @@ func (o *OidcClient) validateTokenResponseData(ctx context.Context, tokenRespons
 	jwtParser := o.jwtParser
 	o.issLock.RUnlock()
 
-	if idTokenString, ok := tokenResponseData["id_token"].(string); ok {
-		idToken, _, err := jwtParser.ParseUnverified(idTokenString, jwt.MapClaims{})
-		if err != nil {
-			return err
-		}
+	idTokenString, _ := tokenResponseData["id_token"].(string)
+	idToken, _, err := jwtParser.ParseUnverified(idTokenString, jwt.MapClaims{})
+	if err != nil {
+		return err
+	}
 
-		// 2.  ID Token
-		// iss
-		// 		REQUIRED.
-		// aud
-		// 		REQUIRED.
-		// 3.1.3.7.  ID Token Validation
-		// 3. The Client MUST validate that the aud (audience) Claim contains
-		//    its client_id value registered at the Issuer identified by the
-		//    iss (issuer) Claim as an audience. The aud (audience) Claim MAY
-		//    contain an array with more than one element. The ID Token MUST
-		//    be rejected if the ID Token does not list the Client as a valid
-		//    audience, or if it contains additional audiences not trusted by
-		//    the Client.
-		if err = idToken.Claims.Valid(jwtParser.ValidationHelper); err != nil {
-			return err
-		}
+	// 2.  ID Token
+	// iss
+	// 		REQUIRED.
+	// aud
+	// 		REQUIRED.
+	// 3.1.3.7.  ID Token Validation
+	// 3. The Client MUST validate that the aud (audience) Claim contains
+	//    its client_id value registered at the Issuer identified by the
+	//    iss (issuer) Claim as an audience. The aud (audience) Claim MAY
+	//    contain an array with more than one element. The ID Token MUST
+	//    be rejected if the ID Token does not list the Client as a valid
+	//    audience, or if it contains additional audiences not trusted by
+	//    the Client.
+	if err = idToken.Claims.Valid(jwtParser.ValidationHelper); err != nil {
+		return err
+	}
 
-		idtc, userinfo, err := o.validateIdTokenClaims(ctx, idToken.Claims, hashedVerifierValue, verifierValue, accessToken)
-		if err != nil {
-			return err
-		}
+	idtc, userinfo, err := o.validateIdTokenClaims(ctx, idToken.Claims, hashedVerifierValue, verifierValue, accessToken, previous)
+	if err != nil {
+		return err
+	}
 
-		tokenResponseData["id_token_claims"] = idtc
+	tokenResponseData["id_token_claims"] = idtc
+	if userinfo != nil {
 		tokenResponseData["userinfo"] = userinfo
-
-		return nil
 	}
+	tokenResponseData["claims"] = mergeClaims(userinfo, idtc)
 
-	return errors.Oauth2.Message("missing id_token in token response")
+	return nil
 }
 
-func (o *OidcClient) validateIdTokenClaims(ctx context.Context, claims jwt.Claims, hashedVerifierValue, verifierValue string, accessToken string) (map[string]interface{}, map[string]interface{}, error) {
+func (o *OidcClient) validateIdTokenClaims(ctx context.Context, claims jwt.Claims, hashedVerifierValue, verifierValue string, accessToken string, previous map[string]interface{}) (map[string]interface{}, map[string]interface{}, error) {
 	var idTokenClaims jwt.MapClaims
 	if tc, ok := claims.(jwt.MapClaims); ok {
 		idTokenClaims = tc
@@ -162,13 +186,29 @@ func (o *OidcClient) validateIdTokenClaims(ctx context.Context, claims jwt.Claim
 		return nil, nil, errors.Oauth2.Messagef("azp claim / client ID mismatch, azp = %q, client ID = %q", azp, o.clientConfig.GetClientID())
 	}
 
+	if previous != nil {
+		// 12.2.  Successful Refresh Response
+		// its iss Claim Value MUST be the same as in the ID Token issued when
+		// the original authentication occurred, its sub Claim Value MUST be
+		// the same as in the ID Token issued when the original authentication
+		// occurred, [...] if the ID Token contains an auth_time Claim, its
+		// value MUST represent the time of the original authentication - not
+		// the time that the new ID token is issued.
+		previousClaims := mapClaims(previous["id_token_claims"])
+		for _, name := range []string{"iss", "sub"} {
+			if idTokenClaims[name] != previousClaims[name] {
+				return nil, nil, errors.Oauth2.Messagef("%s claim mismatch in refreshed ID token: %v vs. %v", name, idTokenClaims[name], previousClaims[name])
+			}
+		}
+	}
+
 	verifierMethod, err := getVerifierMethod(ctx, o.asConfig)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// validate nonce claim value against CSRF token
-	if verifierMethod == "nonce" {
+	// validate nonce claim value against CSRF token; there is no verifier value for refresh token responses
+	if verifierMethod == "nonce" && previous == nil {
 		// 11. If a nonce value was sent in the Authentication Request, a nonce
 		//     Claim MUST be present and its value checked to verify that it is the
 		//     same value as the one that was sent in the Authentication Request.
@@ -184,6 +224,11 @@ func (o *OidcClient) validateIdTokenClaims(ctx context.Context, claims jwt.Claim
 		if hashedVerifierValue != nonce {
 			return nil, nil, errors.Oauth2.Messagef("nonce mismatch: %q (from nonce claim) vs. %q (verifier_value: %q)", nonce, hashedVerifierValue, verifierValue)
 		}
+
+		// replay detection: a nonce is valid for one ID token until it expires
+		if o.config.UseNonce(nonce, claimUnixTime(idTokenClaims["exp"])) {
+			return nil, nil, errors.Oauth2.Messagef("nonce %q already used", nonce)
+		}
 	}
 
 	// 2.  ID Token
@@ -196,6 +241,10 @@ func (o *OidcClient) validateIdTokenClaims(ctx context.Context, claims jwt.Claim
 		return nil, nil, errors.Oauth2.Messagef("missing sub claim in ID token, claims='%#v'", idTokenClaims)
 	}
 
+	if o.config.DisableUserinfo {
+		return idTokenClaims, nil, nil
+	}
+
 	userinfoResponse, err := o.requestUserinfo(ctx, accessToken)
 	if err != nil {
 		return nil, nil, err
@@ -222,6 +271,42 @@ func (o *OidcClient) validateIdTokenClaims(ctx context.Context, claims jwt.Claim
 	return idTokenClaims, userinfoData, nil
 }
 
+func mapClaims(claims interface{}) map[string]interface{} {
+	switch c := claims.(type) {
+	case map[string]interface{}:
+		return c
+	case jwt.MapClaims:
+		return c
+	}
+	return nil
+}
+
+// mergeClaims merges the userinfo claims with the ID token claims. The ID token
+// claims take precedence.
+func mergeClaims(userinfo, idTokenClaims map[string]interface{}) map[string]interface{} {
+	claims := make(map[string]interface{}, len(userinfo)+len(idTokenClaims))
+	for k, v := range userinfo {
+		claims[k] = v
+	}
+	for k, v := range idTokenClaims {
+		claims[k] = v
+	}
+	return claims
+}
+
+// claimUnixTime returns the given numeric claim value or an hour from now as fallback.
+func claimUnixTime(value interface{}) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+	}
+	return time.Now().Add(time.Hour).Unix()
+}
+
 func (o *OidcClient) requestUserinfo(ctx context.Context, accessToken string) ([]byte, error) {
 	userinfoReq, err := o.newUserinfoRequest(ctx, accessToken)
 	if err != nil {
@@ -19,7 +19,9 @@ import (
 // OpenidConfiguration represents an OpenID configuration (.../.well-known/openid-configuration)
 type OpenidConfiguration struct {
 	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	EndSessionEndpoint            string   `json:"end_session_endpoint"`
 	Issuer                        string   `json:"issuer"`
+	JwksURI                       string   `json:"jwks_uri"`
 	TokenEndpoint                 string   `json:"token_endpoint"`
 	UserinfoEndpoint              string   `json:"userinfo_endpoint"`
 	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
@@ -40,6 +42,7 @@ type Config struct {
 	*config.OIDC
 	Backend    http.RoundTripper
 	memStore   *cache.MemoryStore
+	nonceMu    sync.Mutex
 	remoteConf *OpenidConfiguration
 	remoteMu   sync.RWMutex
 	ttl        int64
@@ -82,6 +85,17 @@ func (o *Config) GetAuthorizationEndpoint(uid string) (string, error) {
 	return o.remoteConf.AuthorizationEndpoint, nil
 }
 
+func (o *Config) GetEndSessionEndpoint() (string, error) {
+	err := o.getFreshIfExpired("")
+	if err != nil {
+		return "", err
+	}
+
+	o.remoteMu.RLock()
+	defer o.remoteMu.RUnlock()
+	return o.remoteConf.EndSessionEndpoint, nil
+}
+
 func (o *Config) GetIssuer() (string, error) {
 	err := o.getFreshIfExpired("")
 	if err != nil {
@@ -93,6 +107,17 @@ func (o *Config) GetIssuer() (string, error) {
 	return o.remoteConf.Issuer, nil
 }
 
+func (o *Config) GetJwksURI() (string, error) {
+	err := o.getFreshIfExpired("")
+	if err != nil {
+		return "", err
+	}
+
+	o.remoteMu.RLock()
+	defer o.remoteMu.RUnlock()
+	return o.remoteConf.JwksURI, nil
+}
+
 func (o *Config) GetTokenEndpoint() (string, error) {
 	err := o.getFreshIfExpired("")
 	if err != nil {
@@ -115,6 +140,25 @@ func (o *Config) GetUserinfoEndpoint() (string, error) {
 	return o.remoteConf.UserinfoEndpoint, nil
 }
 
+// UseNonce marks the given nonce as used until the given expiration (unix time)
+// and reports whether it has already been used before.
+func (o *Config) UseNonce(nonce string, exp int64) bool {
+	o.nonceMu.Lock()
+	defer o.nonceMu.Unlock()
+
+	key := "nonce|" + o.Name + "|" + nonce
+	if o.memStore.Get(key) != nil {
+		return true
+	}
+
+	ttl := exp - time.Now().Unix()
+	if ttl < 1 {
+		ttl = 1
+	}
+	o.memStore.Set(key, true, ttl)
+	return false
+}
+
 func (o *Config) getFreshIfExpired(uid string) error {
 	key := o.Name + o.ConfigurationURL
 	confVal := o.memStore.Get(key)
@@ -230,6 +230,7 @@ func (s *HTTPServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 
 	ctx := context.WithValue(req.Context(), request.XFF, req.Header.Get("X-Forwarded-For"))
+	ctx = context.WithValue(ctx, request.ClientIP, s.settings.ClientIP(req))
 	ctx = context.WithValue(ctx, request.LogEntry, s.log)
 	if hs, stringer := h.(fmt.Stringer); stringer {
 		ctx = context.WithValue(ctx, request.Handler, hs.String())
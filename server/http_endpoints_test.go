@@ -7,6 +7,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"path"
 	"reflect"
 	"strconv"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"github.com/avenga/couper/internal/test"
+	"github.com/avenga/couper/internal/test/ldaptest"
 )
 
 const testdataPath = "testdata/endpoints"
@@ -437,3 +439,292 @@ func TestHTTPServer_NoGzipForSmallContent(t *testing.T) {
 		})
 	}
 }
+
+func TestEndpoints_CSRF(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, logHook := newCouper(path.Join(testdataPath, "11_couper.hcl"), helper)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodGet, "http://example.com:8080/app/", nil)
+	helper.Must(err)
+
+	res, err := client.Do(req)
+	helper.Must(err)
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("bootstrap: expected status 200, got: %d", res.StatusCode)
+	}
+
+	var token string
+	for _, c := range res.Cookies() {
+		if c.Name == "_couper_csrf" {
+			token = c.Value
+			if !c.Secure || c.HttpOnly || c.SameSite != http.SameSiteStrictMode {
+				t.Errorf("bootstrap: unexpected cookie attributes: %#v", c)
+			}
+		}
+	}
+	if token == "" {
+		t.Fatal("bootstrap: expected csrf cookie")
+	}
+
+	resBytes, err := io.ReadAll(res.Body)
+	helper.Must(err)
+	res.Body.Close()
+
+	if exp := `window.bootstrap = {"csrf_token":"` + token + `"};`; !strings.Contains(string(resBytes), exp) {
+		t.Errorf("bootstrap: expected %q, got: %s", exp, resBytes)
+	}
+
+	type testCase struct {
+		name      string
+		path      string
+		header    http.Header
+		expStatus int
+		expBody   string
+		expErrMsg string
+	}
+
+	for _, tc := range []testCase{
+		{"no cookie", "/submit", http.Header{"X-Csrf-Token": {token}, "Origin": {"http://example.com:8080"}}, http.StatusForbidden, "", "missing token"},
+		{"no header", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "Origin": {"http://example.com:8080"}}, http.StatusForbidden, "", "missing X-CSRF-Token header"},
+		{"token mismatch", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {"other"}, "Origin": {"http://example.com:8080"}}, http.StatusForbidden, "", "token mismatch"},
+		{"missing origin", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {token}}, http.StatusForbidden, "", "missing Origin or Referer header"},
+		{"same origin referer", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {token}, "Referer": {"http://example.com:8080/app/"}}, http.StatusOK, `{"token":"` + token + `"}`, ""},
+		{"same origin", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {token}, "Origin": {"http://example.com:8080"}}, http.StatusOK, `{"token":"` + token + `"}`, ""},
+		{"allowed origin", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {token}, "Origin": {"https://app.example.com"}}, http.StatusOK, `{"token":"` + token + `"}`, ""},
+		{"cross origin", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {token}, "Origin": {"https://evil.example.com"}}, http.StatusForbidden, "", `origin mismatch: "https://evil.example.com"`},
+		{"cross origin referer", "/submit", http.Header{"Cookie": {"_couper_csrf=" + token}, "X-Csrf-Token": {token}, "Referer": {"https://evil.example.com/form"}}, http.StatusForbidden, "", `origin mismatch: "https://evil.example.com"`},
+		{"disabled", "/public", nil, http.StatusNoContent, "", ""},
+		{"error_handler", "/handled", nil, http.StatusTeapot, "", "missing token"},
+		{"synchronizer token without session", "/sync/data", nil, http.StatusForbidden, "", "missing token"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodPost, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			logHook.Reset()
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				subT.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			res.Body.Close()
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				subT.Errorf("expected body %q, got: %q", tc.expBody, string(b))
+			}
+
+			if tc.expErrMsg != "" {
+				if tc.expStatus == http.StatusForbidden && res.Header.Get("Couper-Error") != "access control error" {
+					subT.Errorf("expected access control error, got: %q", res.Header.Get("Couper-Error"))
+				}
+				entry := logHook.LastEntry()
+				if entry == nil || entry.Data["error_type"] != "csrf" || !strings.HasSuffix(entry.Message, tc.expErrMsg) {
+					subT.Errorf("expected error log %q, got: %#v", tc.expErrMsg, entry)
+				}
+			}
+		})
+	}
+
+	// synchronizer token mode
+	req, err = http.NewRequest(http.MethodGet, "http://example.com:8080/sync/data", nil)
+	helper.Must(err)
+	req.Header.Set("Cookie", "session=abc")
+
+	res, err = client.Do(req)
+	helper.Must(err)
+
+	resBytes, err = io.ReadAll(res.Body)
+	helper.Must(err)
+	res.Body.Close()
+
+	syncToken := string(resBytes)
+	if syncToken == "" || syncToken == token {
+		t.Fatalf("expected a synchronizer token, got: %q", syncToken)
+	}
+	if len(res.Cookies()) > 0 {
+		t.Errorf("expected no cookie in synchronizer token mode, got: %v", res.Cookies())
+	}
+
+	for _, sc := range []struct {
+		session   string
+		token     string
+		expStatus int
+	}{
+		{"abc", syncToken, http.StatusOK},
+		{"abc", token, http.StatusForbidden},
+		{"other", syncToken, http.StatusForbidden},
+	} {
+		req, err = http.NewRequest(http.MethodPost, "http://example.com:8080/sync/data", nil)
+		helper.Must(err)
+		req.Header.Set("Cookie", "session="+sc.session)
+		req.Header.Set("X-Sync-Token", sc.token)
+
+		res, err = client.Do(req)
+		helper.Must(err)
+
+		if res.StatusCode != sc.expStatus {
+			t.Errorf("synchronizer token %q for session %q: expected status %d, got: %d", sc.token, sc.session, sc.expStatus, res.StatusCode)
+		}
+	}
+}
+
+func TestEndpoints_BasicAuthLDAP(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	ldapServer := ldaptest.NewServer(
+		&ldaptest.Entry{DN: "cn=service,dc=example,dc=com", Attributes: map[string][]string{
+			ldaptest.PasswordAttribute: {"secret"},
+		}},
+		&ldaptest.Entry{DN: "uid=john,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"uid":                      {"john"},
+			"memberOf":                 {"cn=admins,ou=groups,dc=example,dc=com", "cn=users,ou=groups,dc=example,dc=com"},
+			ldaptest.PasswordAttribute: {"pass"},
+		}},
+		&ldaptest.Entry{DN: "uid=jane,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"uid":                      {"jane"},
+			"memberOf":                 {"cn=users,ou=groups,dc=example,dc=com"},
+			ldaptest.PasswordAttribute: {"pass"},
+		}},
+		&ldaptest.Entry{DN: "uid=guest,ou=people,dc=example,dc=com", Attributes: map[string][]string{
+			"uid":                      {"guest"},
+			ldaptest.PasswordAttribute: {"pass"},
+		}},
+	)
+	defer ldapServer.Close()
+
+	shutdown, logHook := newCouperWithTemplate(path.Join(testdataPath, "12_couper.hcl"), helper,
+		map[string]interface{}{"ldapURL": ldapServer.URL})
+	defer shutdown()
+
+	type testCase struct {
+		name       string
+		path       string
+		user, pass string
+		expStatus  int
+		expBody    string
+		expErrMsg  string
+	}
+
+	for _, tc := range []testCase{
+		{"search", "/search", "john", "pass", http.StatusOK,
+			`{"dn":"uid=john,ou=people,dc=example,dc=com","groups":["cn=admins,ou=groups,dc=example,dc=com","cn=users,ou=groups,dc=example,dc=com"],"user":"john"}`, ""},
+		{"search wrong password", "/search", "john", "wrong", http.StatusUnauthorized, "", "ldap: credential mismatch"},
+		{"search insufficient scope", "/search", "jane", "pass", http.StatusForbidden, "", "scope: no scope granted"},
+		{"search missing group", "/search", "guest", "pass", http.StatusUnauthorized, "", "ldap: required group membership missing"},
+		{"template", "/template", "jane", "pass", http.StatusOK,
+			`{"dn":"uid=jane,ou=people,dc=example,dc=com","groups":["cn=users,ou=groups,dc=example,dc=com"],"user":"jane"}`, ""},
+		{"template unknown user", "/template", "joe", "pass", http.StatusUnauthorized, "", "ldap: credential mismatch"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+			req.SetBasicAuth(tc.user, tc.pass)
+
+			logHook.Reset()
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				subT.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			res.Body.Close()
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				subT.Errorf("expected body %q, got: %q", tc.expBody, string(b))
+			}
+
+			if tc.expErrMsg != "" {
+				entry := logHook.LastEntry()
+				if entry == nil || !strings.Contains(entry.Message, tc.expErrMsg) {
+					subT.Errorf("expected error log %q, got: %#v", tc.expErrMsg, entry)
+				}
+			}
+		})
+	}
+}
+
+func TestEndpoints_Templates(t *testing.T) {
+	client := newClient()
+	helper := test.New(t)
+
+	shutdown, _ := newCouper(path.Join(testdataPath, "13_couper.hcl"), helper)
+	defer shutdown()
+
+	type testCase struct {
+		name        string
+		path        string
+		expStatus   int
+		expID       string
+		expBody     string
+		expTeam     string
+		expTemplate string
+	}
+
+	for _, tc := range []testCase{
+		{"proxy with request parameter", "/api/users/123", http.StatusOK, "user-123", "", "users", "headers"},
+		{"local attribute override", "/api/orders", http.StatusOK, "all", "", "override", ""},
+		{"response", "/status", http.StatusCreated, "", `{"message":"ok"}`, "", ""},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			h.Must(err)
+
+			res, err := client.Do(req)
+			h.Must(err)
+
+			if res.StatusCode != tc.expStatus {
+				subT.Errorf("expected status %d, got: %d", tc.expStatus, res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			h.Must(err)
+			res.Body.Close()
+
+			if tc.expID != "" {
+				type result struct {
+					Query url.Values
+				}
+				r := &result{}
+				h.Must(json.Unmarshal(b, r))
+				if id := r.Query.Get("id"); id != tc.expID {
+					subT.Errorf("expected backend query id %q, got: %q", tc.expID, id)
+				}
+			}
+
+			if tc.expBody != "" && string(b) != tc.expBody {
+				subT.Errorf("expected body %q, got: %q", tc.expBody, string(b))
+			}
+
+			if v := res.Header.Get("X-Team"); v != tc.expTeam {
+				subT.Errorf("expected x-team header %q, got: %q", tc.expTeam, v)
+			}
+
+			if v := res.Header.Get("X-Template"); v != tc.expTemplate {
+				subT.Errorf("expected x-template header %q, got: %q", tc.expTemplate, v)
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -2348,6 +2351,138 @@ func TestHTTPServer_backend_requests_variables(t *testing.T) {
 	}
 }
 
+func TestHTTPServer_ConnectionVariables(t *testing.T) {
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/integration/endpoint_eval/20_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name      string
+		path      string
+		xff       string
+		expHeader http.Header
+	}
+
+	for _, tc := range []testCase{
+		{"without xff", "/connection", "", http.Header{
+			"X-Remote-Ip":        {"127.0.0.1"},
+			"X-Remote-Port":      {"set"},
+			"X-Protocol-Version": {"HTTP/1.1"},
+			"X-Received-At":      {"set"},
+			"X-Tls":              {"null"},
+		}},
+		{"trusted proxies", "/connection", "203.0.113.5, 10.1.2.3", http.Header{
+			"X-Remote-Ip":   {"203.0.113.5"},
+			"X-Remote-Port": {"null"},
+		}},
+		{"untrusted proxy", "/connection", "203.0.113.5, 198.51.100.1, 10.1.2.3", http.Header{
+			"X-Remote-Ip": {"198.51.100.1"},
+		}},
+		{"only trusted proxies", "/connection", "10.0.0.1", http.Header{
+			"X-Remote-Ip": {"10.0.0.1"},
+		}},
+		{"invalid xff", "/connection", "foo, 10.0.0.1", http.Header{
+			"X-Remote-Ip": {"10.0.0.1"},
+		}},
+		{"backend timings", "/timings", "", http.Header{
+			"X-Timings": {"ok"},
+			"X-Total":   {"set"},
+		}},
+		{"request timings", "/timings/request", "", http.Header{
+			"X-Total": {"set"},
+		}},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodGet, "http://localhost:8080"+tc.path, nil)
+			helper.Must(err)
+
+			if tc.xff != "" {
+				req.Header.Set("X-Forwarded-For", tc.xff)
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != http.StatusOK {
+				subT.Fatalf("expected status OK, got: %d", res.StatusCode)
+			}
+
+			for k, v := range tc.expHeader {
+				if got := res.Header.Get(k); got != v[0] {
+					subT.Errorf("expected %s: %q, got: %q", k, v[0], got)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPServer_XMLAndMultipartBodies(t *testing.T) {
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/integration/endpoint_eval/21_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		expBody     string
+	}
+
+	for _, tc := range []testCase{
+		{"backend xml response", "/xml/backend", "", "",
+			`{"id":"42","items":["a","b"]}`},
+		{"xml request", "/xml/request", "text/xml", `<a x="1"><b>c</b></a>`,
+			`{"a":{"@x":"1","b":"c"}}`},
+		{"invalid xml request", "/xml/request", "application/xml", `<a>`,
+			`{}`},
+		{"multipart request", "/multipart", "multipart/form-data; boundary=couper", "--couper\r\n" +
+			"Content-Disposition: form-data; name=\"user\"\r\n\r\nhans\r\n" +
+			"--couper\r\n" +
+			"Content-Disposition: form-data; name=\"doc\"; filename=\"a.txt\"\r\n" +
+			"Content-Type: text/plain\r\n\r\nfoo\r\n" +
+			"--couper--\r\n",
+			`{"fields":{"user":["hans"]},"files":{"doc":[{"content":"Zm9v","content_type":"text/plain","filename":"a.txt","size":3}]}}`},
+		{"no multipart request", "/multipart", "application/x-www-form-urlencoded", "user=hans",
+			`{}`},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+
+			method := http.MethodGet
+			if tc.body != "" {
+				method = http.MethodPost
+			}
+
+			req, err := http.NewRequest(method, "http://localhost:8080"+tc.path, strings.NewReader(tc.body))
+			helper.Must(err)
+
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != http.StatusOK {
+				subT.Fatalf("expected status OK, got: %d", res.StatusCode)
+			}
+
+			b, err := io.ReadAll(res.Body)
+			helper.Must(err)
+			helper.Must(res.Body.Close())
+
+			if string(b) != tc.expBody {
+				subT.Errorf("expected body:\n%s\ngot:\n%s", tc.expBody, string(b))
+			}
+		})
+	}
+}
+
 func TestHTTPServer_request_variables(t *testing.T) {
 	client := newClient()
 
@@ -3032,6 +3167,233 @@ func Test_Scope(t *testing.T) {
 	}
 }
 
+func TestAccessControl_Groups(t *testing.T) {
+	h := test.New(t)
+	client := newClient()
+
+	shutdown, hook := newCouper("testdata/integration/config/10_couper.hcl", test.New(t))
+	defer shutdown()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "me",
+	})
+	token, tokenErr := tok.SignedString([]byte("y0urS3cr3t"))
+	h.Must(tokenErr)
+
+	type testCase struct {
+		name        string
+		path        string
+		header      http.Header
+		status      int
+		expHeader   http.Header
+		wantErrLog  string
+		wantErrType string
+	}
+
+	basicAuth := "Basic am9objpzZWNyZXQ=" // john:secret
+
+	for _, tc := range []testCase{
+		{"any_of: token", "/any", http.Header{"X-Token": []string{token}}, http.StatusOK, http.Header{"X-Matched": []string{"token"}}, "", ""},
+		{"any_of: basic auth", "/any", http.Header{"Authorization": []string{basicAuth}}, http.StatusOK, http.Header{"X-Matched": []string{"user"}}, "", ""},
+		{"any_of: none", "/any", http.Header{}, http.StatusForbidden, nil, "access control error: token_or_user: no alternative granted access: [token: token required, user: credentials required]", "any_of"},
+		{"all_of: both", "/all", http.Header{"X-Token": []string{token}, "Authorization": []string{basicAuth}}, http.StatusOK, http.Header{"X-Sub": []string{"me"}}, "", ""},
+		{"all_of: token only", "/all", http.Header{"X-Token": []string{token}}, http.StatusUnauthorized, http.Header{"Www-Authenticate": []string{"Basic"}}, "access control error: user: credentials required", "basic_auth_credentials_missing"},
+		{"all_of: basic auth only", "/all", http.Header{"Authorization": []string{basicAuth}}, http.StatusUnauthorized, nil, "access control error: token: token required", "jwt_token_missing"},
+		{"nested: cookie", "/nested", http.Header{"Cookie": []string{"tok=" + token}}, http.StatusOK, http.Header{"X-Matched": []string{"cookie_token"}}, "", ""},
+		{"nested: all_of", "/nested", http.Header{"X-Token": []string{token}, "Authorization": []string{basicAuth}}, http.StatusOK, http.Header{"X-Matched": []string{"token_and_user"}}, "", ""},
+		{"nested: none", "/nested", http.Header{"X-Token": []string{token}}, http.StatusForbidden, nil, "access control error: nested: no alternative granted access: [cookie_token: token required, user: credentials required]", "any_of"},
+		{"error_handler", "/handled", http.Header{}, http.StatusTeapot, nil, "access control error: handled: no alternative granted access", "any_of"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodGet, "http://back.end:8080"+tc.path, nil)
+			helper.Must(err)
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != tc.status {
+				subT.Errorf("expected Status %d, got: %d", tc.status, res.StatusCode)
+				return
+			}
+
+			for k := range tc.expHeader {
+				if v := res.Header.Get(k); v != tc.expHeader.Get(k) {
+					subT.Errorf("expected header %s: %q, got: %q", k, tc.expHeader.Get(k), v)
+				}
+			}
+
+			message := getAccessControlMessages(hook)
+			if tc.wantErrLog == "" {
+				if message != "" {
+					subT.Errorf("Expected error log: %q, actual: %#v", tc.wantErrLog, message)
+				}
+			} else {
+				if !strings.HasPrefix(message, tc.wantErrLog) {
+					subT.Errorf("Expected error log message: %q, actual: %#v", tc.wantErrLog, message)
+				}
+				errorType := getAccessLogErrorType(hook)
+				if errorType != tc.wantErrType {
+					subT.Errorf("Expected error type: %q, actual: %q", tc.wantErrType, errorType)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessControl_Authorization(t *testing.T) {
+	h := test.New(t)
+	client := newClient()
+
+	shutdown, hook := newCouper("testdata/integration/config/11_couper.hcl", test.New(t))
+	defer shutdown()
+
+	newToken := func(claims jwt.MapClaims) string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token, tokenErr := tok.SignedString([]byte("y0urS3cr3t"))
+		h.Must(tokenErr)
+		return token
+	}
+
+	reader := newToken(jwt.MapClaims{"tenant": "a", "role": "reader"})
+	writer := newToken(jwt.MapClaims{"tenant": "a", "role": "writer"})
+	noTenant := newToken(jwt.MapClaims{"role": "writer"})
+
+	type testCase struct {
+		name        string
+		method      string
+		path        string
+		token       string
+		status      int
+		wantErrLog  string
+		wantErrType string
+	}
+
+	for _, tc := range []testCase{
+		{"reader: GET own tenant", http.MethodGet, "/tenants/a", reader, http.StatusNoContent, "", ""},
+		{"reader: POST own tenant", http.MethodPost, "/tenants/a", reader, http.StatusForbidden, "access control error: authorization: rule 11_couper.hcl:7,9-74: not fulfilled", "authorization"},
+		{"reader: GET other tenant", http.MethodGet, "/tenants/b", reader, http.StatusForbidden, "access control error: authorization: rule 11_couper.hcl:6,9-67: not fulfilled", "authorization"},
+		{"writer: POST own tenant", http.MethodPost, "/tenants/a", writer, http.StatusNoContent, "", ""},
+		{"missing claim", http.MethodGet, "/tenants/a", noTenant, http.StatusForbidden, "access control error: authorization: rule 11_couper.hcl:6,9-67: ", "authorization"},
+		{"missing token", http.MethodGet, "/tenants/a", "", http.StatusUnauthorized, "access control error: token: token required", "jwt_token_missing"},
+		{"error_handler", http.MethodGet, "/handled/b", writer, http.StatusTeapot, "access control error: authorization: rule 11_couper.hcl:18,15-73: not fulfilled", "authorization"},
+		{"error_handler: granted", http.MethodGet, "/handled/a", writer, http.StatusNoContent, "", ""},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+			hook.Reset()
+
+			req, err := http.NewRequest(tc.method, "http://back.end:8080"+tc.path, nil)
+			helper.Must(err)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != tc.status {
+				subT.Errorf("expected Status %d, got: %d", tc.status, res.StatusCode)
+				return
+			}
+
+			message := getAccessControlMessages(hook)
+			if tc.wantErrLog == "" {
+				if message != "" {
+					subT.Errorf("Expected error log: %q, actual: %#v", tc.wantErrLog, message)
+				}
+			} else {
+				if !strings.HasPrefix(message, tc.wantErrLog) {
+					subT.Errorf("Expected error log message: %q, actual: %#v", tc.wantErrLog, message)
+				}
+				errorType := getAccessLogErrorType(hook)
+				if errorType != tc.wantErrType {
+					subT.Errorf("Expected error type: %q, actual: %q", tc.wantErrType, errorType)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessControl_Signature(t *testing.T) {
+	client := newClient()
+
+	shutdown, hook := newCouper("testdata/integration/config/12_couper.hcl", test.New(t))
+	defer shutdown()
+
+	payload := `{"action":"opened"}`
+	sign := func(s string) string {
+		mac := hmac.New(sha256.New, []byte("whs3cr3t"))
+		mac.Write([]byte(s))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	now := time.Now().Unix()
+	old := now - 120
+
+	type testCase struct {
+		name        string
+		path        string
+		header      http.Header
+		status      int
+		wantErrLog  string
+		wantErrType string
+	}
+
+	for _, tc := range []testCase{
+		{"github: valid", "/github", http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign(payload)}}, http.StatusOK, "", ""},
+		{"github: invalid", "/github", http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign("{}")}}, http.StatusForbidden, "access control error: github: signature mismatch", "signature_invalid"},
+		{"github: missing", "/github", nil, http.StatusForbidden, `access control error: github: missing signature header "X-Hub-Signature-256"`, "signature"},
+		{"limited: body size exceeded", "/limited", http.Header{"X-Hub-Signature-256": []string{"sha256=" + sign(payload)}}, http.StatusRequestEntityTooLarge, "client request error: limited: body size exceeded: 10B", ""},
+		{"stripe: valid", "/stripe", http.Header{"Stripe-Signature": []string{fmt.Sprintf("t=%d,v1=%s", now, sign(fmt.Sprintf("%d.%s", now, payload)))}}, http.StatusNoContent, "", ""},
+		{"stripe: replay", "/stripe", http.Header{"Stripe-Signature": []string{fmt.Sprintf("t=%d,v1=%s", old, sign(fmt.Sprintf("%d.%s", old, payload)))}}, http.StatusTeapot, "access control error: stripe: timestamp", "signature_invalid"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+			hook.Reset()
+
+			req, err := http.NewRequest(http.MethodPost, "http://back.end:8080"+tc.path, strings.NewReader(payload))
+			helper.Must(err)
+			req.Header.Set("Content-Type", "application/json")
+			for k, v := range tc.header {
+				req.Header[k] = v
+			}
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != tc.status {
+				subT.Errorf("expected Status %d, got: %d", tc.status, res.StatusCode)
+				return
+			}
+
+			if tc.status == http.StatusOK && res.Header.Get("X-Action") != "opened" {
+				subT.Errorf("expected request body to be readable, got x-action: %q", res.Header.Get("X-Action"))
+			}
+
+			message := getAccessControlMessages(hook)
+			if tc.wantErrLog == "" {
+				if message != "" {
+					subT.Errorf("Expected error log: %q, actual: %#v", tc.wantErrLog, message)
+				}
+			} else {
+				if !strings.HasPrefix(message, tc.wantErrLog) {
+					subT.Errorf("Expected error log message: %q, actual: %#v", tc.wantErrLog, message)
+				}
+				errorType := getAccessLogErrorType(hook)
+				if errorType != tc.wantErrType {
+					subT.Errorf("Expected error type: %q, actual: %q", tc.wantErrType, errorType)
+				}
+			}
+		})
+	}
+}
+
 func getAccessLogUrl(hook *logrustest.Hook) string {
 	for _, entry := range hook.AllEntries() {
 		if entry.Data["type"] == "couper_access" && entry.Data["url"] != "" {
@@ -3327,6 +3689,51 @@ func TestFunctions(t *testing.T) {
 	}
 }
 
+func TestFunctions_Store(t *testing.T) {
+	client := newClient()
+
+	shutdown, _ := newCouper("testdata/integration/functions/04_couper.hcl", test.New(t))
+	defer shutdown()
+
+	type testCase struct {
+		name   string
+		path   string
+		header map[string]string
+		status int
+	}
+
+	for _, tc := range []testCase{
+		{"first count a", "/count?name=a", map[string]string{"X-Count": "1"}, http.StatusOK},
+		{"second count a", "/count?name=a", map[string]string{"X-Count": "2"}, http.StatusOK},
+		{"first count b", "/count?name=b", map[string]string{"X-Count": "1"}, http.StatusOK},
+		{"max entries", "/count?name=c", nil, http.StatusInternalServerError},
+		{"set code", "/code", map[string]string{"X-Code": "abc"}, http.StatusOK},
+		{"redeem code", "/redeem", map[string]string{"X-Code": `{"uses":[1,2],"value":"abc"}`, "X-Removed": "null"}, http.StatusOK},
+		{"redeemed code", "/redeem", map[string]string{"X-Code": "null"}, http.StatusOK},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			helper := test.New(subT)
+
+			req, err := http.NewRequest(http.MethodGet, "http://example.com:8080"+tc.path, nil)
+			helper.Must(err)
+
+			res, err := client.Do(req)
+			helper.Must(err)
+
+			if res.StatusCode != tc.status {
+				subT.Errorf("%q: expected Status %d, got: %d", tc.name, tc.status, res.StatusCode)
+				return
+			}
+
+			for k, v := range tc.header {
+				if v1 := res.Header.Get(k); v1 != v {
+					subT.Errorf("%q: unexpected %s response header %#v, got: %#v", tc.name, k, v, v1)
+				}
+			}
+		})
+	}
+}
+
 func TestEndpoint_Response(t *testing.T) {
 	client := newClient()
 	var redirSeen bool
@@ -1,11 +1,18 @@
 package server_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -190,6 +197,217 @@ func TestEndpoints_OAuth2_Options(t *testing.T) {
 	}
 }
 
+func TestEndpoints_OAuth2_ClientAuthentication(t *testing.T) {
+	helper := test.New(t)
+
+	certFile, err := os.ReadFile("testdata/integration/files/certificate.pem")
+	helper.Must(err)
+	pemBlock, _ := pem.Decode(certFile)
+	cert, err := x509.ParseCertificate(pemBlock.Bytes)
+	helper.Must(err)
+
+	type testCase struct {
+		name       string
+		configFile string
+		check      func(*testing.T, *http.Request, string)
+	}
+
+	for _, tc := range []testCase{
+		{"private_key_jwt", "11_couper.hcl", func(subT *testing.T, req *http.Request, tokenURL string) {
+			if req.Header.Get("Authorization") != "" {
+				subT.Error("expected no Authorization header")
+			}
+			if clientID := req.PostForm.Get("client_id"); clientID != "my-client" {
+				subT.Errorf("expected client_id %q, got: %q", "my-client", clientID)
+			}
+			if cat := req.PostForm.Get("client_assertion_type"); cat != "urn:ietf:params:oauth:client-assertion-type:jwt-bearer" {
+				subT.Errorf("unexpected client_assertion_type: %q", cat)
+			}
+
+			claims := jwt.MapClaims{}
+			token, perr := jwt.ParseWithClaims(req.PostForm.Get("client_assertion"), claims, func(token *jwt.Token) (interface{}, error) {
+				return cert.PublicKey, nil
+			}, jwt.WithAudience(tokenURL))
+			if perr != nil {
+				subT.Fatal(perr)
+			}
+			if kid := token.Header["kid"]; kid != "my-key" {
+				subT.Errorf("expected kid %q, got: %v", "my-key", kid)
+			}
+			for _, claim := range []string{"iss", "sub"} {
+				if claims[claim] != "my-client" {
+					subT.Errorf("expected %s claim %q, got: %v", claim, "my-client", claims[claim])
+				}
+			}
+			if claims["jti"] == nil || claims["exp"] == nil {
+				subT.Error("expected jti and exp claims")
+			}
+		}},
+		{"tls_client_auth", "12_couper.hcl", func(subT *testing.T, req *http.Request, _ string) {
+			if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+				subT.Fatal("expected a client certificate")
+			}
+			if !req.TLS.PeerCertificates[0].Equal(cert) {
+				subT.Error("unexpected client certificate")
+			}
+			if req.Header.Get("Authorization") != "" || req.PostForm.Get("client_secret") != "" {
+				subT.Error("expected no client secret")
+			}
+			if clientID := req.PostForm.Get("client_id"); clientID != "my-client" {
+				subT.Errorf("expected client_id %q, got: %q", "my-client", clientID)
+			}
+		}},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			h := test.New(subT)
+
+			asOrigin := httptest.NewUnstartedServer(nil)
+			asOrigin.Config.Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/token" {
+					rw.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				h.Must(req.ParseForm())
+				tc.check(subT, req, asOrigin.URL+"/token")
+
+				rw.Header().Set("Content-Type", "application/json")
+				_, werr := rw.Write([]byte(`{"access_token": "abcdef0123456789", "token_type": "bearer", "expires_in": 100}`))
+				h.Must(werr)
+			})
+			if tc.configFile == "12_couper.hcl" {
+				asOrigin.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+				asOrigin.StartTLS()
+			} else {
+				asOrigin.Start()
+			}
+			defer asOrigin.Close()
+
+			rsOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.Header.Get("Authorization") == "Bearer abcdef0123456789" {
+					rw.WriteHeader(http.StatusNoContent)
+					return
+				}
+				rw.WriteHeader(http.StatusUnauthorized)
+			}))
+			defer rsOrigin.Close()
+
+			confPath := fmt.Sprintf("testdata/oauth2/%s", tc.configFile)
+			shutdown, _ := newCouperWithTemplate(confPath, h, map[string]interface{}{"asOrigin": asOrigin.URL, "rsOrigin": rsOrigin.URL})
+			defer shutdown()
+
+			req, err := http.NewRequest(http.MethodGet, "http://anyserver:8080/", nil)
+			h.Must(err)
+
+			res, err := newClient().Do(req)
+			h.Must(err)
+
+			if res.StatusCode != http.StatusNoContent {
+				subT.Errorf("expected status NoContent, got: %d", res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestEndpoints_OAuth2_OnBehalfOf(t *testing.T) {
+	helper := test.New(t)
+
+	var mu sync.Mutex
+	tokenRequests := map[string]int{}
+
+	asOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/token" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		helper.Must(req.ParseForm())
+
+		var subject string
+		switch grantType := req.PostForm.Get("grant_type"); grantType {
+		case "urn:ietf:params:oauth:grant-type:token-exchange":
+			subject = req.PostForm.Get("subject_token")
+			if stt := req.PostForm.Get("subject_token_type"); stt != "urn:ietf:params:oauth:token-type:access_token" {
+				t.Errorf("unexpected subject_token_type: %q", stt)
+			}
+			if aud := req.PostForm.Get("audience"); aud != "resource-server" {
+				t.Errorf("expected audience %q, got: %q", "resource-server", aud)
+			}
+			if secret := req.PostForm.Get("client_secret"); secret != "my-secret" {
+				t.Errorf("expected client_secret in request body, got: %q", secret)
+			}
+		case "urn:ietf:params:oauth:grant-type:jwt-bearer":
+			subject = req.PostForm.Get("assertion")
+			if scope := req.PostForm.Get("scope"); scope != "read" {
+				t.Errorf("expected scope %q, got: %q", "read", scope)
+			}
+		default:
+			t.Errorf("unexpected grant_type: %q", grantType)
+		}
+
+		mu.Lock()
+		tokenRequests[subject]++
+		mu.Unlock()
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, werr := rw.Write([]byte(`{"access_token": "token-for-` + subject + `", "token_type": "bearer", "expires_in": 100}`))
+		helper.Must(werr)
+	}))
+	defer asOrigin.Close()
+
+	rsOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Authorization", req.Header.Get("Authorization"))
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer rsOrigin.Close()
+
+	shutdown, _ := newCouperWithTemplate("testdata/oauth2/13_couper.hcl", helper, map[string]interface{}{"asOrigin": asOrigin.URL, "rsOrigin": rsOrigin.URL})
+	defer shutdown()
+
+	type testCase struct {
+		path      string
+		header    http.Header
+		expStatus int
+		expAuth   string
+	}
+
+	for _, tc := range []testCase{
+		{"/token-exchange", http.Header{"Authorization": []string{"Bearer alice"}}, http.StatusNoContent, "Bearer token-for-alice"},
+		{"/token-exchange", http.Header{"Authorization": []string{"Bearer bob"}}, http.StatusNoContent, "Bearer token-for-bob"},
+		{"/token-exchange", http.Header{"Authorization": []string{"bearer alice"}}, http.StatusNoContent, "Bearer token-for-alice"},
+		{"/token-exchange", http.Header{}, http.StatusBadGateway, ""},
+		{"/jwt-bearer", http.Header{"X-Assertion": []string{"eve.jwt"}}, http.StatusNoContent, "Bearer token-for-eve.jwt"},
+		{"/jwt-bearer", http.Header{"X-Assertion": []string{"eve.jwt"}}, http.StatusNoContent, "Bearer token-for-eve.jwt"},
+	} {
+		req, err := http.NewRequest(http.MethodGet, "http://anyserver:8080"+tc.path, nil)
+		helper.Must(err)
+		req.Header = tc.header
+
+		res, err := newClient().Do(req)
+		helper.Must(err)
+
+		if res.StatusCode != tc.expStatus {
+			t.Errorf("%s: expected status %d, got: %d", tc.path, tc.expStatus, res.StatusCode)
+			continue
+		}
+
+		if auth := res.Header.Get("X-Authorization"); auth != tc.expAuth {
+			t.Errorf("%s: expected Authorization %q, got: %q", tc.path, tc.expAuth, auth)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for subject, count := range tokenRequests {
+		if count != 1 {
+			t.Errorf("expected one token request for subject %q, got: %d", subject, count)
+		}
+	}
+	if len(tokenRequests) != 3 {
+		t.Errorf("expected token requests for three subjects, got: %v", tokenRequests)
+	}
+}
+
 func TestOAuth2_AccessControl(t *testing.T) {
 	client := newClient()
 	helper := test.New(t)
@@ -386,6 +604,483 @@ func TestOAuth2_AccessControl(t *testing.T) {
 	}
 }
 
+func TestOAuth2_Session(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	var refreshes int32
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/token" {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		helper.Must(req.ParseForm())
+		rw.Header().Set("Content-Type", "application/json")
+
+		var body string
+		switch req.PostForm.Get("grant_type") {
+		case "authorization_code":
+			expiresIn := "100"
+			if req.PostForm.Get("code") == "short" {
+				expiresIn = "1"
+			}
+			body = `{"access_token": "at-` + req.PostForm.Get("code") + `", "refresh_token": "rt", "token_type": "bearer", "expires_in": ` + expiresIn + `}`
+		case "refresh_token":
+			if rt := req.PostForm.Get("refresh_token"); rt != "rt" {
+				t.Errorf("expected refresh_token %q, got: %q", "rt", rt)
+			}
+			atomic.AddInt32(&refreshes, 1)
+			body = `{"access_token": "at-refreshed", "token_type": "bearer", "expires_in": 100}`
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, werr := rw.Write([]byte(body))
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	shutdown, _ := newCouperWithTemplate("testdata/oauth2/14_couper.hcl", helper, map[string]interface{}{"asOrigin": oauthOrigin.URL})
+	defer shutdown()
+
+	doMethod := func(method, path string, cookies ...*http.Cookie) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest(method, "http://back.end:8080"+path, nil)
+		helper.Must(err)
+		req.AddCookie(&http.Cookie{Name: "pkcecv", Value: "qerbnr"})
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+
+		res, err := client.Do(req)
+		helper.Must(err)
+
+		var data map[string]interface{}
+		b, err := io.ReadAll(res.Body)
+		helper.Must(err)
+		_ = json.Unmarshal(b, &data)
+		return res, data
+	}
+
+	do := func(path string, cookies ...*http.Cookie) (*http.Response, map[string]interface{}) {
+		return doMethod(http.MethodGet, path, cookies...)
+	}
+
+	sessionCookie := func(res *http.Response, name string) *http.Cookie {
+		for _, c := range res.Cookies() {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+
+	for _, prefix := range []string{"", "/mem"} {
+		cookieName := "couper_session_ac"
+		if prefix != "" {
+			cookieName = "couper_session_mem"
+		}
+
+		t.Run("session"+prefix, func(subT *testing.T) {
+			res, _ := do(prefix + "/private")
+			if res.StatusCode != http.StatusForbidden {
+				subT.Errorf("expected status %d without session, got: %d", http.StatusForbidden, res.StatusCode)
+			}
+
+			res, data := do(prefix + "/cb?code=qeuboub")
+			if res.StatusCode != http.StatusOK {
+				subT.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+			}
+			cookie := sessionCookie(res, cookieName)
+			if cookie == nil || cookie.Value == "" || !cookie.HttpOnly || !cookie.Secure {
+				subT.Fatalf("expected a session cookie, got: %v", res.Header.Values("Set-Cookie"))
+			}
+			if data["access_token"] != "at-qeuboub" {
+				subT.Errorf("unexpected access_token: %v", data["access_token"])
+			}
+
+			res, data = do(prefix+"/private", cookie)
+			if res.StatusCode != http.StatusOK {
+				subT.Errorf("expected status %d with session, got: %d", http.StatusOK, res.StatusCode)
+			}
+			if data["access_token"] != "at-qeuboub" || data["refresh_token"] != "rt" || data["expires_at"] == nil {
+				subT.Errorf("unexpected session data: %v", data)
+			}
+
+			res, _ = do(prefix+"/logout", cookie)
+			if res.StatusCode != http.StatusForbidden {
+				subT.Errorf("expected status %d for a GET logout request, got: %d", http.StatusForbidden, res.StatusCode)
+			}
+			if c := sessionCookie(res, cookieName); c != nil {
+				subT.Errorf("expected the session cookie to be kept, got: %v", res.Header.Values("Set-Cookie"))
+			}
+
+			res, _ = doMethod(http.MethodPost, prefix+"/logout", cookie)
+			if res.StatusCode != http.StatusSeeOther {
+				subT.Errorf("expected status %d, got: %d", http.StatusSeeOther, res.StatusCode)
+			}
+			if c := sessionCookie(res, cookieName); c == nil || c.MaxAge >= 0 {
+				subT.Errorf("expected the session cookie to be removed, got: %v", res.Header.Values("Set-Cookie"))
+			}
+
+			if prefix == "" {
+				expLocation := "https://authorization.server/logout?client_id=foo&post_logout_redirect_uri=http%3A%2F%2Fback.end%3A8080%2F"
+				if location := res.Header.Get("Location"); location != expLocation {
+					subT.Errorf("expected Location %q, got: %q", expLocation, location)
+				}
+
+				// a cookie session is valid until it expires, however the client has removed it
+				return
+			}
+
+			res, _ = do(prefix+"/private", cookie)
+			if res.StatusCode != http.StatusForbidden {
+				subT.Errorf("expected status %d after logout, got: %d", http.StatusForbidden, res.StatusCode)
+			}
+		})
+	}
+
+	t.Run("cross-site logout", func(subT *testing.T) {
+		res, _ := do("/csrf/cb?code=csrf")
+		cookie := sessionCookie(res, "couper_session_csrf")
+		if cookie == nil {
+			subT.Fatalf("expected a session cookie, got: %v", res.Header.Values("Set-Cookie"))
+		}
+
+		req, err := http.NewRequest(http.MethodPost, "http://back.end:8080/csrf/logout", nil)
+		helper.Must(err)
+		req.Header.Set("Origin", "https://evil.example.com")
+		req.AddCookie(cookie)
+
+		res, err = client.Do(req)
+		helper.Must(err)
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusForbidden {
+			subT.Errorf("expected status %d for a cross-site logout request, got: %d", http.StatusForbidden, res.StatusCode)
+		}
+		if c := sessionCookie(res, "couper_session_csrf"); c != nil {
+			subT.Errorf("expected the session cookie to be kept, got: %v", res.Header.Values("Set-Cookie"))
+		}
+
+		res, data := do("/csrf/private", cookie)
+		if res.StatusCode != http.StatusOK || data["access_token"] != "at-csrf" {
+			subT.Errorf("expected the session to be kept, got: %d %v", res.StatusCode, data)
+		}
+	})
+
+	t.Run("tampered cookie", func(subT *testing.T) {
+		res, _ := do("/private", &http.Cookie{Name: "couper_session_ac", Value: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"})
+		if res.StatusCode != http.StatusForbidden {
+			subT.Errorf("expected status %d, got: %d", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("refresh", func(subT *testing.T) {
+		res, _ := do("/cb?code=short")
+		cookie := sessionCookie(res, "couper_session_ac")
+		if cookie == nil {
+			subT.Fatal("expected a session cookie")
+		}
+
+		res, data := do("/private", cookie)
+		if res.StatusCode != http.StatusOK {
+			subT.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+		}
+		if data["access_token"] != "at-refreshed" || data["refresh_token"] != "rt" {
+			subT.Errorf("expected refreshed session data, got: %v", data)
+		}
+		if atomic.LoadInt32(&refreshes) != 1 {
+			subT.Errorf("expected one refresh request, got: %d", refreshes)
+		}
+
+		refreshedCookie := sessionCookie(res, "couper_session_ac")
+		if refreshedCookie == nil {
+			subT.Fatal("expected an updated session cookie")
+		}
+
+		res, data = do("/private", refreshedCookie)
+		if res.StatusCode != http.StatusOK || data["access_token"] != "at-refreshed" {
+			subT.Errorf("expected the refreshed session, got: %d %v", res.StatusCode, data)
+		}
+		if atomic.LoadInt32(&refreshes) != 1 {
+			subT.Errorf("expected no further refresh request, got: %d", refreshes)
+		}
+	})
+}
+
+func TestOIDC_BackChannelLogout(t *testing.T) {
+	helper := test.New(t)
+	client := newClient()
+
+	certFile, err := os.ReadFile("./testdata/integration/files/certificate.pem")
+	helper.Must(err)
+	pemBlock, _ := pem.Decode(certFile)
+	keyFile, err := os.ReadFile("./testdata/integration/files/pkcs8.key")
+	helper.Must(err)
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(keyFile)
+	helper.Must(err)
+
+	st := "qeirtbnpetrbi"
+	nonce := oauth2.Base64urlSha256(st)
+	issuer := "https://authorization.server"
+
+	var userinfoRequests int32
+	var refreshedSub atomic.Value
+	refreshedSub.Store("myself")
+	oauthOrigin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+
+		var body []byte
+		switch req.URL.Path {
+		case "/.well-known/openid-configuration":
+			body = []byte(`{
+			"issuer": "` + issuer + `",
+			"authorization_endpoint": "https://authorization.server/oauth2/authorize",
+			"token_endpoint": "http://` + req.Host + `/token",
+			"userinfo_endpoint": "http://` + req.Host + `/userinfo",
+			"jwks_uri": "http://` + req.Host + `/jwks"
+			}`)
+		case "/jwks":
+			body = []byte(`{"keys": [{"kty": "RSA", "kid": "rs256", "alg": "RS256", "use": "sig", "x5c": ["` +
+				base64.StdEncoding.EncodeToString(pemBlock.Bytes) + `"]}]}`)
+		case "/token":
+			helper.Must(req.ParseForm())
+			if req.PostForm.Get("grant_type") == "refresh_token" {
+				// refreshed ID tokens have no nonce claim
+				idToken, _ := lib.CreateJWT("HS256", []byte("$e(rEt"), jwt.MapClaims{
+					"aud":  "foo",
+					"exp":  4000000000,
+					"iat":  2000,
+					"iss":  issuer,
+					"name": "from refreshed ID token",
+					"sub":  refreshedSub.Load(),
+				})
+				body = []byte(`{"access_token": "refreshed", "token_type": "bearer", "expires_in": 1, "id_token": "` + idToken + `"}`)
+				break
+			}
+
+			expiresIn := "100"
+			if req.PostForm.Get("code") == "short" {
+				expiresIn = `1, "refresh_token": "rt"`
+			}
+			idToken, _ := lib.CreateJWT("HS256", []byte("$e(rEt"), jwt.MapClaims{
+				"aud":   "foo",
+				"azp":   "foo",
+				"exp":   4000000000,
+				"iat":   1000,
+				"iss":   issuer,
+				"name":  "from ID token",
+				"nonce": nonce,
+				"sid":   "s1",
+				"sub":   "myself",
+			})
+			body = []byte(`{"access_token": "abcdef0123456789", "token_type": "bearer", "expires_in": ` + expiresIn + `, "id_token": "` + idToken + `"}`)
+		case "/userinfo":
+			atomic.AddInt32(&userinfoRequests, 1)
+			body = []byte(`{"sub": "myself", "name": "from userinfo", "email": "me@example.com"}`)
+		default:
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, werr := rw.Write(body)
+		helper.Must(werr)
+	}))
+	defer oauthOrigin.Close()
+
+	shutdown, hook := newCouperWithTemplate("testdata/oauth2/15_couper.hcl", helper, map[string]interface{}{"asOrigin": oauthOrigin.URL})
+	defer shutdown()
+
+	do := func(req *http.Request) (*http.Response, map[string]interface{}) {
+		hook.Reset()
+		res, err := client.Do(req)
+		helper.Must(err)
+
+		var data map[string]interface{}
+		b, err := io.ReadAll(res.Body)
+		helper.Must(err)
+		_ = json.Unmarshal(b, &data)
+		return res, data
+	}
+
+	get := func(path string, cookies ...*http.Cookie) (*http.Response, map[string]interface{}) {
+		req, err := http.NewRequest(http.MethodGet, "http://back.end:8080"+path, nil)
+		helper.Must(err)
+		req.AddCookie(&http.Cookie{Name: "nnc", Value: st})
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return do(req)
+	}
+
+	logout := func(claims jwt.MapClaims, key interface{}) (*http.Response, map[string]interface{}) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "rs256"
+		logoutToken, err := token.SignedString(key)
+		helper.Must(err)
+
+		req, err := http.NewRequest(http.MethodPost, "http://back.end:8080/backchannel-logout",
+			strings.NewReader("logout_token="+logoutToken))
+		helper.Must(err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return do(req)
+	}
+
+	logoutClaims := func(jti string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"aud":    "foo",
+			"events": map[string]interface{}{"http://schemas.openid.net/event/backchannel-logout": map[string]interface{}{}},
+			"iat":    time.Now().Unix(),
+			"iss":    issuer,
+			"jti":    jti,
+			"sid":    "s1",
+		}
+	}
+
+	res, data := get("/cb?code=qeuboub")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+	}
+
+	claims, ok := data["claims"].(map[string]interface{})
+	if !ok || claims["email"] != "me@example.com" || claims["name"] != "from ID token" {
+		t.Errorf("expected merged claims, got: %v", data["claims"])
+	}
+
+	var cookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == "couper_session_ac" {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		t.Fatalf("expected a session cookie, got: %v", res.Header.Values("Set-Cookie"))
+	}
+
+	res, _ = get("/private", cookie)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d with session, got: %d", http.StatusOK, res.StatusCode)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	helper.Must(err)
+
+	missingEvents := logoutClaims("j2")
+	delete(missingEvents, "events")
+	withNonce := logoutClaims("j3")
+	withNonce["nonce"] = nonce
+	wrongAudience := logoutClaims("j4")
+	wrongAudience["aud"] = "bar"
+
+	for _, tc := range []struct {
+		name       string
+		claims     jwt.MapClaims
+		key        interface{}
+		wantErrLog string
+	}{
+		{"wrong signature", logoutClaims("j1"), otherKey, "access control error: ac: back-channel logout error: token signature is invalid"},
+		{"missing events", missingEvents, privateKey, "access control error: ac: back-channel logout error: missing events claim"},
+		{"nonce", withNonce, privateKey, "access control error: ac: back-channel logout error: nonce claim not allowed"},
+		{"wrong audience", wrongAudience, privateKey, "access control error: ac: back-channel logout error: token audience is invalid"},
+	} {
+		t.Run(tc.name, func(subT *testing.T) {
+			res, _ := logout(tc.claims, tc.key)
+			if res.StatusCode != http.StatusForbidden {
+				subT.Errorf("expected status %d, got: %d", http.StatusForbidden, res.StatusCode)
+			}
+			if message := getAccessControlMessages(hook); !strings.HasPrefix(message, tc.wantErrLog) {
+				subT.Errorf("expected error log %q, got: %q", tc.wantErrLog, message)
+			}
+		})
+	}
+
+	res, _ = get("/private", cookie)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected a valid session after invalid logout tokens, got: %d", res.StatusCode)
+	}
+
+	res, _ = logout(logoutClaims("j0"), privateKey)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+	}
+	if cc := res.Header.Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("expected Cache-Control %q, got: %q", "no-store", cc)
+	}
+
+	res, _ = logout(logoutClaims("j0"), privateKey)
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status %d for a replayed logout token, got: %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	res, _ = get("/private", cookie)
+	if res.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status %d after back-channel logout, got: %d", http.StatusForbidden, res.StatusCode)
+	}
+
+	t.Run("disable_userinfo", func(subT *testing.T) {
+		requests := atomic.LoadInt32(&userinfoRequests)
+		res, data := get("/nui/cb?code=qeuboub")
+		if res.StatusCode != http.StatusOK {
+			subT.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+		}
+		if atomic.LoadInt32(&userinfoRequests) != requests {
+			subT.Error("expected no userinfo request")
+		}
+		if _, ok := data["userinfo"]; ok {
+			subT.Errorf("expected no userinfo, got: %v", data["userinfo"])
+		}
+		claims, ok := data["claims"].(map[string]interface{})
+		if !ok || claims["name"] != "from ID token" || claims["email"] != nil {
+			subT.Errorf("expected ID token claims only, got: %v", data["claims"])
+		}
+	})
+
+	t.Run("refresh", func(subT *testing.T) {
+		res, _ := get("/rf/cb?code=short")
+		if res.StatusCode != http.StatusOK {
+			subT.Fatalf("expected status %d, got: %d", http.StatusOK, res.StatusCode)
+		}
+		var cookie *http.Cookie
+		for _, c := range res.Cookies() {
+			if c.Name == "couper_session_rf" {
+				cookie = c
+			}
+		}
+		if cookie == nil {
+			subT.Fatalf("expected a session cookie, got: %v", res.Header.Values("Set-Cookie"))
+		}
+
+		res, data := get("/rf/private", cookie)
+		if res.StatusCode != http.StatusOK {
+			subT.Fatalf("expected status %d with a refreshed session, got: %d", http.StatusOK, res.StatusCode)
+		}
+		claims, ok := data["claims"].(map[string]interface{})
+		if data["access_token"] != "refreshed" || !ok || claims["name"] != "from refreshed ID token" {
+			subT.Errorf("expected refreshed claims, got: %v", data["claims"])
+		}
+
+		refreshedSub.Store("someone else")
+		res, _ = get("/rf/private", cookie)
+		if res.StatusCode != http.StatusForbidden {
+			subT.Errorf("expected status %d for a refreshed ID token with another subject, got: %d", http.StatusForbidden, res.StatusCode)
+		}
+	})
+
+	t.Run("nonce replay", func(subT *testing.T) {
+		res, _ := get("/nui/cb?code=qeuboub")
+		if res.StatusCode != http.StatusForbidden {
+			subT.Errorf("expected status %d, got: %d", http.StatusForbidden, res.StatusCode)
+		}
+		wantErrLog := "access control error: nui: token response validation error: nonce"
+		if message := getAccessControlMessages(hook); !strings.HasPrefix(message, wantErrLog) {
+			subT.Errorf("expected error log %q, got: %q", wantErrLog, message)
+		}
+	})
+}
+
 func TestOAuth2_Locking(t *testing.T) {
 	helper := test.New(t)
 	client := test.NewHTTPClient()
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -367,6 +369,69 @@ server "zipzip" {
 	}
 }
 
+func TestHTTPServer_AWSSigV4(t *testing.T) {
+	configFile := `
+server "s3" {
+	endpoint "/**" {
+		proxy {
+			backend {
+				origin = "%s"
+
+				aws_sigv4 {
+					access_key_id = "AKIDEXAMPLE"
+					secret_access_key = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+					region = "eu-central-1"
+					service = "s3"
+					session_token = env.COUPER_TEST_SESSION_TOKEN
+				}
+			}
+		}
+	}
+}
+`
+	helper := test.New(t)
+
+	helper.Must(os.Setenv("COUPER_TEST_SESSION_TOKEN", "s3ss10n"))
+	defer os.Unsetenv("COUPER_TEST_SESSION_TOKEN")
+
+	payload := "couper"
+	origin := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		helper.Must(err)
+
+		sum := sha256.Sum256(b)
+		if got := r.Header.Get("X-Amz-Content-Sha256"); got != hex.EncodeToString(sum[:]) {
+			t.Errorf("expected payload hash, got: %q", got)
+		}
+
+		if got := r.Header.Get("X-Amz-Security-Token"); got != "s3ss10n" {
+			t.Errorf("expected X-Amz-Security-Token, got: %q", got)
+		}
+
+		credential := "Credential=AKIDEXAMPLE/" + time.Now().UTC().Format("20060102") + "/eu-central-1/s3/aws4_request, "
+		if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 "+credential) ||
+			!strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=") {
+			t.Errorf("unexpected Authorization header: %q", auth)
+		}
+
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer origin.Close()
+
+	shutdown, _ := newCouperWithBytes([]byte(fmt.Sprintf(configFile, origin.URL)), helper)
+	defer shutdown()
+
+	req, err := http.NewRequest(http.MethodPut, "http://localhost:8080/bucket/object.txt", strings.NewReader(payload))
+	helper.Must(err)
+
+	res, err := newClient().Do(req)
+	helper.Must(err)
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Errorf("expected status %d, got: %d", http.StatusNoContent, res.StatusCode)
+	}
+}
+
 func TestHTTPServer_Errors(t *testing.T) {
 	helper := test.New(t)
 	client := newClient()
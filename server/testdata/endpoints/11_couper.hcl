@@ -0,0 +1,59 @@
+server "csrf" {
+  csrf {
+    allowed_origins = ["https://app.example.com"]
+  }
+
+  spa {
+    bootstrap_file = "11_app.html"
+    paths = ["/app/**"]
+    bootstrap_data = {
+      csrf_token = request.csrf_token
+    }
+  }
+
+  endpoint "/submit" {
+    response {
+      json_body = {
+        token = request.csrf_token
+      }
+    }
+  }
+
+  endpoint "/public" {
+    csrf {
+      disable = true
+    }
+    response {
+      status = 204
+    }
+  }
+
+  endpoint "/handled" {
+    csrf {
+      error_handler "csrf" {
+        response {
+          status = 418
+        }
+      }
+    }
+    response {
+      status = 204
+    }
+  }
+
+  api {
+    base_path = "/sync"
+
+    csrf {
+      mode = "synchronizer_token"
+      session_cookie = "session"
+      header_name = "X-Sync-Token"
+    }
+
+    endpoint "/**" {
+      response {
+        body = request.csrf_token
+      }
+    }
+  }
+}
@@ -0,0 +1,45 @@
+server "ldap" {
+  endpoint "/search" {
+    access_control = ["ldap_search"]
+    beta_scope = "admin"
+
+    response {
+      json_body = {
+        user = request.context.ldap_search.user
+        dn = request.context.ldap_search.dn
+        groups = request.context.ldap_search.groups
+      }
+    }
+  }
+
+  endpoint "/template" {
+    access_control = ["ldap_template"]
+
+    response {
+      json_body = request.context.ldap_template
+    }
+  }
+}
+
+definitions {
+  basic_auth "ldap_search" {
+    ldap {
+      url = "{{ .ldapURL }}"
+      bind_dn = "cn=service,dc=example,dc=com"
+      bind_password = "secret"
+      search_base_dn = "ou=people,dc=example,dc=com"
+      required_groups = ["cn=users,ou=groups,dc=example,dc=com"]
+      beta_role_map = {
+        "cn=admins,ou=groups,dc=example,dc=com" = ["admin"]
+      }
+    }
+  }
+
+  basic_auth "ldap_template" {
+    ldap {
+      url = "{{ .ldapURL }}"
+      bind_dn_template = "uid={username},ou=people,dc=example,dc=com"
+      group_attribute = "memberOf"
+    }
+  }
+}
@@ -0,0 +1,69 @@
+server "templates" {
+  api {
+    base_path = "/api"
+
+    endpoint "/users/{id}" {
+      template = "default_proxy"
+      template_params = {
+        id = "user-${request.path_params.id}"
+        team = "users"
+      }
+    }
+
+    endpoint "/orders" {
+      template = "default_proxy"
+      template_params = {
+        id = "all"
+        team = "orders"
+      }
+
+      set_response_headers = {
+        x-team = "override"
+      }
+    }
+  }
+
+  endpoint "/status" {
+    template = "status"
+    template_params = {
+      message = "ok"
+    }
+  }
+}
+
+definitions {
+  backend "test" {
+    origin = env.COUPER_TEST_BACKEND_ADDR
+  }
+
+  endpoint_template "default_proxy" {
+    template = "headers"
+    template_params = {
+      team = template_params.team
+    }
+
+    proxy {
+      backend = "test"
+      path = "/anything"
+      set_query_params = {
+        id = template_params.id
+      }
+    }
+  }
+
+  endpoint_template "headers" {
+    set_response_headers = {
+      x-team = template_params.team
+      x-template = "headers"
+    }
+  }
+
+  endpoint_template "status" {
+    response {
+      status = 201
+      json_body = {
+        message = template_params.message
+      }
+    }
+  }
+}
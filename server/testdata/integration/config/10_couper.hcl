@@ -0,0 +1,75 @@
+server "groups" {
+  endpoint "/any" {
+    access_control = ["token_or_user"]
+    response {
+      headers = {
+        x-matched = request.context.token_or_user.matched
+      }
+    }
+  }
+
+  endpoint "/all" {
+    access_control = ["token_and_user"]
+    response {
+      headers = {
+        x-sub = request.context.token.sub
+      }
+    }
+  }
+
+  endpoint "/nested" {
+    access_control = ["nested"]
+    response {
+      headers = {
+        x-matched = request.context.nested.matched
+      }
+    }
+  }
+
+  endpoint "/handled" {
+    access_control = ["handled"]
+    response {
+      status = 204
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    header = "x-token"
+    signature_algorithm = "HS256"
+    key = "y0urS3cr3t"
+  }
+
+  jwt "cookie_token" {
+    cookie = "tok"
+    signature_algorithm = "HS256"
+    key = "y0urS3cr3t"
+  }
+
+  basic_auth "user" {
+    user = "john"
+    password = "secret"
+  }
+
+  any_of "token_or_user" {
+    access_control = ["token", "user"]
+  }
+
+  all_of "token_and_user" {
+    access_control = ["token", "user"]
+  }
+
+  any_of "nested" {
+    access_control = ["cookie_token", "token_and_user"]
+  }
+
+  any_of "handled" {
+    access_control = ["cookie_token", "user"]
+    error_handler "any_of" {
+      response {
+        status = 418
+      }
+    }
+  }
+}
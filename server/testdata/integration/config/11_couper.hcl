@@ -0,0 +1,37 @@
+server "authorization" {
+  endpoint "/tenants/{tenant}" {
+    access_control = ["token"]
+    authorization {
+      rules = [
+        request.context.token.tenant == request.path_params.tenant,
+        request.method == "GET" || request.context.token.role != "reader"
+      ]
+    }
+    response {
+      status = 204
+    }
+  }
+
+  endpoint "/handled/{tenant}" {
+    access_control = ["token"]
+    authorization {
+      rules = request.context.token.tenant == request.path_params.tenant
+      error_handler "authorization" {
+        response {
+          status = 418
+        }
+      }
+    }
+    response {
+      status = 204
+    }
+  }
+}
+
+definitions {
+  jwt "token" {
+    header = "authorization"
+    signature_algorithm = "HS256"
+    key = "y0urS3cr3t"
+  }
+}
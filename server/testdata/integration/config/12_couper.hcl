@@ -0,0 +1,53 @@
+server "signature" {
+  endpoint "/github" {
+    access_control = ["github"]
+    response {
+      headers = {
+        x-action = request.json_body.action
+      }
+    }
+  }
+
+  endpoint "/limited" {
+    access_control = ["limited"]
+    response {
+      status = 204
+    }
+  }
+
+  endpoint "/stripe" {
+    access_control = ["stripe"]
+    response {
+      status = 204
+    }
+  }
+}
+
+definitions {
+  signature "github" {
+    header = "X-Hub-Signature-256"
+    prefix = "sha256="
+    key = "whs3cr3t"
+  }
+
+  signature "limited" {
+    header = "X-Hub-Signature-256"
+    prefix = "sha256="
+    key = "whs3cr3t"
+    request_body_limit = "10B"
+  }
+
+  signature "stripe" {
+    header = "Stripe-Signature"
+    header_param = "v1"
+    key = "whs3cr3t"
+    canonical_string = "${signature_params.t}.${request.body}"
+    timestamp = signature_params.t
+    timestamp_tolerance = "1m"
+    error_handler "signature_invalid" {
+      response {
+        status = 418
+      }
+    }
+  }
+}
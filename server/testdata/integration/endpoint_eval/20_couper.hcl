@@ -0,0 +1,43 @@
+server "connection" {
+  endpoint "/connection" {
+    response {
+      headers = {
+        x-remote-ip = request.remote_ip
+        x-remote-port = request.remote_port != null ? "set" : "null"
+        x-protocol-version = request.protocol_version
+        x-received-at = request.received_at > 0 ? "set" : "null"
+        x-tls = request.tls == null ? "null" : "set"
+      }
+    }
+  }
+
+  endpoint "/timings" {
+    proxy {
+      backend {
+        origin = env.COUPER_TEST_BACKEND_ADDR
+        path = "/anything"
+      }
+    }
+
+    set_response_headers = {
+      x-timings = backend_responses.default.timings.dns >= 0 && backend_responses.default.timings.connect >= 0 && backend_responses.default.timings.tls >= 0 && backend_responses.default.timings.ttfb >= 0 ? "ok" : "missing"
+      x-total = backend_responses.default.timings.total > 0 ? "set" : "null"
+    }
+  }
+
+  endpoint "/timings/request" {
+    request "named" {
+      url = "${env.COUPER_TEST_BACKEND_ADDR}/anything"
+    }
+
+    response {
+      headers = {
+        x-total = backend_responses.named.timings.total > 0 ? "set" : "null"
+      }
+    }
+  }
+}
+
+settings {
+  trusted_proxies = ["127.0.0.1", "10.0.0.0/8"]
+}
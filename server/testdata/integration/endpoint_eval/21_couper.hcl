@@ -0,0 +1,40 @@
+server "bodies" {
+  endpoint "/xml" {
+    response {
+      headers = {
+        content-type = "application/xml"
+      }
+      body = xml_encode({
+        order = {
+          "@id" = request.query.id[0]
+          item = ["a", "b"]
+        }
+      })
+    }
+  }
+
+  endpoint "/xml/backend" {
+    request {
+      url = "http://localhost:8080/xml?id=42"
+    }
+
+    response {
+      json_body = {
+        id = backend_responses.default.xml_body.order["@id"]
+        items = backend_responses.default.xml_body.order.item
+      }
+    }
+  }
+
+  endpoint "/xml/request" {
+    response {
+      json_body = request.xml_body
+    }
+  }
+
+  endpoint "/multipart" {
+    response {
+      json_body = request.multipart
+    }
+  }
+}
@@ -0,0 +1,37 @@
+server "store-functions" {
+  endpoint "/count" {
+    response {
+      headers = {
+        x-count = store_incr("counter", request.query.name[0], 1, "1m")
+      }
+    }
+  }
+
+  endpoint "/code" {
+    response {
+      headers = {
+        x-code = store_set("codes", "code", { value = "abc", uses = [1, 2] }).value
+      }
+    }
+  }
+
+  endpoint "/redeem" {
+    response {
+      headers = {
+        x-code = json_encode(store_get("codes", "code"))
+        x-removed = json_encode(store_set("codes", "code", null))
+      }
+    }
+  }
+}
+
+definitions {
+  store "counter" {
+    max_entries = 2
+  }
+
+  store "codes" {
+    default_ttl = "10m"
+    max_value_size = "1KiB"
+  }
+}
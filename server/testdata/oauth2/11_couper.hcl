@@ -0,0 +1,25 @@
+server "oauth2-private-key-jwt" {
+  api {
+    endpoint "/" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+          path   = "/resource"
+
+          oauth2 {
+            token_endpoint             = "{{.asOrigin}}/token"
+            client_id                  = "my-client"
+            grant_type                 = "client_credentials"
+            token_endpoint_auth_method = "private_key_jwt"
+
+            jwt_signing_profile {
+              key_file            = "./testdata/integration/files/pkcs8.key"
+              key_id              = "my-key"
+              signature_algorithm = "RS256"
+            }
+          }
+        }
+      }
+    }
+  }
+}
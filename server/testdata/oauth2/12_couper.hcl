@@ -0,0 +1,26 @@
+server "oauth2-tls-client-auth" {
+  api {
+    endpoint "/" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+          path   = "/resource"
+
+          oauth2 {
+            token_endpoint             = "{{.asOrigin}}/token"
+            client_id                  = "my-client"
+            grant_type                 = "client_credentials"
+            token_endpoint_auth_method = "tls_client_auth"
+
+            backend {
+              origin                         = "{{.asOrigin}}"
+              client_certificate_file        = "./testdata/integration/files/certificate.pem"
+              client_private_key_file        = "./testdata/integration/files/pkcs8.key"
+              disable_certificate_validation = true
+            }
+          }
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,40 @@
+server "oauth2-on-behalf-of" {
+  api {
+    endpoint "/token-exchange" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+          path   = "/resource"
+
+          oauth2 {
+            token_endpoint             = "{{.asOrigin}}/token"
+            client_id                  = "my-client"
+            client_secret              = "my-secret"
+            grant_type                 = "urn:ietf:params:oauth:grant-type:token-exchange"
+            subject_token              = request.headers.authorization
+            audience                   = "resource-server"
+            token_endpoint_auth_method = "client_secret_post"
+          }
+        }
+      }
+    }
+
+    endpoint "/jwt-bearer" {
+      proxy {
+        backend {
+          origin = "{{.rsOrigin}}"
+          path   = "/resource"
+
+          oauth2 {
+            token_endpoint = "{{.asOrigin}}/token"
+            client_id      = "my-client"
+            client_secret  = "my-secret"
+            grant_type     = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+            subject_token  = request.headers.x-assertion
+            scope          = "read"
+          }
+        }
+      }
+    }
+  }
+}
@@ -0,0 +1,128 @@
+server "client" {
+  api {
+    endpoint "/cb" {
+      access_control = ["ac"]
+      response {
+        json_body = request.context.ac
+      }
+    }
+
+    endpoint "/private" {
+      access_control = ["ac"]
+      response {
+        json_body = request.context.ac
+      }
+    }
+
+    endpoint "/logout" {
+      access_control = ["ac"]
+      response {
+        status = 303
+        headers = {
+          location = request.context.ac.logout_url
+        }
+      }
+    }
+
+    endpoint "/mem/cb" {
+      access_control = ["mem"]
+      response {
+        json_body = request.context.mem
+      }
+    }
+
+    endpoint "/mem/private" {
+      access_control = ["mem"]
+      response {
+        json_body = request.context.mem
+      }
+    }
+
+    endpoint "/mem/logout" {
+      access_control = ["mem"]
+      response {
+        status = 303
+        headers = {
+          location = request.context.mem.logout_url
+        }
+      }
+    }
+
+    endpoint "/csrf/cb" {
+      access_control = ["csrf"]
+      response {
+        json_body = request.context.csrf
+      }
+    }
+
+    endpoint "/csrf/private" {
+      access_control = ["csrf"]
+      response {
+        json_body = request.context.csrf
+      }
+    }
+
+    endpoint "/csrf/logout" {
+      access_control = ["csrf"]
+      csrf {}
+      response {
+        status = 303
+        headers = {
+          location = request.context.csrf.logout_url
+        }
+      }
+    }
+  }
+}
+definitions {
+  beta_oauth2 "ac" {
+    grant_type = "authorization_code"
+    redirect_uri = "http://localhost:8080/cb" # value is not checked
+    authorization_endpoint = "https://authorization.server/oauth2/authorize"
+    token_endpoint = "{{.asOrigin}}/token"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    verifier_method = "ccm_s256"
+    verifier_value = request.cookies.pkcecv
+
+    session {
+      secret = "s3cr3t"
+      logout_path = "/logout"
+      end_session_endpoint = "https://authorization.server/logout"
+      post_logout_redirect_uri = "/"
+    }
+  }
+
+  beta_oauth2 "mem" {
+    grant_type = "authorization_code"
+    redirect_uri = "http://localhost:8080/mem/cb" # value is not checked
+    authorization_endpoint = "https://authorization.server/oauth2/authorize"
+    token_endpoint = "{{.asOrigin}}/token"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    verifier_method = "ccm_s256"
+    verifier_value = request.cookies.pkcecv
+
+    session {
+      store = "memory"
+      logout_path = "/mem/logout"
+    }
+  }
+
+  beta_oauth2 "csrf" {
+    grant_type = "authorization_code"
+    redirect_uri = "http://localhost:8080/csrf/cb" # value is not checked
+    authorization_endpoint = "https://authorization.server/oauth2/authorize"
+    token_endpoint = "{{.asOrigin}}/token"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    verifier_method = "ccm_s256"
+    verifier_value = request.cookies.pkcecv
+
+    session {
+      store = "memory"
+      cookie_name = "couper_session_csrf"
+      logout_path = "/csrf/logout"
+    }
+  }
+}
@@ -0,0 +1,86 @@
+server "client" {
+  api {
+    endpoint "/cb" {
+      access_control = ["ac"]
+      response {
+        json_body = request.context.ac
+      }
+    }
+
+    endpoint "/private" {
+      access_control = ["ac"]
+      response {
+        json_body = request.context.ac
+      }
+    }
+
+    endpoint "/backchannel-logout" {
+      access_control = ["ac"]
+      response {
+        headers = {
+          cache-control = "no-store"
+        }
+      }
+    }
+
+    endpoint "/rf/cb" {
+      access_control = ["rf"]
+      response {
+        json_body = request.context.rf
+      }
+    }
+
+    endpoint "/rf/private" {
+      access_control = ["rf"]
+      response {
+        json_body = request.context.rf
+      }
+    }
+
+    endpoint "/nui/cb" {
+      access_control = ["nui"]
+      response {
+        json_body = request.context.nui
+      }
+    }
+  }
+}
+
+definitions {
+  beta_oidc "ac" {
+    configuration_url = "{{.asOrigin}}/.well-known/openid-configuration"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    redirect_uri = "http://localhost:8080/cb" # value is not checked
+    verifier_method = "nonce"
+    verifier_value = request.cookies.nnc
+
+    session {
+      secret = "s3cr3t"
+      backchannel_logout_path = "/backchannel-logout"
+    }
+  }
+
+  beta_oidc "nui" {
+    configuration_url = "{{.asOrigin}}/.well-known/openid-configuration"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    redirect_uri = "http://localhost:8080/nui/cb" # value is not checked
+    verifier_method = "nonce"
+    verifier_value = request.cookies.nnc
+    disable_userinfo = true
+  }
+
+  beta_oidc "rf" {
+    configuration_url = "{{.asOrigin}}/.well-known/openid-configuration"
+    client_id = "foo"
+    client_secret = "etbinbp4in"
+    redirect_uri = "http://localhost:8080/rf/cb" # value is not checked
+    verifier_method = "nonce"
+    verifier_value = request.cookies.nnc
+
+    session {
+      secret = "s3cr3t"
+    }
+  }
+}
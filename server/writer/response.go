@@ -46,6 +46,7 @@ type Response struct {
 	rawBytesWritten int
 	bytesWritten    int
 	// modifier
+	cookies  []*http.Cookie
 	evalCtx  *eval.Context
 	modifier []hcl.Body
 }
@@ -132,6 +133,7 @@ func (r *Response) WriteHeader(statusCode int) {
 		return
 	}
 
+	r.applyCookies()
 	r.configureHeader()
 	r.applyModifier()
 
@@ -187,6 +189,20 @@ func (r *Response) AddModifier(evalCtx *eval.Context, modifier []hcl.Body) {
 	r.modifier = append(r.modifier, modifier...)
 }
 
+// AddCookie adds the given cookie to the response regardless of the Set-Cookie
+// header fields of the written response, e.g. for session cookies.
+func (r *Response) AddCookie(cookie *http.Cookie) {
+	r.cookies = append(r.cookies, cookie)
+}
+
+func (r *Response) applyCookies() {
+	for _, cookie := range r.cookies {
+		if v := cookie.String(); v != "" {
+			r.rw.Header().Add(setCookieHeader, v)
+		}
+	}
+}
+
 func (r *Response) applyModifier() {
 	if r.evalCtx == nil || r.modifier == nil {
 		return
@@ -114,3 +114,22 @@ func TestResponse_ProtoWriteAll(t *testing.T) {
 		t.Errorf("Expected Test header, got: %q", res.Header.Get("Test"))
 	}
 }
+
+func TestResponse_AddCookie(t *testing.T) {
+	helper := test.New(t)
+
+	rec := httptest.NewRecorder()
+	w := writer.NewResponseWriter(rec, "")
+	w.AddCookie(&http.Cookie{Name: "session", Value: "abc", HttpOnly: true})
+
+	_, err := w.Write([]byte("HTTP/1.1 200 OK\r\nSet-Cookie: origin=xyz\r\n\r\n"))
+	helper.Must(err)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 2 {
+		t.Fatalf("expected two cookies, got: %v", rec.Result().Header.Values("Set-Cookie"))
+	}
+	if cookies[0].Name != "origin" || cookies[1].Name != "session" || !cookies[1].HttpOnly {
+		t.Errorf("unexpected cookies: %v", rec.Result().Header.Values("Set-Cookie"))
+	}
+}